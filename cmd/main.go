@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+
 	"github.com/abyanmajid/matcha"
 	"github.com/abyanmajid/matcha/email"
 	"github.com/abyanmajid/matcha/logger"
@@ -8,6 +10,8 @@ import (
 	"github.com/abyanmajid/matcha/reference"
 	"github.com/abyanmajid/thorfinn/internal"
 	"github.com/abyanmajid/thorfinn/internal/api"
+	"github.com/abyanmajid/thorfinn/internal/lifecycle"
+	"github.com/abyanmajid/thorfinn/internal/webhooks"
 )
 
 const (
@@ -18,13 +22,72 @@ const (
 	AuthSendEmailVerificationPath = "/auth/send-email-verification"
 	AuthSendPasswordResetLinkPath = "/auth/send-password-reset-link"
 	AuthResetPasswordPath         = "/auth/reset-password"
+	AuthChangePasswordPath        = "/auth/password"
+	AuthChangeEmailPath           = "/auth/email/change"
+	AuthConfirmEmailChangePath    = "/auth/email/confirm"
+	AuthRevertEmailChangePath     = "/auth/email/revert"
+	AuthNotificationsPath         = "/auth/notifications"
 	AuthOtpSendPath               = "/auth/otp/send"
 	AuthOtpVerifyPath             = "/auth/otp/verify"
+	AuthSwitchOrganizationPath    = "/auth/organization"
+	AuthAcceptInvitationPath      = "/auth/invitations/accept"
+
+	UsersGetAllPath  = "/users"
+	UsersGetPath     = "/users/{id}"
+	UsersUpdatePath  = "/users/{id}"
+	UsersPatchPath   = "/users/{id}"
+	UsersDeletePath  = "/users/{id}"
+	UsersApprovePath = "/users/{id}/approve"
+	UsersSuspendPath = "/users/{id}/suspend"
+	UsersRestorePath = "/users/{id}/restore"
+	UsersPurgePath   = "/users/{id}/purge"
+
+	AdminImpersonatePath = "/admin/users/{id}/impersonate"
+
+	InvitationsCreatePath = "/invitations"
+	InvitationsGetAllPath = "/invitations"
+	InvitationsRevokePath = "/invitations/{id}"
+
+	OrganizationsCreatePath  = "/organizations"
+	OrganizationsGetAllPath  = "/organizations"
+	OrganizationsGetPath     = "/organizations/{id}"
+	OrganizationsUpdatePath  = "/organizations/{id}"
+	OrganizationsDeletePath  = "/organizations/{id}"
+	MembersGetAllPath        = "/organizations/{id}/members"
+	MembersUpdatePath        = "/organizations/{id}/members/{user_id}"
+	MembersRemovePath        = "/organizations/{id}/members/{user_id}"
+	OrgInvitationsCreatePath = "/organizations/{id}/invitations"
+	OrgInvitationsGetAllPath = "/organizations/{id}/invitations"
+	OrgInvitationsRevokePath = "/organizations/{id}/invitations/{invitation_id}"
+	OrgApiKeysCreatePath     = "/organizations/{id}/api-keys"
+	OrgApiKeysGetAllPath     = "/organizations/{id}/api-keys"
+	OrgApiKeysRevokePath     = "/organizations/{id}/api-keys/{key_id}"
+
+	MePath       = "/me"
+	MeDeletePath = "/me/delete"
+	MeExportPath = "/me/export"
+
+	ApiKeysCreatePath = "/me/api-keys"
+	ApiKeysGetAllPath = "/me/api-keys"
+	ApiKeysRevokePath = "/me/api-keys/{id}"
+
+	AuthzCheckPath        = "/authz/check"
+	AuthzCheckBatchPath   = "/authz/check/batch"
+	AuthzExpandPath       = "/authz/expand"
+	AuthzTuplesGetAllPath = "/authz/tuples"
+	AuthzTuplesCreatePath = "/authz/tuples"
+	AuthzTuplesDeletePath = "/authz/tuples/delete"
+
+	AuditEventsGetAllPath = "/audit-events"
+	AuditEventsVerifyPath = "/audit-events/verify"
 
-	UsersGetAllPath = "/users"
-	UsersGetPath    = "/users/{id}"
-	UsersUpdatePath = "/users/{id}"
-	UsersDeletePath = "/users/{id}"
+	WebhooksCreatePath    = "/webhooks"
+	WebhooksGetAllPath    = "/webhooks"
+	WebhooksGetPath       = "/webhooks/{id}"
+	WebhooksUpdatePath    = "/webhooks/{id}"
+	WebhooksDeletePath    = "/webhooks/{id}"
+	WebhookDeliveriesPath = "/webhooks/{id}/deliveries"
+	WebhookRedeliverPath  = "/webhooks/{id}/deliveries/{delivery_id}/redeliver"
 )
 
 func main() {
@@ -37,6 +100,9 @@ func main() {
 		logger.Fatal("Failed to create query client: %v", err)
 	}
 
+	lifecycle.NewJanitor(config, queries).Start(context.Background())
+	webhooks.NewDispatcher(config, queries).Start(context.Background())
+
 	mailer := email.NewClient(email.Config{
 		Host:     config.SmtpHost,
 		Port:     config.SmtpPort,
@@ -68,14 +134,80 @@ func main() {
 	app.Post(AuthSendEmailVerificationPath, resources.AuthResources.SendEmailVerification)
 	app.Post(AuthSendPasswordResetLinkPath, resources.AuthResources.SendPasswordResetLink)
 	app.Put(AuthResetPasswordPath, resources.AuthResources.ResetPassword)
+	app.Put(AuthChangePasswordPath, resources.AuthResources.ChangePassword)
+	app.Post(AuthChangeEmailPath, resources.AuthResources.ChangeEmail)
+	app.Put(AuthConfirmEmailChangePath, resources.AuthResources.ConfirmEmailChange)
+	app.Put(AuthRevertEmailChangePath, resources.AuthResources.RevertEmailChange)
+	app.Put(AuthNotificationsPath, resources.AuthResources.UpdateNotificationPreferences)
 	app.Post(AuthOtpSendPath, resources.AuthResources.OtpSend)
 	app.Post(AuthOtpVerifyPath, resources.AuthResources.OtpVerify)
+	app.Put(AuthSwitchOrganizationPath, resources.AuthResources.SwitchOrganization)
+	app.Post(AuthAcceptInvitationPath, resources.AuthResources.AcceptInvitation)
 
 	// User management resources
 	app.Get(UsersGetAllPath, resources.UsersResources.GetAllUsers)
 	app.Get(UsersGetPath, resources.UsersResources.GetUser)
 	app.Put(UsersUpdatePath, resources.UsersResources.UpdateUser)
+	app.Patch(UsersPatchPath, resources.UsersResources.PatchUser)
 	app.Delete(UsersDeletePath, resources.UsersResources.DeleteUser)
+	app.Post(UsersApprovePath, resources.UsersResources.ApproveUser)
+	app.Post(UsersSuspendPath, resources.UsersResources.SuspendUser)
+	app.Post(UsersRestorePath, resources.UsersResources.RestoreUser)
+	app.Post(UsersPurgePath, resources.UsersResources.PurgeUser)
+	app.Post(AdminImpersonatePath, resources.AuthResources.Impersonate)
+
+	// Instance invitation resources
+	app.Post(InvitationsCreatePath, resources.UsersResources.CreateInvitation)
+	app.Get(InvitationsGetAllPath, resources.UsersResources.GetInvitations)
+	app.Delete(InvitationsRevokePath, resources.UsersResources.RevokeInvitation)
+
+	// Organization resources
+	app.Post(OrganizationsCreatePath, resources.OrganizationsResources.CreateOrganization)
+	app.Get(OrganizationsGetAllPath, resources.OrganizationsResources.GetOrganizations)
+	app.Get(OrganizationsGetPath, resources.OrganizationsResources.GetOrganization)
+	app.Patch(OrganizationsUpdatePath, resources.OrganizationsResources.UpdateOrganization)
+	app.Delete(OrganizationsDeletePath, resources.OrganizationsResources.DeleteOrganization)
+	app.Get(MembersGetAllPath, resources.OrganizationsResources.GetMembers)
+	app.Patch(MembersUpdatePath, resources.OrganizationsResources.UpdateMember)
+	app.Delete(MembersRemovePath, resources.OrganizationsResources.RemoveMember)
+	app.Post(OrgInvitationsCreatePath, resources.OrganizationsResources.CreateInvitation)
+	app.Get(OrgInvitationsGetAllPath, resources.OrganizationsResources.GetInvitations)
+	app.Delete(OrgInvitationsRevokePath, resources.OrganizationsResources.RevokeInvitation)
+	app.Post(OrgApiKeysCreatePath, resources.OrganizationsResources.CreateApiKey)
+	app.Get(OrgApiKeysGetAllPath, resources.OrganizationsResources.GetApiKeys)
+	app.Delete(OrgApiKeysRevokePath, resources.OrganizationsResources.RevokeApiKey)
+
+	// Current user resources
+	app.Get(MePath, resources.UsersResources.GetMe)
+	app.Patch(MePath, resources.UsersResources.UpdateMe)
+	app.Post(MeDeletePath, resources.UsersResources.DeleteMe)
+	app.Get(MeExportPath, resources.UsersResources.ExportMe)
+
+	// API key resources
+	app.Post(ApiKeysCreatePath, resources.UsersResources.CreateApiKey)
+	app.Get(ApiKeysGetAllPath, resources.UsersResources.GetApiKeys)
+	app.Delete(ApiKeysRevokePath, resources.UsersResources.RevokeApiKey)
+
+	// Authorization resources
+	app.Post(AuthzCheckPath, resources.AuthzResources.Check)
+	app.Post(AuthzCheckBatchPath, resources.AuthzResources.CheckBatch)
+	app.Post(AuthzExpandPath, resources.AuthzResources.Expand)
+	app.Get(AuthzTuplesGetAllPath, resources.AuthzResources.GetTuples)
+	app.Post(AuthzTuplesCreatePath, resources.AuthzResources.CreateTuple)
+	app.Post(AuthzTuplesDeletePath, resources.AuthzResources.DeleteTuple)
+
+	// Audit log resources
+	app.Get(AuditEventsGetAllPath, resources.AuditResources.GetAuditEvents)
+	app.Get(AuditEventsVerifyPath, resources.AuditResources.VerifyAuditEvents)
+
+	// Webhook resources
+	app.Post(WebhooksCreatePath, resources.WebhooksResources.CreateWebhook)
+	app.Get(WebhooksGetAllPath, resources.WebhooksResources.GetWebhooks)
+	app.Get(WebhooksGetPath, resources.WebhooksResources.GetWebhook)
+	app.Patch(WebhooksUpdatePath, resources.WebhooksResources.UpdateWebhook)
+	app.Delete(WebhooksDeletePath, resources.WebhooksResources.DeleteWebhook)
+	app.Get(WebhookDeliveriesPath, resources.WebhooksResources.GetWebhookDeliveries)
+	app.Post(WebhookRedeliverPath, resources.WebhooksResources.RedeliverWebhook)
 
 	app.Reference("/reference", &reference.Options{
 		Source: "/docs",
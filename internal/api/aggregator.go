@@ -3,25 +3,41 @@ package api
 import (
 	"github.com/abyanmajid/matcha/email"
 	"github.com/abyanmajid/thorfinn/internal"
+	audit_features "github.com/abyanmajid/thorfinn/internal/api/audit"
 	auth_features "github.com/abyanmajid/thorfinn/internal/api/auth"
+	authz_features "github.com/abyanmajid/thorfinn/internal/api/authz"
+	organizations_features "github.com/abyanmajid/thorfinn/internal/api/organizations"
 	users_features "github.com/abyanmajid/thorfinn/internal/api/users"
+	webhooks_features "github.com/abyanmajid/thorfinn/internal/api/webhooks"
 	"github.com/abyanmajid/thorfinn/internal/database"
 )
 
 type Resources struct {
-	authResources  *auth_features.DerivedAuthResources
-	usersResources *users_features.DerivedUsersResources
+	authResources          *auth_features.DerivedAuthResources
+	usersResources         *users_features.DerivedUsersResources
+	organizationsResources *organizations_features.DerivedOrganizationsResources
+	authzResources         *authz_features.DerivedAuthzResources
+	auditResources         *audit_features.DerivedAuditResources
+	webhooksResources      *webhooks_features.DerivedWebhooksResources
 }
 
 type Handlers struct {
-	authHandlers  *auth_features.AuthHandlers
-	usersHandlers *users_features.UsersHandlers
+	authHandlers          *auth_features.AuthHandlers
+	usersHandlers         *users_features.UsersHandlers
+	organizationsHandlers *organizations_features.OrganizationsHandlers
+	authzHandlers         *authz_features.AuthzHandlers
+	auditHandlers         *audit_features.AuditHandlers
+	webhooksHandlers      *webhooks_features.WebhooksHandlers
 }
 
 func aggregateHandlers(isDev bool, config *internal.EnvConfig, queries *database.Queries, mailer *email.Client) *Handlers {
 	return &Handlers{
-		authHandlers:  auth_features.NewHandlers(isDev, config, queries, mailer),
-		usersHandlers: users_features.NewHandlers(isDev, config, queries, mailer),
+		authHandlers:          auth_features.NewHandlers(isDev, config, queries, mailer),
+		usersHandlers:         users_features.NewHandlers(isDev, config, queries, mailer),
+		organizationsHandlers: organizations_features.NewHandlers(isDev, config, queries, mailer),
+		authzHandlers:         authz_features.NewHandlers(isDev, config, queries, mailer),
+		auditHandlers:         audit_features.NewHandlers(isDev, config, queries, mailer),
+		webhooksHandlers:      webhooks_features.NewHandlers(isDev, config, queries, mailer),
 	}
 }
 
@@ -36,8 +52,32 @@ func aggregateResources(handlers *Handlers) (*Resources, error) {
 		return nil, err
 	}
 
+	derivedOrganizationsResources, err := organizations_features.Derive(handlers.organizationsHandlers)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedAuthzResources, err := authz_features.Derive(handlers.authzHandlers)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedAuditResources, err := audit_features.Derive(handlers.auditHandlers)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedWebhooksResources, err := webhooks_features.Derive(handlers.webhooksHandlers)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Resources{
-		authResources:  derivedAuthResources,
-		usersResources: derivedUsersResources,
+		authResources:          derivedAuthResources,
+		usersResources:         derivedUsersResources,
+		organizationsResources: derivedOrganizationsResources,
+		authzResources:         derivedAuthzResources,
+		auditResources:         derivedAuditResources,
+		webhooksResources:      derivedWebhooksResources,
 	}, nil
 }
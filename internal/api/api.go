@@ -3,14 +3,22 @@ package api
 import (
 	"github.com/abyanmajid/matcha/email"
 	"github.com/abyanmajid/thorfinn/internal"
+	audit_features "github.com/abyanmajid/thorfinn/internal/api/audit"
 	auth_features "github.com/abyanmajid/thorfinn/internal/api/auth"
+	authz_features "github.com/abyanmajid/thorfinn/internal/api/authz"
+	organizations_features "github.com/abyanmajid/thorfinn/internal/api/organizations"
 	users_features "github.com/abyanmajid/thorfinn/internal/api/users"
+	webhooks_features "github.com/abyanmajid/thorfinn/internal/api/webhooks"
 	"github.com/abyanmajid/thorfinn/internal/database"
 )
 
 type ApiResources struct {
-	AuthResources  *auth_features.DerivedAuthResources
-	UsersResources *users_features.DerivedUsersResources
+	AuthResources          *auth_features.DerivedAuthResources
+	UsersResources         *users_features.DerivedUsersResources
+	OrganizationsResources *organizations_features.DerivedOrganizationsResources
+	AuthzResources         *authz_features.DerivedAuthzResources
+	AuditResources         *audit_features.DerivedAuditResources
+	WebhooksResources      *webhooks_features.DerivedWebhooksResources
 }
 
 type Utils struct {
@@ -28,7 +36,11 @@ func CreateApiResources(utils *Utils) (*ApiResources, error) {
 	}
 
 	return &ApiResources{
-		AuthResources:  resources.authResources,
-		UsersResources: resources.usersResources,
+		AuthResources:          resources.authResources,
+		UsersResources:         resources.usersResources,
+		OrganizationsResources: resources.organizationsResources,
+		AuthzResources:         resources.authzResources,
+		AuditResources:         resources.auditResources,
+		WebhooksResources:      resources.webhooksResources,
 	}, nil
 }
@@ -0,0 +1,26 @@
+package audit_features
+
+import "github.com/abyanmajid/matcha/openapi"
+
+type DerivedAuditResources struct {
+	GetAuditEvents    *openapi.Resource
+	VerifyAuditEvents *openapi.Resource
+}
+
+func Derive(handlers *AuditHandlers) (*DerivedAuditResources, error) {
+	auditResources := NewAuditResources(handlers)
+	getAuditEventsResource, err := auditResources.GetAuditEventsResource()
+	if err != nil {
+		return nil, err
+	}
+
+	verifyAuditEventsResource, err := auditResources.VerifyAuditEventsResource()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedAuditResources{
+		GetAuditEvents:    getAuditEventsResource,
+		VerifyAuditEvents: verifyAuditEventsResource,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package audit_features
+
+import (
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AuditEvent is an action recorded in the audit log. ImpersonatorID is set
+// when ActorID was being impersonated, and Hash and PreviousHash are set when
+// the event is part of the hash chain.
+type AuditEvent struct {
+	ID             int64              `json:"id"`
+	Action         string             `json:"action"`
+	Outcome        string             `json:"outcome"`
+	ActorID        pgtype.Text        `json:"actor_id"`
+	ImpersonatorID pgtype.Text        `json:"impersonator_id"`
+	TargetUserID   pgtype.Text        `json:"target_user_id"`
+	IpAddress      string             `json:"ip_address"`
+	UserAgent      string             `json:"user_agent"`
+	RequestID      string             `json:"request_id"`
+	Metadata       json.RawMessage    `json:"metadata"`
+	PreviousHash   pgtype.Text        `json:"previous_hash"`
+	Hash           pgtype.Text        `json:"hash"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type GetAuditEventsRequest struct{}
+
+type GetAuditEventsResponse struct {
+	Message    string       `json:"message"`
+	Events     []AuditEvent `json:"events"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type VerifyAuditEventsRequest struct{}
+
+// VerifyAuditEventsResponse reports whether the hash chain is intact. BrokenAt
+// is the id of the first event that was modified, or follows a removed event.
+type VerifyAuditEventsResponse struct {
+	Message  string `json:"message"`
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
@@ -0,0 +1,113 @@
+package audit_features
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/matcha/email"
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/audit"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/session"
+)
+
+type AuditHandlers struct {
+	isDev         bool
+	config        *internal.EnvConfig
+	queries       *database.Queries
+	mailer        *email.Client
+	authenticator *session.Authenticator
+	auditor       *audit.Auditor
+}
+
+func NewHandlers(isDev bool, config *internal.EnvConfig, queries *database.Queries, mailer *email.Client) *AuditHandlers {
+	return &AuditHandlers{
+		isDev:         isDev,
+		config:        config,
+		queries:       queries,
+		mailer:        mailer,
+		authenticator: session.NewAuthenticator(config, queries),
+		auditor:       audit.NewAuditor(config, queries),
+	}
+}
+
+func (h *AuditHandlers) GetAuditEvents(c *ctx.Request[GetAuditEventsRequest]) *ctx.Response[GetAuditEventsResponse] {
+	logger.Info("Invoked: GetAuditEvents")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetAuditEventsResponse](err)
+	}
+
+	logger.Debug("Parsing query parameters")
+	params, err := parseListAuditEventsQuery(c)
+	if err != nil {
+		logger.Error("Error parsing query parameters: %v", err)
+		return internal.ApiError[GetAuditEventsResponse](err)
+	}
+
+	logger.Debug("Fetching audit events")
+	rows, err := h.queries.ListAuditEvents(c.Request.Context(), params)
+	if err != nil {
+		logger.Error("Error getting audit events: %v", err)
+		return internal.GenericError[GetAuditEventsResponse]()
+	}
+
+	nextCursor := ""
+	if len(rows) == int(params.Limit) {
+		rows = rows[:len(rows)-1]
+		nextCursor = strconv.FormatInt(rows[len(rows)-1].ID, 10)
+	}
+
+	events := make([]AuditEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, newAuditEvent(row))
+	}
+
+	return &ctx.Response[GetAuditEventsResponse]{
+		Response: GetAuditEventsResponse{
+			Message:    "Successfully fetched audit events",
+			Events:     events,
+			NextCursor: nextCursor,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuditHandlers) VerifyAuditEvents(c *ctx.Request[VerifyAuditEventsRequest]) *ctx.Response[VerifyAuditEventsResponse] {
+	logger.Info("Invoked: VerifyAuditEvents")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[VerifyAuditEventsResponse](err)
+	}
+
+	logger.Debug("Verifying hash chain")
+	verification, err := h.auditor.Verify(c.Request.Context())
+	if err != nil {
+		logger.Error("Error verifying hash chain: %v", err)
+		return internal.GenericError[VerifyAuditEventsResponse]()
+	}
+
+	var brokenAt *int64
+	if verification.BrokenAt != 0 {
+		logger.Error("Hash chain is broken at audit event %d", verification.BrokenAt)
+		brokenAt = &verification.BrokenAt
+	}
+
+	return &ctx.Response[VerifyAuditEventsResponse]{
+		Response: VerifyAuditEventsResponse{
+			Message:  "Successfully verified audit events",
+			Valid:    brokenAt == nil,
+			Checked:  verification.Checked,
+			BrokenAt: brokenAt,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
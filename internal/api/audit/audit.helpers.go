@@ -0,0 +1,104 @@
+package audit_features
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/audit"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultEventsLimit = 20
+	maxEventsLimit     = 100
+)
+
+// parseListAuditEventsQuery reads the filters of a request for audit events.
+// One more event than requested is fetched to know whether there is a next
+// page, and the after cursor is the id of the last event of the previous page.
+func parseListAuditEventsQuery(c *ctx.Request[GetAuditEventsRequest]) (database.ListAuditEventsParams, error) {
+	fields := map[string][]string{}
+	params := database.ListAuditEventsParams{
+		ActorID:      textParam(c, "actor_id"),
+		TargetUserID: textParam(c, "target_user_id"),
+		Action:       textParam(c, "action"),
+		Outcome:      textParam(c, "outcome"),
+		IpAddress:    textParam(c, "ip_address"),
+		RequestID:    textParam(c, "request_id"),
+		Limit:        defaultEventsLimit + 1,
+	}
+
+	if limit := c.GetQueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxEventsLimit {
+			fields["limit"] = []string{fmt.Sprintf("Must be an integer between 1 and %d", maxEventsLimit)}
+		}
+		params.Limit = int32(n) + 1
+	}
+
+	if params.Outcome.Valid && !slices.Contains(audit.Outcomes, params.Outcome.String) {
+		fields["outcome"] = []string{fmt.Sprintf("Must be one of %s", strings.Join(audit.Outcomes, ", "))}
+	}
+
+	params.CreatedAfter = timeParam(c, "created_after", fields)
+	params.CreatedBefore = timeParam(c, "created_before", fields)
+
+	if after := c.GetQueryParam("after"); after != "" {
+		id, err := strconv.ParseInt(after, 10, 64)
+		if err != nil || id < 1 {
+			fields["after"] = []string{"Must be a cursor returned by a previous request"}
+		}
+		params.BeforeID = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	if len(fields) > 0 {
+		return params, apierror.Validation(fields)
+	}
+
+	return params, nil
+}
+
+func textParam(c *ctx.Request[GetAuditEventsRequest], name string) pgtype.Text {
+	value := c.GetQueryParam(name)
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+func timeParam(c *ctx.Request[GetAuditEventsRequest], name string, fields map[string][]string) pgtype.Timestamptz {
+	value := c.GetQueryParam(name)
+	if value == "" {
+		return pgtype.Timestamptz{}
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		fields[name] = []string{"Must be an RFC 3339 timestamp"}
+		return pgtype.Timestamptz{}
+	}
+
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+func newAuditEvent(event database.ThorfinnAuditEvent) AuditEvent {
+	return AuditEvent{
+		ID:             event.ID,
+		Action:         event.Action,
+		Outcome:        event.Outcome,
+		ActorID:        event.ActorID,
+		ImpersonatorID: event.ImpersonatorID,
+		TargetUserID:   event.TargetUserID,
+		IpAddress:      event.IpAddress,
+		UserAgent:      event.UserAgent,
+		RequestID:      event.RequestID,
+		Metadata:       json.RawMessage(event.Metadata),
+		PreviousHash:   event.PreviousHash,
+		Hash:           event.Hash,
+		CreatedAt:      event.CreatedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package audit_features
+
+import (
+	"net/http"
+
+	"github.com/abyanmajid/matcha/openapi"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+)
+
+type AuditResources struct {
+	handlers *AuditHandlers
+}
+
+func NewAuditResources(handlers *AuditHandlers) *AuditResources {
+	return &AuditResources{
+		handlers: handlers,
+	}
+}
+
+func (r *AuditResources) GetAuditEventsResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetAuditEventsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetAuditEventsResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get audit events",
+		Description: "Get a page of audit events, newest first, optionally filtered. Pass the next_cursor of a response as the after parameter to fetch the next page; it is omitted on the last page. Requires the admin role",
+		Schema: openapi.Schema{
+			Parameters: []openapi.Parameter{
+				{In: "query", Name: "limit", Description: "The maximum number of events to return, between 1 and 100. Defaults to 20"},
+				{In: "query", Name: "after", Description: "The next_cursor of the previous page"},
+				{In: "query", Name: "actor_id", Description: "Only return events performed by this user"},
+				{In: "query", Name: "target_user_id", Description: "Only return events performed on this user"},
+				{In: "query", Name: "action", Description: "Only return events with this action, such as user.logged_in"},
+				{In: "query", Name: "outcome", Description: "Only return events with this outcome: success or failure"},
+				{In: "query", Name: "ip_address", Description: "Only return events from this IP address"},
+				{In: "query", Name: "request_id", Description: "Only return events recorded in the request with this X-Request-Id"},
+				{In: "query", Name: "created_after", Description: "Only return events recorded at or after this RFC 3339 timestamp"},
+				{In: "query", Name: "created_before", Description: "Only return events recorded before this RFC 3339 timestamp"},
+			},
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched audit events",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.ValidationFailed),
+		},
+	}
+
+	resource := internal.NewResource("GetAuditEvents", doc, r.handlers.GetAuditEvents)
+
+	return &resource, nil
+}
+
+func (r *AuditResources) VerifyAuditEventsResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(VerifyAuditEventsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(VerifyAuditEventsResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Verify audit events",
+		Description: "Recompute the hash chain of the audit log, from the oldest event still retained to the latest, and report the first event that was modified or follows a removed event. Only events recorded while AUDIT_HASH_CHAIN was enabled are checked. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully verified audit events",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("VerifyAuditEvents", doc, r.handlers.VerifyAuditEvents)
+
+	return &resource, nil
+}
@@ -3,15 +3,23 @@ package auth_features
 import "github.com/abyanmajid/matcha/openapi"
 
 type DerivedAuthResources struct {
-	Register              *openapi.Resource
-	VerifyEmail           *openapi.Resource
-	Login                 *openapi.Resource
-	Logout                *openapi.Resource
-	SendEmailVerification *openapi.Resource
-	SendPasswordResetLink *openapi.Resource
-	ResetPassword         *openapi.Resource
-	OtpSend               *openapi.Resource
-	OtpVerify             *openapi.Resource
+	Register                      *openapi.Resource
+	VerifyEmail                   *openapi.Resource
+	Login                         *openapi.Resource
+	Logout                        *openapi.Resource
+	SendEmailVerification         *openapi.Resource
+	SendPasswordResetLink         *openapi.Resource
+	ResetPassword                 *openapi.Resource
+	ChangePassword                *openapi.Resource
+	ChangeEmail                   *openapi.Resource
+	ConfirmEmailChange            *openapi.Resource
+	RevertEmailChange             *openapi.Resource
+	UpdateNotificationPreferences *openapi.Resource
+	OtpSend                       *openapi.Resource
+	OtpVerify                     *openapi.Resource
+	SwitchOrganization            *openapi.Resource
+	AcceptInvitation              *openapi.Resource
+	Impersonate                   *openapi.Resource
 }
 
 func Derive(handlers *AuthHandlers) (*DerivedAuthResources, error) {
@@ -51,6 +59,31 @@ func Derive(handlers *AuthHandlers) (*DerivedAuthResources, error) {
 		return nil, err
 	}
 
+	changePasswordResource, err := authResources.ChangePasswordResource()
+	if err != nil {
+		return nil, err
+	}
+
+	changeEmailResource, err := authResources.ChangeEmailResource()
+	if err != nil {
+		return nil, err
+	}
+
+	confirmEmailChangeResource, err := authResources.ConfirmEmailChangeResource()
+	if err != nil {
+		return nil, err
+	}
+
+	revertEmailChangeResource, err := authResources.RevertEmailChangeResource()
+	if err != nil {
+		return nil, err
+	}
+
+	updateNotificationPreferencesResource, err := authResources.UpdateNotificationPreferencesResource()
+	if err != nil {
+		return nil, err
+	}
+
 	otpSendResource, err := authResources.OtpSendResource()
 	if err != nil {
 		return nil, err
@@ -61,15 +94,38 @@ func Derive(handlers *AuthHandlers) (*DerivedAuthResources, error) {
 		return nil, err
 	}
 
+	switchOrganizationResource, err := authResources.SwitchOrganizationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	acceptInvitationResource, err := authResources.AcceptInvitationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	impersonateResource, err := authResources.ImpersonateResource()
+	if err != nil {
+		return nil, err
+	}
+
 	return &DerivedAuthResources{
-		Register:              registerResource,
-		VerifyEmail:           confirmEmailResource,
-		Login:                 loginResource,
-		Logout:                logoutResource,
-		SendEmailVerification: sendEmailVerificationResource,
-		SendPasswordResetLink: sendPasswordResetLinkResource,
-		ResetPassword:         resetPasswordResource,
-		OtpSend:               otpSendResource,
-		OtpVerify:             otpVerifyResource,
+		Register:                      registerResource,
+		VerifyEmail:                   confirmEmailResource,
+		Login:                         loginResource,
+		Logout:                        logoutResource,
+		SendEmailVerification:         sendEmailVerificationResource,
+		SendPasswordResetLink:         sendPasswordResetLinkResource,
+		ResetPassword:                 resetPasswordResource,
+		ChangePassword:                changePasswordResource,
+		ChangeEmail:                   changeEmailResource,
+		ConfirmEmailChange:            confirmEmailChangeResource,
+		RevertEmailChange:             revertEmailChangeResource,
+		UpdateNotificationPreferences: updateNotificationPreferencesResource,
+		OtpSend:                       otpSendResource,
+		OtpVerify:                     otpVerifyResource,
+		SwitchOrganization:            switchOrganizationResource,
+		AcceptInvitation:              acceptInvitationResource,
+		Impersonate:                   impersonateResource,
 	}, nil
 }
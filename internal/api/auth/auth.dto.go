@@ -1,9 +1,11 @@
 package auth_features
 
+import "github.com/jackc/pgx/v5/pgtype"
+
 type RegisterRequest struct {
-	Email           string `json:"email"`
-	Password        string `json:"password"`
-	ConfirmPassword string `json:"confirm_password"`
+	Email           string `json:"email" validate:"required,email"`
+	Password        string `json:"password" validate:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
 }
 
 type RegisterResponse struct {
@@ -11,7 +13,7 @@ type RegisterResponse struct {
 }
 
 type ConfirmEmailRequest struct {
-	Token string `json:"token"`
+	Token string `json:"token" validate:"required"`
 }
 
 type ConfirmEmailResponse struct {
@@ -19,8 +21,8 @@ type ConfirmEmailResponse struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -36,7 +38,7 @@ type LogoutResponse struct {
 }
 
 type SendVerificationEmailRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required,email"`
 }
 
 type SendVerificationEmailResponse struct {
@@ -44,7 +46,7 @@ type SendVerificationEmailResponse struct {
 }
 
 type SendPasswordResetRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required,email"`
 }
 
 type SendPasswordResetResponse struct {
@@ -52,16 +54,60 @@ type SendPasswordResetResponse struct {
 }
 
 type ResetPasswordRequest struct {
-	Token       string `json:"token"`
-	NewPassword string `json:"new_password"`
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
 }
 
 type ResetPasswordResponse struct {
 	Message string `json:"message"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+}
+
+type ChangePasswordResponse struct {
+	Message string `json:"message"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ChangeEmailResponse struct {
+	Message string `json:"message"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ConfirmEmailChangeResponse struct {
+	Message string `json:"message"`
+}
+
+type RevertEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type RevertEmailChangeResponse struct {
+	Message string `json:"message"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	OptOuts []string `json:"opt_outs"`
+}
+
+type UpdateNotificationPreferencesResponse struct {
+	Message string   `json:"message"`
+	OptOuts []string `json:"opt_outs"`
+}
+
 type OtpSendRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required,email"`
 }
 
 type OtpSendResponse struct {
@@ -70,10 +116,41 @@ type OtpSendResponse struct {
 }
 
 type OtpVerifyRequest struct {
-	OtpCodeId string `json:"otp_code_id"`
-	OtpCode   string `json:"otp_code"`
+	OtpCodeId string `json:"otp_code_id" validate:"required,uuid"`
+	OtpCode   string `json:"otp_code" validate:"required,len=6"`
 }
 
 type OtpVerifyResponse struct {
 	Message string `json:"message"`
 }
+
+type SwitchOrganizationRequest struct {
+	OrganizationID string `json:"organization_id" validate:"uuid"`
+}
+
+type SwitchOrganizationResponse struct {
+	Message       string `json:"message"`
+	AccessToken   string `json:"access_token"`
+	RefreshTokens string `json:"refresh_token"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password,omitempty" validate:"min=8"`
+}
+
+type AcceptInvitationResponse struct {
+	Message string `json:"message"`
+	UserID  string `json:"user_id"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ImpersonateResponse struct {
+	Message       string             `json:"message"`
+	AccessToken   string             `json:"access_token"`
+	RefreshTokens string             `json:"refresh_token"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+}
@@ -1,8 +1,10 @@
 package auth_features
 
 import (
+	"crypto/subtle"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -14,45 +16,97 @@ import (
 	"github.com/abyanmajid/matcha/logger"
 	"github.com/abyanmajid/matcha/security"
 	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/audit"
+	"github.com/abyanmajid/thorfinn/internal/claims"
 	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/hooks"
+	"github.com/abyanmajid/thorfinn/internal/invitations"
+	"github.com/abyanmajid/thorfinn/internal/lifecycle"
+	"github.com/abyanmajid/thorfinn/internal/notifications"
+	"github.com/abyanmajid/thorfinn/internal/organizations"
+	"github.com/abyanmajid/thorfinn/internal/password"
+	"github.com/abyanmajid/thorfinn/internal/registration"
+	"github.com/abyanmajid/thorfinn/internal/session"
+	"github.com/abyanmajid/thorfinn/internal/webhooks"
 )
 
 type AuthHandlers struct {
-	isDev   bool
-	config  *internal.EnvConfig
-	queries *database.Queries
-	mailer  *email.Client
+	isDev           bool
+	config          *internal.EnvConfig
+	queries         *database.Queries
+	mailer          *email.Client
+	passwordHasher  *password.Hasher
+	passwordHistory *password.History
+	authenticator   *session.Authenticator
+	notifier        *notifications.Notifier
+	claimsMapper    *claims.Mapper
+	inviter         *invitations.Inviter
+	registration    *registration.Policy
+	auditor         *audit.Auditor
+	dispatcher      *webhooks.Dispatcher
 }
 
 func NewHandlers(isDev bool, config *internal.EnvConfig, queries *database.Queries, mailer *email.Client) *AuthHandlers {
+	passwordHasher := password.NewHasher(config)
+
 	return &AuthHandlers{
-		isDev:   isDev,
-		config:  config,
-		queries: queries,
-		mailer:  mailer,
+		isDev:           isDev,
+		config:          config,
+		queries:         queries,
+		mailer:          mailer,
+		passwordHasher:  passwordHasher,
+		passwordHistory: password.NewHistory(config, queries, passwordHasher),
+		authenticator:   session.NewAuthenticator(config, queries),
+		notifier:        notifications.NewNotifier(config, mailer),
+		claimsMapper:    claims.NewMapper(config),
+		inviter:         invitations.NewInviter(config, queries, mailer),
+		registration:    registration.NewPolicy(config),
+		auditor:         audit.NewAuditor(config, queries),
+		dispatcher:      webhooks.NewDispatcher(config, queries),
 	}
 }
 
 func (h *AuthHandlers) Register(c *ctx.Request[RegisterRequest]) *ctx.Response[RegisterResponse] {
 	logger.Info("Invoked: Register")
 
+	defer h.padResponseTime(time.Now())
+
+	logger.Debug("Checking registration policy")
+	err := h.registration.CheckMode()
+	if err != nil {
+		logger.Error("Registration is not open: %v", err)
+		return internal.ApiError[RegisterResponse](err)
+	}
+
+	err = h.registration.CheckEmail(c.Body.Email)
+	if err != nil {
+		logger.Error("Email is not allowed: %v", err)
+		return internal.ApiError[RegisterResponse](err)
+	}
+
+	logger.Debug("Running before register hooks")
+	err = hooks.BeforeRegister(c.Request.Context(), hooks.Registration{Email: c.Body.Email})
+	if err != nil {
+		logger.Error("Error running before register hooks: %v", err)
+		return internal.ApiError[RegisterResponse](err)
+	}
+
 	logger.Debug("Finding user by email")
-	_, err := h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
+	_, err = h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		logger.Error("Error finding user by email: %v", err)
 		return internal.GenericError[RegisterResponse]()
 	}
 
-	if errors.Is(err, sql.ErrNoRows) {
-		logger.Debug("Validating register payload")
-		err := validateRegisterPayload(c.Body)
-		if err != nil {
-			logger.Error("Error validating register payload: %v", err)
-			return internal.CustomError[RegisterResponse](err.Error())
-		}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.Debug("Hashing password for an existing user")
+		_, _ = h.passwordHasher.Hash(c.Body.Password)
+	}
 
+	if errors.Is(err, sql.ErrNoRows) {
 		logger.Debug("Hashing password")
-		passwordHash, err := security.Hash([]byte(c.Body.Password))
+		passwordHash, err := h.passwordHasher.Hash(c.Body.Password)
 		if err != nil {
 			logger.Error("Error hashing password: %v", err)
 			return internal.GenericError[RegisterResponse]()
@@ -62,19 +116,37 @@ func (h *AuthHandlers) Register(c *ctx.Request[RegisterRequest]) *ctx.Response[R
 		user, err := h.queries.CreateUser(c.Request.Context(), database.CreateUserParams{
 			ID:           uuid.New().String(),
 			Email:        c.Body.Email,
-			PasswordHash: string(passwordHash.Hash),
+			PasswordHash: passwordHash,
+			Status:       h.registration.InitialStatus(),
 		})
 		if err != nil {
 			logger.Error("Error creating user: %v", err)
 			return internal.GenericError[RegisterResponse]()
 		}
 
+		logger.Debug("Recording password history")
+		err = h.passwordHistory.Record(c.Request.Context(), user.ID, user.PasswordHash)
+		if err != nil {
+			logger.Error("Error recording password history: %v", err)
+			return internal.GenericError[RegisterResponse]()
+		}
+
+		h.auditor.Record(c.Request, audit.Event{
+			Action:       audit.ActionUserRegistered,
+			Actor:        audit.Actor{UserID: user.ID},
+			TargetUserID: user.ID,
+			Metadata:     map[string]any{"status": user.Status},
+		})
+		h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserRegistered, user, nil)
+		hooks.AfterRegister(c.Request.Context(), user)
+
 		logger.Debug("Creating verification link")
 		verificationLink, err := createVerificationLink(VerificationLinkOpts[RegisterRequest]{
 			Request: c,
 			Config:  h.config,
 			UserId:  user.ID,
 			Path:    "auth/verify-email",
+			Purpose: purposeEmailVerification,
 		})
 
 		if err != nil {
@@ -82,14 +154,9 @@ func (h *AuthHandlers) Register(c *ctx.Request[RegisterRequest]) *ctx.Response[R
 			return internal.GenericError[RegisterResponse]()
 		}
 
-		err = h.mailer.SendEmail(h.config.EmailFrom, []string{c.Body.Email}, "Email Verification", "email_verification", map[string]any{
+		internal.SendEmailAsync(h.mailer, h.config.EmailFrom, []string{c.Body.Email}, "Email Verification", "email_verification", map[string]any{
 			"VerificationLink": verificationLink,
 		})
-
-		if err != nil {
-			logger.Error("Error sending email verification email: %v", err)
-			return internal.GenericError[RegisterResponse]()
-		}
 	}
 
 	return &ctx.Response[RegisterResponse]{
@@ -106,7 +173,7 @@ func (h *AuthHandlers) VerifyEmail(c *ctx.Request[ConfirmEmailRequest]) *ctx.Res
 
 	if h.isTokenBlacklisted(c.Body.Token) {
 		logger.Error("Token is blacklisted")
-		return internal.CustomError[ConfirmEmailResponse]("token is blacklisted")
+		return internal.CustomError[ConfirmEmailResponse](apierror.TokenRevoked, "token is blacklisted")
 	}
 
 	err := h.blacklistToken(c.Body.Token)
@@ -116,10 +183,10 @@ func (h *AuthHandlers) VerifyEmail(c *ctx.Request[ConfirmEmailRequest]) *ctx.Res
 	}
 
 	logger.Debug("Decoding, decrypting, and verifying token")
-	claims, err := processVerificationToken(c.Body.Token, h.config)
+	claims, err := processVerificationToken(c.Body.Token, h.config, purposeEmailVerification)
 	if err != nil {
 		logger.Error("Error processing verification token: %v", err)
-		return internal.CustomError[ConfirmEmailResponse](err.Error())
+		return internal.ApiError[ConfirmEmailResponse](err)
 	}
 
 	logger.Debug("Validating user id")
@@ -127,11 +194,18 @@ func (h *AuthHandlers) VerifyEmail(c *ctx.Request[ConfirmEmailRequest]) *ctx.Res
 	userId, err := validateUserIdInterface(userIdInterface)
 	if err != nil {
 		logger.Error("Error validating user id: %v", err)
-		return internal.CustomError[ConfirmEmailResponse](err.Error())
+		return internal.ApiError[ConfirmEmailResponse](err)
+	}
+
+	logger.Debug("Checking account status")
+	err = h.checkAccountStatus(c.Request.Context(), userId, lifecycle.CheckVerification)
+	if err != nil {
+		logger.Error("Error checking account status: %v", err)
+		return internal.ApiError[ConfirmEmailResponse](err)
 	}
 
 	logger.Debug("Updating user verification status")
-	_, err = h.queries.UpdateUserVerified(c.Request.Context(), database.UpdateUserVerifiedParams{
+	user, err := h.queries.UpdateUserVerified(c.Request.Context(), database.UpdateUserVerifiedParams{
 		ID:       userId,
 		Verified: true,
 	})
@@ -140,6 +214,14 @@ func (h *AuthHandlers) VerifyEmail(c *ctx.Request[ConfirmEmailRequest]) *ctx.Res
 		return internal.GenericError[ConfirmEmailResponse]()
 	}
 
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionEmailVerified,
+		Actor:        audit.Actor{UserID: userId},
+		TargetUserID: userId,
+	})
+	h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserEmailVerified, user, nil)
+	hooks.AfterEmailVerified(c.Request.Context(), user)
+
 	return &ctx.Response[ConfirmEmailResponse]{
 		Response: ConfirmEmailResponse{
 			Message: "Email has been verified",
@@ -152,39 +234,126 @@ func (h *AuthHandlers) VerifyEmail(c *ctx.Request[ConfirmEmailRequest]) *ctx.Res
 func (h *AuthHandlers) Login(c *ctx.Request[LoginRequest]) *ctx.Response[LoginResponse] {
 	logger.Info("Invoked: Login")
 
+	defer h.padResponseTime(time.Now())
+
 	logger.Debug("Finding user by email")
 	user, err := h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
-	if err != nil {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		logger.Error("Error finding user by email: %v", err)
 		return internal.GenericError[LoginResponse]()
 	}
 
-	if !user.Verified {
-		logger.Error("User is not verified")
-		return internal.CustomError[LoginResponse]("please verify your email to login")
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Debug("Comparing password with dummy hash")
+		h.passwordHasher.VerifyDummy(c.Body.Password)
+
+		logger.Error("User does not exist")
+		h.recordFailedLogin(c, "", "unknown_email")
+		return internal.CustomError[LoginResponse](apierror.InvalidCredentials, "invalid credentials")
 	}
 
 	logger.Debug("Comparing password with hash")
-	err = security.VerifyHash([]byte(user.PasswordHash), []byte(c.Body.Password))
+	err = h.passwordHasher.Verify(user.PasswordHash, c.Body.Password)
 	if err != nil {
 		logger.Error("Error verifying password: %v", err)
-		return internal.CustomError[LoginResponse]("invalid credentials")
+		h.recordFailedLogin(c, user.ID, "incorrect_password")
+		return internal.CustomError[LoginResponse](apierror.InvalidCredentials, "invalid credentials")
+	}
+
+	// A deletion the user requested is only cancelled once the login is
+	// allowed, so that a rejected login doesn't reactivate the account.
+	cancelsDeletion := lifecycle.CancelsOnLogin(user)
+
+	if !cancelsDeletion {
+		err = lifecycle.Check(user)
+		if err != nil {
+			logger.Error("Account is not active: %v", err)
+			h.recordFailedLogin(c, user.ID, "account_"+user.Status)
+			return internal.ApiError[LoginResponse](err)
+		}
+	}
+
+	if !user.Verified {
+		logger.Error("User is not verified")
+		h.recordFailedLogin(c, user.ID, "email_not_verified")
+		return internal.CustomError[LoginResponse](apierror.EmailNotVerified, "please verify your email to login")
 	}
 
-	accessToken, err := h.createAccessToken(&user)
+	logger.Debug("Running before login hooks")
+	err = hooks.BeforeLogin(c.Request.Context(), user)
 	if err != nil {
-		logger.Error("Error creating access token: %v", err)
+		logger.Error("Error running before login hooks: %v", err)
+		h.recordFailedLogin(c, user.ID, "hook_rejected")
+		return internal.ApiError[LoginResponse](err)
+	}
+
+	if cancelsDeletion {
+		logger.Debug("Cancelling account deletion")
+		user, err = h.queries.UpdateUserStatus(c.Request.Context(), database.UpdateUserStatusParams{
+			ID:     user.ID,
+			Status: lifecycle.StatusActive,
+		})
+		if err != nil {
+			logger.Error("Error cancelling account deletion: %v", err)
+			return internal.GenericError[LoginResponse]()
+		}
+
+		h.notifier.Notify(user, notifications.KindDeletionCancelled, notifications.Details{
+			IpAddress: internal.ClientIp(h.config, c.Request),
+			UserAgent: c.GetHeader("User-Agent"),
+		})
+		h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserDeletionCancelled, user, nil)
+	}
+
+	if h.passwordHasher.NeedsRehash(user.PasswordHash) {
+		logger.Debug("Rehashing password with current parameters")
+		h.rehashPassword(c.Request.Context(), &user, c.Body.Password)
+	}
+
+	newDevice := h.isNewDevice(c, &user)
+
+	logger.Debug("Creating session")
+	session, err := h.createSession(c, &user)
+	if err != nil {
+		logger.Error("Error creating session: %v", err)
 		return internal.GenericError[LoginResponse]()
 	}
 
-	refreshToken, err := h.createRefreshToken(&user)
+	logger.Debug("Recording last login")
+	err = h.queries.UpdateUserLastLogin(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Error recording last login: %v", err)
+	}
+
+	if newDevice {
+		h.notifier.Notify(user, notifications.KindNewLogin, notifications.Details{
+			IpAddress: internal.ClientIp(h.config, c.Request),
+			UserAgent: c.GetHeader("User-Agent"),
+		})
+	}
+
+	accessToken, err := h.createAccessToken(c.Request.Context(), &user, session)
+	if err != nil {
+		logger.Error("Error creating access token: %v", err)
+		return internal.ApiError[LoginResponse](err)
+	}
+
+	refreshToken, err := h.createRefreshToken(&user, session)
 	if err != nil {
 		logger.Error("Error creating refresh token: %v", err)
 		return internal.GenericError[LoginResponse]()
 	}
 
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserLoggedIn,
+		Actor:        audit.Actor{UserID: user.ID},
+		TargetUserID: user.ID,
+		Metadata:     map[string]any{"session_id": session.ID, "new_device": newDevice},
+	})
+	hooks.AfterLogin(c.Request.Context(), user)
+
 	logger.Debug("Setting auth cookies")
-	h.setAuthCookies(c, accessToken, refreshToken)
+	h.setAuthCookies(&c.Cookies, accessToken, refreshToken)
 
 	return &ctx.Response[LoginResponse]{
 		Response: LoginResponse{
@@ -200,6 +369,23 @@ func (h *AuthHandlers) Login(c *ctx.Request[LoginRequest]) *ctx.Response[LoginRe
 func (h *AuthHandlers) Logout(c *ctx.Request[LogoutRequest]) *ctx.Response[LogoutResponse] {
 	logger.Info("Invoked: Logout")
 
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err == nil {
+		logger.Debug("Revoking session")
+		err = h.queries.RevokeSession(c.Request.Context(), principal.Session.ID)
+		if err != nil {
+			logger.Error("Error revoking session: %v", err)
+			return internal.GenericError[LogoutResponse]()
+		}
+
+		h.auditor.Record(c.Request, audit.Event{
+			Action:       audit.ActionUserLoggedOut,
+			Actor:        principal.Actor(),
+			TargetUserID: principal.User.ID,
+			Metadata:     map[string]any{"session_id": principal.Session.ID},
+		})
+	}
+
 	logger.Debug("Clearing auth cookies")
 	h.clearAuthCookies(c)
 
@@ -215,6 +401,8 @@ func (h *AuthHandlers) Logout(c *ctx.Request[LogoutRequest]) *ctx.Response[Logou
 func (h *AuthHandlers) SendEmailVerification(c *ctx.Request[SendVerificationEmailRequest]) *ctx.Response[SendVerificationEmailResponse] {
 	logger.Info("Invoked: SendVerificationEmail")
 
+	defer h.padResponseTime(time.Now())
+
 	logger.Debug("Finding user by email")
 	user, err := h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -222,18 +410,14 @@ func (h *AuthHandlers) SendEmailVerification(c *ctx.Request[SendVerificationEmai
 		return internal.GenericError[SendVerificationEmailResponse]()
 	}
 
-	if !errors.Is(err, sql.ErrNoRows) {
-		if user.Verified {
-			logger.Error("User is already verified")
-			return internal.CustomError[SendVerificationEmailResponse]("user is already verified")
-		}
-
+	if !errors.Is(err, sql.ErrNoRows) && !user.Verified && lifecycle.CanVerifyEmail(user) {
 		logger.Debug("Creating verification link")
 		verificationLink, err := createVerificationLink(VerificationLinkOpts[SendVerificationEmailRequest]{
 			Request: c,
 			Config:  h.config,
 			UserId:  user.ID,
 			Path:    "auth/verify-email",
+			Purpose: purposeEmailVerification,
 		})
 
 		if err != nil {
@@ -241,19 +425,19 @@ func (h *AuthHandlers) SendEmailVerification(c *ctx.Request[SendVerificationEmai
 			return internal.GenericError[SendVerificationEmailResponse]()
 		}
 
-		err = h.mailer.SendEmail(h.config.EmailFrom, []string{c.Body.Email}, "Email Verification", "email_verification", map[string]any{
+		internal.SendEmailAsync(h.mailer, h.config.EmailFrom, []string{c.Body.Email}, "Email Verification", "email_verification", map[string]any{
 			"VerificationLink": verificationLink,
 		})
 
-		if err != nil {
-			logger.Error("Error sending email verification email: %v", err)
-			return internal.GenericError[SendVerificationEmailResponse]()
-		}
+		h.auditor.Record(c.Request, audit.Event{
+			Action:       audit.ActionEmailVerificationSent,
+			TargetUserID: user.ID,
+		})
 	}
 
 	return &ctx.Response[SendVerificationEmailResponse]{
 		Response: SendVerificationEmailResponse{
-			Message: "If this user exists, you will receive a verification email shortly.",
+			Message: "If this user exists and is not yet verified, you will receive a verification email shortly.",
 		},
 		StatusCode: http.StatusOK,
 		Error:      nil,
@@ -263,6 +447,8 @@ func (h *AuthHandlers) SendEmailVerification(c *ctx.Request[SendVerificationEmai
 func (h *AuthHandlers) SendPasswordResetLink(c *ctx.Request[SendPasswordResetRequest]) *ctx.Response[SendPasswordResetResponse] {
 	logger.Info("Invoked: SendPasswordResetVerification")
 
+	defer h.padResponseTime(time.Now())
+
 	logger.Debug("Finding user by email")
 	user, err := h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -270,13 +456,14 @@ func (h *AuthHandlers) SendPasswordResetLink(c *ctx.Request[SendPasswordResetReq
 		return internal.GenericError[SendPasswordResetResponse]()
 	}
 
-	if !errors.Is(err, sql.ErrNoRows) {
+	if !errors.Is(err, sql.ErrNoRows) && lifecycle.IsActive(user) {
 		logger.Debug("Creating verification link")
 		verificationLink, err := createVerificationLink(VerificationLinkOpts[SendPasswordResetRequest]{
 			Request: c,
 			Config:  h.config,
 			UserId:  user.ID,
 			Path:    "auth/reset-password",
+			Purpose: purposePasswordReset,
 		})
 
 		if err != nil {
@@ -284,14 +471,14 @@ func (h *AuthHandlers) SendPasswordResetLink(c *ctx.Request[SendPasswordResetReq
 			return internal.GenericError[SendPasswordResetResponse]()
 		}
 
-		err = h.mailer.SendEmail(h.config.EmailFrom, []string{c.Body.Email}, "Password Reset", "password_reset_verification", map[string]any{
+		internal.SendEmailAsync(h.mailer, h.config.EmailFrom, []string{c.Body.Email}, "Password Reset", "password_reset_verification", map[string]any{
 			"VerificationLink": verificationLink,
 		})
 
-		if err != nil {
-			logger.Error("Error sending password reset verification email: %v", err)
-			return internal.GenericError[SendPasswordResetResponse]()
-		}
+		h.auditor.Record(c.Request, audit.Event{
+			Action:       audit.ActionPasswordResetRequested,
+			TargetUserID: user.ID,
+		})
 	}
 
 	return &ctx.Response[SendPasswordResetResponse]{
@@ -308,20 +495,14 @@ func (h *AuthHandlers) ResetPassword(c *ctx.Request[ResetPasswordRequest]) *ctx.
 
 	if h.isTokenBlacklisted(c.Body.Token) {
 		logger.Error("Token is blacklisted")
-		return internal.CustomError[ResetPasswordResponse]("token is blacklisted")
-	}
-
-	err := h.blacklistToken(c.Body.Token)
-	if err != nil {
-		logger.Error("Error blacklisting token: %v", err)
-		return internal.GenericError[ResetPasswordResponse]()
+		return internal.CustomError[ResetPasswordResponse](apierror.TokenRevoked, "token is blacklisted")
 	}
 
 	logger.Debug("Decoding, decrypting, and verifying token")
-	claims, err := processVerificationToken(c.Body.Token, h.config)
+	claims, err := processVerificationToken(c.Body.Token, h.config, purposePasswordReset)
 	if err != nil {
 		logger.Error("Error processing verification token: %v", err)
-		return internal.CustomError[ResetPasswordResponse](err.Error())
+		return internal.ApiError[ResetPasswordResponse](err)
 	}
 
 	logger.Debug("Validating user id")
@@ -329,25 +510,75 @@ func (h *AuthHandlers) ResetPassword(c *ctx.Request[ResetPasswordRequest]) *ctx.
 	userId, err := validateUserIdInterface(userIdInterface)
 	if err != nil {
 		logger.Error("Error validating user id: %v", err)
-		return internal.CustomError[ResetPasswordResponse](err.Error())
+		return internal.ApiError[ResetPasswordResponse](err)
+	}
+
+	logger.Debug("Checking account status")
+	err = h.checkAccountStatus(c.Request.Context(), userId, lifecycle.Check)
+	if err != nil {
+		logger.Error("Error checking account status: %v", err)
+		return internal.ApiError[ResetPasswordResponse](err)
+	}
+
+	logger.Debug("Checking password history")
+	err = h.passwordHistory.Check(c.Request.Context(), userId, c.Body.NewPassword)
+	if errors.Is(err, password.ErrPasswordReused) {
+		logger.Error("New password was used recently")
+		return internal.ApiError[ResetPasswordResponse](err)
+	}
+	if err != nil {
+		logger.Error("Error checking password history: %v", err)
+		return internal.GenericError[ResetPasswordResponse]()
 	}
 
-	newPasswordHash, err := security.Hash([]byte(c.Body.NewPassword))
+	err = h.blacklistToken(c.Body.Token)
+	if err != nil {
+		logger.Error("Error blacklisting token: %v", err)
+		return internal.GenericError[ResetPasswordResponse]()
+	}
+
+	newPasswordHash, err := h.passwordHasher.Hash(c.Body.NewPassword)
 	if err != nil {
 		logger.Error("Error hashing new password: %v", err)
 		return internal.GenericError[ResetPasswordResponse]()
 	}
 
 	logger.Debug("Updating user password")
-	_, err = h.queries.UpdateUserPassword(c.Request.Context(), database.UpdateUserPasswordParams{
+	user, err := h.queries.UpdateUserPassword(c.Request.Context(), database.UpdateUserPasswordParams{
 		ID:           userId,
-		PasswordHash: newPasswordHash.Hash,
+		PasswordHash: newPasswordHash,
 	})
 	if err != nil {
 		logger.Error("Error updating user password: %v", err)
 		return internal.GenericError[ResetPasswordResponse]()
 	}
 
+	logger.Debug("Recording password history")
+	err = h.passwordHistory.Record(c.Request.Context(), userId, newPasswordHash)
+	if err != nil {
+		logger.Error("Error recording password history: %v", err)
+		return internal.GenericError[ResetPasswordResponse]()
+	}
+
+	logger.Debug("Revoking all sessions")
+	err = h.queries.RevokeUserSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Error revoking sessions: %v", err)
+		return internal.GenericError[ResetPasswordResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionPasswordReset,
+		Actor:        audit.Actor{UserID: user.ID},
+		TargetUserID: user.ID,
+	})
+	hooks.AfterPasswordReset(c.Request.Context(), user)
+
+	h.notifier.Notify(user, notifications.KindPasswordChanged, notifications.Details{
+		IpAddress: internal.ClientIp(h.config, c.Request),
+		UserAgent: c.GetHeader("User-Agent"),
+	})
+
 	return &ctx.Response[ResetPasswordResponse]{
 		Response: ResetPasswordResponse{
 			Message: "Password has been reset",
@@ -357,57 +588,546 @@ func (h *AuthHandlers) ResetPassword(c *ctx.Request[ResetPasswordRequest]) *ctx.
 	}
 }
 
-func (h *AuthHandlers) OtpSend(c *ctx.Request[OtpSendRequest]) *ctx.Response[OtpSendResponse] {
-	logger.Info("Invoked: TwoFactorSend")
+func (h *AuthHandlers) ChangePassword(c *ctx.Request[ChangePasswordRequest]) *ctx.Response[ChangePasswordResponse] {
+	logger.Info("Invoked: ChangePassword")
 
-	logger.Debug("Finding user by email")
-	user, err := h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[ChangePasswordResponse](err)
+	}
+
+	err = principal.ForbidImpersonation()
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[ChangePasswordResponse](err)
+	}
+
+	user := principal.User
+
+	logger.Debug("Comparing current password with hash")
+	err = h.passwordHasher.Verify(user.PasswordHash, c.Body.CurrentPassword)
+	if err != nil {
+		logger.Error("Error verifying current password: %v", err)
+		h.auditor.Record(c.Request, audit.Event{
+			Action:       audit.ActionPasswordChanged,
+			Outcome:      audit.OutcomeFailure,
+			Actor:        principal.Actor(),
+			TargetUserID: user.ID,
+			Metadata:     map[string]any{"reason": "incorrect_password"},
+		})
+		return internal.CustomError[ChangePasswordResponse](apierror.IncorrectPassword, "current password is incorrect")
+	}
+
+	logger.Debug("Checking password history")
+	err = h.passwordHistory.Check(c.Request.Context(), user.ID, c.Body.NewPassword)
+	if errors.Is(err, password.ErrPasswordReused) {
+		logger.Error("New password was used recently")
+		return internal.ApiError[ChangePasswordResponse](err)
+	}
+	if err != nil {
+		logger.Error("Error checking password history: %v", err)
+		return internal.GenericError[ChangePasswordResponse]()
+	}
+
+	newPasswordHash, err := h.passwordHasher.Hash(c.Body.NewPassword)
+	if err != nil {
+		logger.Error("Error hashing new password: %v", err)
+		return internal.GenericError[ChangePasswordResponse]()
+	}
+
+	logger.Debug("Updating user password")
+	updatedUser, err := h.queries.UpdateUserPassword(c.Request.Context(), database.UpdateUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: newPasswordHash,
+	})
+	if err != nil {
+		logger.Error("Error updating user password: %v", err)
+		return internal.GenericError[ChangePasswordResponse]()
+	}
+
+	logger.Debug("Recording password history")
+	err = h.passwordHistory.Record(c.Request.Context(), user.ID, newPasswordHash)
+	if err != nil {
+		logger.Error("Error recording password history: %v", err)
+		return internal.GenericError[ChangePasswordResponse]()
+	}
+
+	logger.Debug("Revoking other sessions")
+	err = h.queries.RevokeOtherUserSessions(c.Request.Context(), database.RevokeOtherUserSessionsParams{
+		UserID: user.ID,
+		ID:     principal.Session.ID,
+	})
+	if err != nil {
+		logger.Error("Error revoking other sessions: %v", err)
+		return internal.GenericError[ChangePasswordResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionPasswordChanged,
+		Actor:        principal.Actor(),
+		TargetUserID: user.ID,
+	})
+	hooks.AfterPasswordChange(c.Request.Context(), updatedUser)
+
+	h.notifier.Notify(user, notifications.KindPasswordChanged, notifications.Details{
+		IpAddress: internal.ClientIp(h.config, c.Request),
+		UserAgent: c.GetHeader("User-Agent"),
+	})
+
+	return &ctx.Response[ChangePasswordResponse]{
+		Response: ChangePasswordResponse{
+			Message: "Your password has been changed",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthHandlers) ChangeEmail(c *ctx.Request[ChangeEmailRequest]) *ctx.Response[ChangeEmailResponse] {
+	logger.Info("Invoked: ChangeEmail")
+
+	defer h.padResponseTime(time.Now())
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[ChangeEmailResponse](err)
+	}
+
+	err = principal.ForbidImpersonation()
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[ChangeEmailResponse](err)
+	}
+
+	user := principal.User
+
+	logger.Debug("Comparing password with hash")
+	err = h.passwordHasher.Verify(user.PasswordHash, c.Body.Password)
+	if err != nil {
+		logger.Error("Error verifying password: %v", err)
+		h.auditor.Record(c.Request, audit.Event{
+			Action:       audit.ActionEmailChangeRequested,
+			Outcome:      audit.OutcomeFailure,
+			Actor:        principal.Actor(),
+			TargetUserID: user.ID,
+			Metadata:     map[string]any{"reason": "incorrect_password"},
+		})
+		return internal.CustomError[ChangeEmailResponse](apierror.IncorrectPassword, "password is incorrect")
+	}
+
+	if c.Body.NewEmail == user.Email {
+		logger.Error("New email is the same as the current email")
+		return internal.CustomError[ChangeEmailResponse](apierror.EmailUnchanged, "new email must be different from your current email")
+	}
+
+	err = h.registration.CheckEmail(c.Body.NewEmail)
 	if err != nil {
+		logger.Error("New email is not allowed: %v", err)
+		return internal.ApiError[ChangeEmailResponse](err)
+	}
+
+	logger.Debug("Finding user by new email")
+	_, err = h.queries.FindUserByEmail(c.Request.Context(), c.Body.NewEmail)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		logger.Error("Error finding user by email: %v", err)
-		return internal.GenericError[OtpSendResponse]()
+		return internal.GenericError[ChangeEmailResponse]()
 	}
 
-	if !user.Verified {
-		logger.Error("User is not verified")
-		return internal.CustomError[OtpSendResponse]("please verify your email to enable 2FA")
+	if errors.Is(err, sql.ErrNoRows) {
+		changeId := uuid.New().String()
+
+		logger.Debug("Creating email change confirmation link")
+		confirmationLink, err := createVerificationLink(VerificationLinkOpts[ChangeEmailRequest]{
+			Request: c,
+			Config:  h.config,
+			UserId:  user.ID,
+			Path:    "auth/confirm-email-change",
+			Purpose: purposeEmailChange,
+			Claims: security.JwtClaims{
+				"change_id": changeId,
+				"old_email": user.Email,
+				"new_email": c.Body.NewEmail,
+			},
+		})
+		if err != nil {
+			logger.Error("Error creating email change confirmation link: %v", err)
+			return internal.GenericError[ChangeEmailResponse]()
+		}
+
+		logger.Debug("Creating email change revert link")
+		revertLink, err := createVerificationLink(VerificationLinkOpts[ChangeEmailRequest]{
+			Request:   c,
+			Config:    h.config,
+			UserId:    user.ID,
+			Path:      "auth/revert-email-change",
+			Purpose:   purposeEmailChangeRevert,
+			ExpiresIn: time.Hour * 24 * 7,
+			Claims: security.JwtClaims{
+				"change_id": changeId,
+				"old_email": user.Email,
+			},
+		})
+		if err != nil {
+			logger.Error("Error creating email change revert link: %v", err)
+			return internal.GenericError[ChangeEmailResponse]()
+		}
+
+		internal.SendEmailAsync(h.mailer, h.config.EmailFrom, []string{c.Body.NewEmail}, "Confirm Your New Email", "email_change_confirmation", map[string]any{
+			"ConfirmationLink": confirmationLink,
+		})
+
+		internal.SendEmailAsync(h.mailer, h.config.EmailFrom, []string{user.Email}, "Email Change Requested", "email_change_notice", map[string]any{
+			"NewEmail":   c.Body.NewEmail,
+			"RevertLink": revertLink,
+		})
+
+		h.auditor.Record(c.Request, audit.Event{
+			Action:       audit.ActionEmailChangeRequested,
+			Actor:        principal.Actor(),
+			TargetUserID: user.ID,
+			Metadata:     map[string]any{"change_id": changeId, "new_email": c.Body.NewEmail},
+		})
+	}
+
+	return &ctx.Response[ChangeEmailResponse]{
+		Response: ChangeEmailResponse{
+			Message: "If this email is not already in use, you will receive a confirmation link at the new address shortly.",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthHandlers) ConfirmEmailChange(c *ctx.Request[ConfirmEmailChangeRequest]) *ctx.Response[ConfirmEmailChangeResponse] {
+	logger.Info("Invoked: ConfirmEmailChange")
+
+	if h.isTokenBlacklisted(c.Body.Token) {
+		logger.Error("Token is blacklisted")
+		return internal.CustomError[ConfirmEmailChangeResponse](apierror.TokenRevoked, "token is blacklisted")
+	}
+
+	logger.Debug("Decoding, decrypting, and verifying token")
+	claims, err := processVerificationToken(c.Body.Token, h.config, purposeEmailChange)
+	if err != nil {
+		logger.Error("Error processing verification token: %v", err)
+		return internal.ApiError[ConfirmEmailChangeResponse](err)
+	}
+
+	logger.Debug("Validating token claims")
+	userId, err := validateUserIdInterface(claims["user_id"])
+	if err != nil {
+		logger.Error("Error validating user id: %v", err)
+		return internal.ApiError[ConfirmEmailChangeResponse](err)
 	}
 
-	if !user.TwoFactorEnabled {
-		logger.Error("User does not have 2FA enabled")
-		return internal.CustomError[OtpSendResponse]("User does not have 2FA enabled")
+	changeId, err := validateChangeIdInterface(claims["change_id"])
+	if err != nil {
+		logger.Error("Error validating change id: %v", err)
+		return internal.ApiError[ConfirmEmailChangeResponse](err)
 	}
 
-	logger.Debug("Creating OTP code")
-	otpCode, err := generateOtp(6)
+	oldEmail, err := validateEmailInterface(claims["old_email"])
 	if err != nil {
-		logger.Error("Error generating OTP code: %v", err)
-		return internal.GenericError[OtpSendResponse]()
+		logger.Error("Error validating old email: %v", err)
+		return internal.ApiError[ConfirmEmailChangeResponse](err)
+	}
+
+	newEmail, err := validateEmailInterface(claims["new_email"])
+	if err != nil {
+		logger.Error("Error validating new email: %v", err)
+		return internal.ApiError[ConfirmEmailChangeResponse](err)
+	}
+
+	if h.isTokenBlacklisted(emailChangeMarker(changeId)) {
+		logger.Error("Email change has already been confirmed or reverted")
+		return internal.CustomError[ConfirmEmailChangeResponse](apierror.EmailChangeInvalid, "this email change is no longer valid")
+	}
+
+	logger.Debug("Finding user by id")
+	user, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if err != nil {
+		logger.Error("Error finding user by id: %v", err)
+		return internal.GenericError[ConfirmEmailChangeResponse]()
+	}
+
+	err = lifecycle.Check(user)
+	if err != nil {
+		logger.Error("Account is not active: %v", err)
+		return internal.ApiError[ConfirmEmailChangeResponse](err)
+	}
+
+	if user.Email != oldEmail {
+		logger.Error("User email has changed since the email change was requested")
+		return internal.CustomError[ConfirmEmailChangeResponse](apierror.EmailChangeInvalid, "this email change is no longer valid")
+	}
+
+	logger.Debug("Finding user by new email")
+	_, err = h.queries.FindUserByEmail(c.Request.Context(), newEmail)
+	if err == nil {
+		logger.Error("New email is already in use")
+		return internal.CustomError[ConfirmEmailChangeResponse](apierror.EmailTaken, "this email is already in use")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Error finding user by email: %v", err)
+		return internal.GenericError[ConfirmEmailChangeResponse]()
+	}
+
+	err = h.blacklistToken(c.Body.Token)
+	if err != nil {
+		logger.Error("Error blacklisting token: %v", err)
+		return internal.GenericError[ConfirmEmailChangeResponse]()
 	}
 
-	logger.Debug("Creating OTP code in database")
-	code, err := h.queries.CreateOtpCode(c.Request.Context(), database.CreateOtpCodeParams{
-		ID:        uuid.New().String(),
-		Code:      otpCode,
-		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(3 * time.Minute), Valid: true},
+	logger.Debug("Updating user email")
+	updated, err := h.queries.UpdateUserEmail(c.Request.Context(), database.UpdateUserEmailParams{
+		ID:    user.ID,
+		Email: newEmail,
 	})
 	if err != nil {
-		logger.Error("Error creating OTP code in database: %v", err)
-		return internal.GenericError[OtpSendResponse]()
+		logger.Error("Error updating user email: %v", err)
+		return internal.GenericError[ConfirmEmailChangeResponse]()
 	}
 
-	err = h.mailer.SendEmail(h.config.EmailFrom, []string{c.Body.Email}, "Two-Factor Authentication", "two_factor_email_otp", map[string]any{
-		"OtpCode":       otpCode,
-		"ExpiryMinutes": 3,
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionEmailChanged,
+		Actor:        audit.Actor{UserID: user.ID},
+		TargetUserID: user.ID,
+		Metadata:     map[string]any{"change_id": changeId, "old_email": oldEmail, "new_email": newEmail},
 	})
+	h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserEmailChanged, updated, map[string]any{"old_email": oldEmail})
+	hooks.AfterEmailChange(c.Request.Context(), updated, oldEmail)
+
+	h.notifier.Notify(user, notifications.KindEmailChanged, notifications.Details{
+		IpAddress: internal.ClientIp(h.config, c.Request),
+		UserAgent: c.GetHeader("User-Agent"),
+		NewEmail:  newEmail,
+	})
+
+	return &ctx.Response[ConfirmEmailChangeResponse]{
+		Response: ConfirmEmailChangeResponse{
+			Message: "Your email has been changed",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthHandlers) RevertEmailChange(c *ctx.Request[RevertEmailChangeRequest]) *ctx.Response[RevertEmailChangeResponse] {
+	logger.Info("Invoked: RevertEmailChange")
+
+	if h.isTokenBlacklisted(c.Body.Token) {
+		logger.Error("Token is blacklisted")
+		return internal.CustomError[RevertEmailChangeResponse](apierror.TokenRevoked, "token is blacklisted")
+	}
+
+	logger.Debug("Decoding, decrypting, and verifying token")
+	claims, err := processVerificationToken(c.Body.Token, h.config, purposeEmailChangeRevert)
+	if err != nil {
+		logger.Error("Error processing verification token: %v", err)
+		return internal.ApiError[RevertEmailChangeResponse](err)
+	}
+
+	logger.Debug("Validating token claims")
+	userId, err := validateUserIdInterface(claims["user_id"])
+	if err != nil {
+		logger.Error("Error validating user id: %v", err)
+		return internal.ApiError[RevertEmailChangeResponse](err)
+	}
+
+	changeId, err := validateChangeIdInterface(claims["change_id"])
 	if err != nil {
-		logger.Error("Error sending OTP code: %v", err)
+		logger.Error("Error validating change id: %v", err)
+		return internal.ApiError[RevertEmailChangeResponse](err)
+	}
+
+	oldEmail, err := validateEmailInterface(claims["old_email"])
+	if err != nil {
+		logger.Error("Error validating old email: %v", err)
+		return internal.ApiError[RevertEmailChangeResponse](err)
+	}
+
+	logger.Debug("Finding user by id")
+	user, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if err != nil {
+		logger.Error("Error finding user by id: %v", err)
+		return internal.GenericError[RevertEmailChangeResponse]()
+	}
+
+	err = lifecycle.Check(user)
+	if err != nil {
+		logger.Error("Account is not active: %v", err)
+		return internal.ApiError[RevertEmailChangeResponse](err)
+	}
+
+	err = h.blacklistToken(c.Body.Token)
+	if err != nil {
+		logger.Error("Error blacklisting token: %v", err)
+		return internal.GenericError[RevertEmailChangeResponse]()
+	}
+
+	logger.Debug("Cancelling pending email change")
+	err = h.blacklistToken(emailChangeMarker(changeId))
+	if err != nil {
+		logger.Error("Error blacklisting email change: %v", err)
+		return internal.GenericError[RevertEmailChangeResponse]()
+	}
+
+	if user.Email != oldEmail {
+		logger.Debug("Restoring previous user email")
+		restored, err := h.queries.UpdateUserEmail(c.Request.Context(), database.UpdateUserEmailParams{
+			ID:    user.ID,
+			Email: oldEmail,
+		})
+		if err != nil {
+			logger.Error("Error restoring user email: %v", err)
+			return internal.GenericError[RevertEmailChangeResponse]()
+		}
+
+		h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserEmailChanged, restored, map[string]any{"old_email": user.Email})
+		hooks.AfterEmailChange(c.Request.Context(), restored, user.Email)
+	}
+
+	logger.Debug("Revoking all sessions")
+	err = h.queries.RevokeUserSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Error revoking sessions: %v", err)
+		return internal.GenericError[RevertEmailChangeResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionEmailChangeReverted,
+		Actor:        audit.Actor{UserID: user.ID},
+		TargetUserID: user.ID,
+		Metadata:     map[string]any{"change_id": changeId, "restored_email": oldEmail},
+	})
+
+	return &ctx.Response[RevertEmailChangeResponse]{
+		Response: RevertEmailChangeResponse{
+			Message: "The email change has been reverted and all sessions have been signed out. We recommend changing your password.",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthHandlers) UpdateNotificationPreferences(c *ctx.Request[UpdateNotificationPreferencesRequest]) *ctx.Response[UpdateNotificationPreferencesResponse] {
+	logger.Info("Invoked: UpdateNotificationPreferences")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[UpdateNotificationPreferencesResponse](err)
+	}
+
+	err = principal.ForbidImpersonation()
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[UpdateNotificationPreferencesResponse](err)
+	}
+
+	optOuts := c.Body.OptOuts
+	if optOuts == nil {
+		optOuts = []string{}
+	}
+
+	logger.Debug("Validating notification opt-outs")
+	err = notifications.ValidateOptOuts(optOuts)
+	if err != nil {
+		logger.Error("Error validating notification opt-outs: %v", err)
+		return internal.ApiError[UpdateNotificationPreferencesResponse](err)
+	}
+
+	logger.Debug("Updating notification opt-outs")
+	user, err := h.queries.UpdateUserNotificationOptOuts(c.Request.Context(), database.UpdateUserNotificationOptOutsParams{
+		ID:                  principal.User.ID,
+		NotificationOptOuts: optOuts,
+	})
+	if err != nil {
+		logger.Error("Error updating notification opt-outs: %v", err)
+		return internal.GenericError[UpdateNotificationPreferencesResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionNotificationsUpdated,
+		Actor:        principal.Actor(),
+		TargetUserID: user.ID,
+		Metadata:     map[string]any{"opt_outs": user.NotificationOptOuts},
+	})
+
+	return &ctx.Response[UpdateNotificationPreferencesResponse]{
+		Response: UpdateNotificationPreferencesResponse{
+			Message: "Your notification preferences have been updated",
+			OptOuts: user.NotificationOptOuts,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthHandlers) OtpSend(c *ctx.Request[OtpSendRequest]) *ctx.Response[OtpSendResponse] {
+	logger.Info("Invoked: TwoFactorSend")
+
+	defer h.padResponseTime(time.Now())
+
+	// Callers that can't be sent an OTP get a random id that will never verify,
+	// so the response doesn't reveal whether the account exists or has 2FA.
+	otpCodeId := uuid.New().String()
+
+	logger.Debug("Finding user by email")
+	user, err := h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Error finding user by email: %v", err)
 		return internal.GenericError[OtpSendResponse]()
 	}
 
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Debug("User does not exist, skipping OTP")
+	} else if !lifecycle.IsActive(user) {
+		logger.Debug("User is not active, skipping OTP")
+	} else if !user.Verified {
+		logger.Debug("User is not verified, skipping OTP")
+	} else if !user.TwoFactorEnabled {
+		logger.Debug("User does not have 2FA enabled, skipping OTP")
+	} else {
+		logger.Debug("Creating OTP code")
+		otpCode, err := generateOtp(6)
+		if err != nil {
+			logger.Error("Error generating OTP code: %v", err)
+			return internal.GenericError[OtpSendResponse]()
+		}
+
+		logger.Debug("Creating OTP code in database")
+		code, err := h.queries.CreateOtpCode(c.Request.Context(), database.CreateOtpCodeParams{
+			ID:        otpCodeId,
+			Code:      otpCode,
+			ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(3 * time.Minute), Valid: true},
+			UserID:    pgtype.Text{String: user.ID, Valid: true},
+		})
+		if err != nil {
+			logger.Error("Error creating OTP code in database: %v", err)
+			return internal.GenericError[OtpSendResponse]()
+		}
+
+		otpCodeId = code.ID
+
+		h.auditor.Record(c.Request, audit.Event{
+			Action:       audit.ActionOtpSent,
+			TargetUserID: user.ID,
+			Metadata:     map[string]any{"otp_code_id": otpCodeId},
+		})
+
+		internal.SendEmailAsync(h.mailer, h.config.EmailFrom, []string{c.Body.Email}, "Two-Factor Authentication", "two_factor_email_otp", map[string]any{
+			"OtpCode":       otpCode,
+			"ExpiryMinutes": 3,
+		})
+	}
+
 	return &ctx.Response[OtpSendResponse]{
 		Response: OtpSendResponse{
-			Message:   "We have sent an OTP to your email",
-			OtpCodeId: code.ID,
+			Message:   "If this account has two-factor authentication enabled, we have sent an OTP to your email",
+			OtpCodeId: otpCodeId,
 		},
 		StatusCode: http.StatusOK,
 		Error:      nil,
@@ -419,6 +1139,10 @@ func (h *AuthHandlers) OtpVerify(c *ctx.Request[OtpVerifyRequest]) *ctx.Response
 
 	logger.Debug("Finding OTP code by id")
 	otpCode, err := h.queries.FindOtpCodeById(c.Request.Context(), c.Body.OtpCodeId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("OTP code does not exist")
+		return internal.CustomError[OtpVerifyResponse](apierror.OtpInvalid, "invalid OTP code")
+	}
 	if err != nil {
 		logger.Error("Error finding OTP code by id: %v", err)
 		return internal.GenericError[OtpVerifyResponse]()
@@ -426,14 +1150,27 @@ func (h *AuthHandlers) OtpVerify(c *ctx.Request[OtpVerifyRequest]) *ctx.Response
 
 	if otpCode.ExpiresAt.Time.Before(time.Now()) {
 		logger.Error("OTP code has expired")
-		return internal.CustomError[OtpVerifyResponse]("OTP code has expired")
+		h.recordOtpVerification(c, otpCode.UserID.String, audit.OutcomeFailure, "expired")
+		return internal.CustomError[OtpVerifyResponse](apierror.OtpExpired, "OTP code has expired")
 	}
 
-	if otpCode.Code != c.Body.OtpCode {
+	if subtle.ConstantTimeCompare([]byte(otpCode.Code), []byte(c.Body.OtpCode)) != 1 {
 		logger.Error("Invalid OTP code")
-		return internal.CustomError[OtpVerifyResponse]("invalid OTP code")
+		h.recordOtpVerification(c, otpCode.UserID.String, audit.OutcomeFailure, "incorrect_code")
+		return internal.CustomError[OtpVerifyResponse](apierror.OtpInvalid, "invalid OTP code")
 	}
 
+	if otpCode.UserID.Valid {
+		logger.Debug("Checking account status")
+		err = h.checkAccountStatus(c.Request.Context(), otpCode.UserID.String, lifecycle.Check)
+		if err != nil {
+			logger.Error("Error checking account status: %v", err)
+			return internal.ApiError[OtpVerifyResponse](err)
+		}
+	}
+
+	h.recordOtpVerification(c, otpCode.UserID.String, audit.OutcomeSuccess, "")
+
 	return &ctx.Response[OtpVerifyResponse]{
 		Response: OtpVerifyResponse{
 			Message: "Successfully verified OTP code",
@@ -442,3 +1179,326 @@ func (h *AuthHandlers) OtpVerify(c *ctx.Request[OtpVerifyRequest]) *ctx.Response
 		Error:      nil,
 	}
 }
+
+func (h *AuthHandlers) SwitchOrganization(c *ctx.Request[SwitchOrganizationRequest]) *ctx.Response[SwitchOrganizationResponse] {
+	logger.Info("Invoked: SwitchOrganization")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[SwitchOrganizationResponse](err)
+	}
+
+	activeOrganizationId := pgtype.Text{}
+	if c.Body.OrganizationID != "" {
+		logger.Debug("Finding membership")
+		_, err = h.queries.FindMembership(c.Request.Context(), database.FindMembershipParams{
+			OrganizationID: c.Body.OrganizationID,
+			UserID:         principal.User.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Error("User is not a member of the organization")
+			return internal.ApiError[SwitchOrganizationResponse](organizations.ErrNotFound)
+		}
+		if err != nil {
+			logger.Error("Error finding membership: %v", err)
+			return internal.GenericError[SwitchOrganizationResponse]()
+		}
+
+		activeOrganizationId = pgtype.Text{String: c.Body.OrganizationID, Valid: true}
+	}
+
+	logger.Debug("Setting active organization")
+	session, err := h.queries.SetSessionActiveOrganization(c.Request.Context(), database.SetSessionActiveOrganizationParams{
+		ID:                   principal.Session.ID,
+		ActiveOrganizationID: activeOrganizationId,
+	})
+	if err != nil {
+		logger.Error("Error setting active organization: %v", err)
+		return internal.GenericError[SwitchOrganizationResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionOrganizationSwitched,
+		Actor:        principal.Actor(),
+		TargetUserID: principal.User.ID,
+		Metadata:     map[string]any{"organization_id": c.Body.OrganizationID},
+	})
+
+	accessToken, err := h.createAccessToken(c.Request.Context(), &principal.User, &session)
+	if err != nil {
+		logger.Error("Error creating access token: %v", err)
+		return internal.ApiError[SwitchOrganizationResponse](err)
+	}
+
+	refreshToken, err := h.createRefreshToken(&principal.User, &session)
+	if err != nil {
+		logger.Error("Error creating refresh token: %v", err)
+		return internal.GenericError[SwitchOrganizationResponse]()
+	}
+
+	logger.Debug("Setting auth cookies")
+	h.setAuthCookies(&c.Cookies, accessToken, refreshToken)
+
+	return &ctx.Response[SwitchOrganizationResponse]{
+		Response: SwitchOrganizationResponse{
+			Message:       "Successfully switched organization",
+			AccessToken:   accessToken,
+			RefreshTokens: refreshToken,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+// AcceptInvitation redeems an invitation. Invitees without an account get one,
+// with the invited email already verified, and invitees with an account are
+// added to the organization they were invited to.
+func (h *AuthHandlers) AcceptInvitation(c *ctx.Request[AcceptInvitationRequest]) *ctx.Response[AcceptInvitationResponse] {
+	logger.Info("Invoked: AcceptInvitation")
+
+	logger.Debug("Finding invitation")
+	invitation, err := h.inviter.Find(c.Request.Context(), c.Body.Token)
+	if err != nil {
+		logger.Error("Error finding invitation: %v", err)
+		return internal.ApiError[AcceptInvitationResponse](err)
+	}
+
+	logger.Debug("Finding user by email")
+	user, err := h.queries.FindUserByEmail(c.Request.Context(), invitation.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Error finding user by email: %v", err)
+		return internal.GenericError[AcceptInvitationResponse]()
+	}
+	exists := err == nil
+
+	if exists {
+		if !invitation.OrganizationID.Valid {
+			logger.Error("Email is already in use")
+			return internal.CustomError[AcceptInvitationResponse](apierror.EmailTaken, "this email is already in use")
+		}
+
+		err = lifecycle.Check(user)
+		if err != nil {
+			logger.Error("Account is not active: %v", err)
+			return internal.ApiError[AcceptInvitationResponse](err)
+		}
+
+		logger.Debug("Finding existing membership")
+		_, err = h.queries.FindMembership(c.Request.Context(), database.FindMembershipParams{
+			OrganizationID: invitation.OrganizationID.String,
+			UserID:         user.ID,
+		})
+		if err == nil {
+			logger.Error("User is already a member")
+			return internal.CustomError[AcceptInvitationResponse](apierror.AlreadyMember, "you are already a member of this organization")
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error("Error finding membership: %v", err)
+			return internal.GenericError[AcceptInvitationResponse]()
+		}
+	} else if c.Body.Password == "" {
+		logger.Error("Password is required to create an account")
+		return internal.ApiError[AcceptInvitationResponse](apierror.Validation(map[string][]string{
+			"password": {"This field is required"},
+		}))
+	} else {
+		logger.Debug("Checking email against registration policy")
+		err = h.registration.CheckEmail(invitation.Email)
+		if err != nil {
+			logger.Error("Email is not allowed: %v", err)
+			return internal.ApiError[AcceptInvitationResponse](err)
+		}
+
+		logger.Debug("Running before register hooks")
+		err = hooks.BeforeRegister(c.Request.Context(), hooks.Registration{
+			Email:        invitation.Email,
+			InvitationID: invitation.ID,
+		})
+		if err != nil {
+			logger.Error("Error running before register hooks: %v", err)
+			return internal.ApiError[AcceptInvitationResponse](err)
+		}
+	}
+
+	passwordHash := ""
+	if !exists {
+		logger.Debug("Hashing password")
+		passwordHash, err = h.passwordHasher.Hash(c.Body.Password)
+		if err != nil {
+			logger.Error("Error hashing password: %v", err)
+			return internal.GenericError[AcceptInvitationResponse]()
+		}
+	}
+
+	// The invitation is accepted in the same transaction as the account and
+	// membership are created, so that a failure doesn't use it up.
+	err = h.queries.InTx(c.Request.Context(), func(queries *database.Queries) error {
+		logger.Debug("Accepting invitation")
+		err := h.inviter.WithTx(queries).Accept(c.Request.Context(), invitation)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			roles := []string{}
+			if !invitation.OrganizationID.Valid && invitation.Role.Valid {
+				roles = append(roles, invitation.Role.String)
+			}
+
+			logger.Debug("Creating user")
+			user, err = queries.CreateInvitedUser(c.Request.Context(), database.CreateInvitedUserParams{
+				ID:           uuid.New().String(),
+				Email:        invitation.Email,
+				PasswordHash: passwordHash,
+				Roles:        roles,
+			})
+			if err != nil {
+				return fmt.Errorf("error creating user: %v", err)
+			}
+
+			logger.Debug("Recording password history")
+			err = h.passwordHistory.WithTx(queries).Record(c.Request.Context(), user.ID, user.PasswordHash)
+			if err != nil {
+				return fmt.Errorf("error recording password history: %v", err)
+			}
+		}
+
+		if invitation.OrganizationID.Valid {
+			logger.Debug("Creating membership")
+			_, err = queries.CreateMembership(c.Request.Context(), database.CreateMembershipParams{
+				OrganizationID: invitation.OrganizationID.String,
+				UserID:         user.ID,
+				Role:           invitation.Role.String,
+			})
+			if err != nil {
+				return fmt.Errorf("error creating membership: %v", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Error("Error accepting invitation: %v", err)
+		return internal.ApiError[AcceptInvitationResponse](err)
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionInvitationAccepted,
+		Actor:        audit.Actor{UserID: user.ID},
+		TargetUserID: user.ID,
+		Metadata: map[string]any{
+			"invitation_id":   invitation.ID,
+			"organization_id": invitation.OrganizationID.String,
+			"created_account": !exists,
+		},
+	})
+
+	if !exists {
+		h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserRegistered, user, nil)
+		hooks.AfterRegister(c.Request.Context(), user)
+	}
+
+	return &ctx.Response[AcceptInvitationResponse]{
+		Response: AcceptInvitationResponse{
+			Message: "Successfully accepted invitation",
+			UserID:  user.ID,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+// Impersonate signs the caller in as another user, so that support staff can
+// see what the user sees. The session is short-lived, and its tokens carry an
+// act claim naming the caller.
+func (h *AuthHandlers) Impersonate(c *ctx.Request[ImpersonateRequest]) *ctx.Response[ImpersonateResponse] {
+	logger.Info("Invoked: Impersonate")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleImpersonator)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[ImpersonateResponse](err)
+	}
+
+	err = principal.ForbidImpersonation()
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[ImpersonateResponse](err)
+	}
+
+	userId := c.GetPathParam("id")
+	if userId == principal.User.ID {
+		logger.Error("User tried to impersonate themselves")
+		return internal.CustomError[ImpersonateResponse](apierror.Forbidden, "you can't impersonate yourself")
+	}
+
+	logger.Debug("Finding user by id")
+	user, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[ImpersonateResponse](apierror.UserNotFound, "user not found")
+	}
+	if err != nil {
+		logger.Error("Error finding user by id: %v", err)
+		return internal.GenericError[ImpersonateResponse]()
+	}
+
+	if !lifecycle.IsActive(user) {
+		logger.Error("User is %s", user.Status)
+		return internal.CustomError[ImpersonateResponse](apierror.AccountStatusConflict, fmt.Sprintf("only active users can be impersonated, but user is %s", user.Status))
+	}
+
+	for _, role := range user.Roles {
+		if !principal.HasRole(role) {
+			logger.Error("User has the %s role, which the caller lacks", role)
+			return internal.CustomError[ImpersonateResponse](apierror.Forbidden, "you can't impersonate a user with roles you don't have")
+		}
+	}
+
+	logger.Debug("Creating impersonation session")
+	impersonation, err := h.queries.CreateImpersonationSession(c.Request.Context(), database.CreateImpersonationSessionParams{
+		ID:             uuid.New().String(),
+		UserID:         user.ID,
+		IpAddress:      internal.ClientIp(h.config, c.Request),
+		UserAgent:      c.GetHeader("User-Agent"),
+		ExpiresAt:      pgtype.Timestamptz{Time: time.Now().Add(time.Duration(h.config.ImpersonationSessionMinutes) * time.Minute), Valid: true},
+		ImpersonatorID: pgtype.Text{String: principal.User.ID, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Error creating impersonation session: %v", err)
+		return internal.GenericError[ImpersonateResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionImpersonationStarted,
+		Actor:        principal.Actor(),
+		TargetUserID: user.ID,
+		Metadata:     map[string]any{"session_id": impersonation.ID, "reason": c.Body.Reason},
+	})
+
+	logger.Debug("Creating access token")
+	accessToken, err := h.createAccessToken(c.Request.Context(), &user, &impersonation)
+	if err != nil {
+		logger.Error("Error creating access token: %v", err)
+		return internal.ApiError[ImpersonateResponse](err)
+	}
+
+	logger.Debug("Creating refresh token")
+	refreshToken, err := h.createRefreshToken(&user, &impersonation)
+	if err != nil {
+		logger.Error("Error creating refresh token: %v", err)
+		return internal.GenericError[ImpersonateResponse]()
+	}
+
+	return &ctx.Response[ImpersonateResponse]{
+		Response: ImpersonateResponse{
+			Message:       "Successfully started impersonation",
+			AccessToken:   accessToken,
+			RefreshTokens: refreshToken,
+			ExpiresAt:     impersonation.ExpiresAt,
+		},
+		StatusCode: http.StatusCreated,
+		Error:      nil,
+	}
+}
@@ -3,6 +3,7 @@ package auth_features
 import (
 	"context"
 	"crypto/rand"
+	"database/sql"
 	"errors"
 	"fmt"
 	"math/big"
@@ -10,17 +11,31 @@ import (
 	"time"
 
 	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/matcha/logger"
 	"github.com/abyanmajid/matcha/security"
 	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/audit"
 	"github.com/abyanmajid/thorfinn/internal/database"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	purposeEmailVerification = "email_verification"
+	purposePasswordReset     = "password_reset"
+	purposeEmailChange       = "email_change"
+	purposeEmailChangeRevert = "email_change_revert"
 )
 
 type VerificationLinkOpts[T any] struct {
-	Request *ctx.Request[T]
-	Config  *internal.EnvConfig
-	UserId  string
-	Path    string
+	Request   *ctx.Request[T]
+	Config    *internal.EnvConfig
+	UserId    string
+	Path      string
+	Purpose   string
+	ExpiresIn time.Duration
+	Claims    security.JwtClaims
 }
 
 func (h *AuthHandlers) isTokenBlacklisted(token string) bool {
@@ -28,6 +43,13 @@ func (h *AuthHandlers) isTokenBlacklisted(token string) bool {
 	return err == nil
 }
 
+// emailChangeMarker is blacklisted once an email change has been reverted, so
+// that its confirmation link stops working. It isn't blacklisted when the
+// change is confirmed, so that the old address can still revert it.
+func emailChangeMarker(changeId string) string {
+	return fmt.Sprintf("email_change:%s", changeId)
+}
+
 func (h *AuthHandlers) blacklistToken(token string) error {
 	_, err := h.queries.CreateBlacklistedToken(context.Background(), database.CreateBlacklistedTokenParams{
 		ID:    uuid.New().String(),
@@ -39,12 +61,59 @@ func (h *AuthHandlers) blacklistToken(token string) error {
 	return nil
 }
 
-func createVerificationLink[T any](opts VerificationLinkOpts[T]) (string, error) {
-	token := security.NewJWT(security.JwtClaims{
-		"user_id": opts.UserId,
-		"iat":     time.Now().Unix(),
-		"exp":     time.Now().Add(time.Minute * 10).Unix(),
+// padResponseTime sleeps until at least AUTH_RESPONSE_FLOOR_MS has passed
+// since start. It is deferred by every endpoint that looks up an account by
+// email, so that existing and non-existing accounts can't be told apart by
+// how long the response takes. A request that takes longer than the floor is
+// padded to the next multiple of it, so that its duration only shows which
+// multiple it fell in.
+func (h *AuthHandlers) padResponseTime(start time.Time) {
+	floor := time.Duration(h.config.AuthResponseFloorMs) * time.Millisecond
+	if floor <= 0 {
+		return
+	}
+
+	elapsed := time.Since(start)
+	padded := max((elapsed+floor-1)/floor, 1) * floor
+
+	time.Sleep(padded - elapsed)
+}
+
+func (h *AuthHandlers) rehashPassword(ctx context.Context, user *database.ThorfinnUser, password string) {
+	passwordHash, err := h.passwordHasher.Hash(password)
+	if err != nil {
+		logger.Error("Error rehashing password: %v", err)
+		return
+	}
+
+	_, err = h.queries.UpdateUserPassword(ctx, database.UpdateUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: passwordHash,
 	})
+	if err != nil {
+		logger.Error("Error storing rehashed password: %v", err)
+		return
+	}
+
+	user.PasswordHash = passwordHash
+}
+
+func createVerificationLink[T any](opts VerificationLinkOpts[T]) (string, error) {
+	expiresIn := opts.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = time.Minute * 10
+	}
+
+	claims := security.JwtClaims{}
+	for key, value := range opts.Claims {
+		claims[key] = value
+	}
+	claims["user_id"] = opts.UserId
+	claims["purpose"] = opts.Purpose
+	claims["iat"] = time.Now().Unix()
+	claims["exp"] = time.Now().Add(expiresIn).Unix()
+
+	token := security.NewJWT(claims)
 
 	signedToken, err := token.Sign([]byte(opts.Config.JwtSecret))
 	if err != nil {
@@ -77,31 +146,35 @@ func generateOtp(length int) (string, error) {
 	return string(bytes), nil
 }
 
-func processVerificationToken(token string, config *internal.EnvConfig) (security.JwtClaims, error) {
+func processVerificationToken(token string, config *internal.EnvConfig, purpose string) (security.JwtClaims, error) {
 	if token == "" {
-		return nil, errors.New("token not found")
+		return nil, apierror.New(apierror.InvalidToken, "token not found")
 	}
 
 	encryptedToken, err := security.DecodeBase64(token)
 	if err != nil {
-		return nil, errors.New("an error occurred while processing your request")
+		return nil, apierror.New(apierror.InvalidToken, "token is invalid or has expired")
 	}
 
 	tokenByte, err := security.Decrypt([]byte(encryptedToken), []byte(config.EncryptionSecret))
 	if err != nil {
-		return nil, errors.New("an error occurred while processing your request")
+		return nil, apierror.New(apierror.InvalidToken, "token is invalid or has expired")
 	}
 
 	verifiedToken, err := security.VerifyJWT(string(tokenByte), []byte(config.JwtSecret))
 	if err != nil {
-		return nil, errors.New("token is invalid or has expired")
+		return nil, apierror.New(apierror.InvalidToken, "token is invalid or has expired")
+	}
+
+	if verifiedToken.JwtClaims["purpose"] != purpose {
+		return nil, apierror.New(apierror.InvalidToken, "token is invalid or has expired")
 	}
 
 	return verifiedToken.JwtClaims, nil
 }
 
-func (h *AuthHandlers) setAuthCookies(c *ctx.Request[LoginRequest], accessToken string, refreshToken string) {
-	c.Cookies.SetCookie("access_token", accessToken, &ctx.CookieOptions{
+func (h *AuthHandlers) setAuthCookies(cookies *ctx.Cookies, accessToken string, refreshToken string) {
+	cookies.SetCookie("access_token", accessToken, &ctx.CookieOptions{
 		Path:     "/",
 		Domain:   fmt.Sprintf(".%s", h.config.RootDomain),
 		MaxAge:   15 * 60,
@@ -111,7 +184,7 @@ func (h *AuthHandlers) setAuthCookies(c *ctx.Request[LoginRequest], accessToken
 		Expires:  time.Now().Add(15 * time.Minute),
 	})
 
-	c.Cookies.SetCookie("refresh_token", refreshToken, &ctx.CookieOptions{
+	cookies.SetCookie("refresh_token", refreshToken, &ctx.CookieOptions{
 		Path:     "/",
 		Domain:   fmt.Sprintf(".%s", h.config.RootDomain),
 		MaxAge:   30 * 24 * 60 * 60,
@@ -144,14 +217,76 @@ func (h *AuthHandlers) clearAuthCookies(c *ctx.Request[LogoutRequest]) {
 	})
 }
 
-func (h *AuthHandlers) createAccessToken(user *database.ThorfinnUser) (string, error) {
-	claims := security.JwtClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"iat":     time.Now().Unix(),
-		"exp":     time.Now().Add(time.Minute * 15).Unix(),
+func (h *AuthHandlers) createSession(c *ctx.Request[LoginRequest], user *database.ThorfinnUser) (*database.ThorfinnSession, error) {
+	session, err := h.queries.CreateSession(c.Request.Context(), database.CreateSessionParams{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		IpAddress: internal.ClientIp(h.config, c.Request),
+		UserAgent: c.GetHeader("User-Agent"),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour * 24 * 30), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// isNewDevice reports whether the user is logging in from an IP address and
+// user agent combination that none of their previous sessions were created
+// from. A user's very first login is not considered a new device.
+func (h *AuthHandlers) isNewDevice(c *ctx.Request[LoginRequest], user *database.ThorfinnUser) bool {
+	count, err := h.queries.CountUserSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Error counting sessions: %v", err)
+		return false
 	}
 
+	if count == 0 {
+		return false
+	}
+
+	known, err := h.queries.HasUserSessionFrom(c.Request.Context(), database.HasUserSessionFromParams{
+		UserID:    user.ID,
+		IpAddress: internal.ClientIp(h.config, c.Request),
+		UserAgent: c.GetHeader("User-Agent"),
+	})
+	if err != nil {
+		logger.Error("Error finding sessions from device: %v", err)
+		return false
+	}
+
+	return !known
+}
+
+func (h *AuthHandlers) createAccessToken(ctx context.Context, user *database.ThorfinnUser, session *database.ThorfinnSession) (string, error) {
+	var membership *database.ThorfinnMembership
+	if session.ActiveOrganizationID.Valid {
+		found, err := h.queries.FindMembership(ctx, database.FindMembershipParams{
+			OrganizationID: session.ActiveOrganizationID.String,
+			UserID:         user.ID,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("error finding membership of active organization: %v", err)
+		}
+		if err == nil {
+			membership = &found
+		}
+	}
+
+	claims, err := h.claimsMapper.Claims(ctx, *user, membership)
+	if err != nil {
+		return "", fmt.Errorf("error mapping claims: %w", err)
+	}
+
+	claims["user_id"] = user.ID
+	claims["email"] = user.Email
+	claims["session_id"] = session.ID
+	claims["token_type"] = "access"
+	claims["iat"] = time.Now().Unix()
+	claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+	addActor(claims, session)
+
 	unsignedAccessToken := security.NewJWT(claims)
 	signedAccessToken, err := unsignedAccessToken.Sign([]byte(h.config.JwtSecret))
 	if err != nil {
@@ -166,13 +301,16 @@ func (h *AuthHandlers) createAccessToken(user *database.ThorfinnUser) (string, e
 	return security.EncodeBase64(encryptedSignedAccessToken), nil
 }
 
-func (h *AuthHandlers) createRefreshToken(user *database.ThorfinnUser) (string, error) {
+func (h *AuthHandlers) createRefreshToken(user *database.ThorfinnUser, session *database.ThorfinnSession) (string, error) {
 	claims := security.JwtClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"iat":     time.Now().Unix(),
-		"exp":     time.Now().Add(time.Hour * 24 * 30).Unix(),
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"session_id": session.ID,
+		"token_type": "refresh",
+		"iat":        time.Now().Unix(),
+		"exp":        time.Now().Add(time.Hour * 24 * 30).Unix(),
 	}
+	addActor(claims, session)
 
 	unsignedRefreshToken := security.NewJWT(claims)
 	signedRefreshToken, err := unsignedRefreshToken.Sign([]byte(h.config.JwtSecret))
@@ -187,3 +325,50 @@ func (h *AuthHandlers) createRefreshToken(user *database.ThorfinnUser) (string,
 
 	return security.EncodeBase64(encryptedSignedRefreshToken), nil
 }
+
+// addActor adds an act claim naming the impersonator (RFC 8693) to the claims
+// of tokens issued for an impersonation session.
+func addActor(claims security.JwtClaims, session *database.ThorfinnSession) {
+	if session.ImpersonatorID.Valid {
+		claims["act"] = map[string]any{"sub": session.ImpersonatorID.String}
+	}
+}
+
+// checkAccountStatus returns the error check reports for the account of
+// userId, typically because it can no longer be used to authenticate.
+func (h *AuthHandlers) checkAccountStatus(ctx context.Context, userId string, check func(database.ThorfinnUser) error) error {
+	user, err := h.queries.FindUserById(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierror.New(apierror.InvalidToken, "token is invalid or has expired")
+	}
+	if err != nil {
+		return err
+	}
+
+	return check(user)
+}
+
+// recordFailedLogin records a failed login to the account of userId, which is
+// empty when no account has the email.
+func (h *AuthHandlers) recordFailedLogin(c *ctx.Request[LoginRequest], userId string, reason string) {
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserLoggedIn,
+		Outcome:      audit.OutcomeFailure,
+		TargetUserID: userId,
+		Metadata:     map[string]any{"email": c.Body.Email, "reason": reason},
+	})
+}
+
+func (h *AuthHandlers) recordOtpVerification(c *ctx.Request[OtpVerifyRequest], userId string, outcome string, reason string) {
+	metadata := map[string]any{"otp_code_id": c.Body.OtpCodeId}
+	if reason != "" {
+		metadata["reason"] = reason
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionOtpVerified,
+		Outcome:      outcome,
+		TargetUserID: userId,
+		Metadata:     metadata,
+	})
+}
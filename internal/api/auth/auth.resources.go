@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/abyanmajid/matcha/openapi"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
 )
 
 type AuthResources struct {
@@ -29,21 +31,21 @@ func (r *AuthResources) RegisterResource() (*openapi.Resource, error) {
 
 	doc := openapi.ResourceDoc{
 		Summary:     "Register a new user",
-		Description: "Check if a user exists, if not, validate the inputs, and send a confirmation email to the user",
+		Description: "Check if a user exists, if not, validate the inputs, and send a confirmation email to the user. Depending on the registration mode, registration may be closed, require an invitation, or create an account that an admin must approve before it can log in",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{
 				Content: openapi.Json(requestSchema),
 			},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Please check your email for a verification link",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.RegistrationClosed, apierror.InvitationRequired, apierror.EmailDomainNotAllowed, apierror.DisposableEmail, apierror.HookRejected),
 		},
 	}
 
-	resource := openapi.NewResource("Register", doc, r.handlers.Register)
+	resource := internal.NewResource("Register", doc, r.handlers.Register)
 
 	return &resource, nil
 }
@@ -64,16 +66,16 @@ func (r *AuthResources) ConfirmEmailResource() (*openapi.Resource, error) {
 		Description: "Verify email",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Email confirmed",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.InvalidToken, apierror.TokenRevoked, apierror.AccountSuspended, apierror.AccountDeleted),
 		},
 	}
 
-	resource := openapi.NewResource("VerifyEmail", doc, r.handlers.VerifyEmail)
+	resource := internal.NewResource("VerifyEmail", doc, r.handlers.VerifyEmail)
 
 	return &resource, nil
 }
@@ -94,16 +96,16 @@ func (r *AuthResources) LoginResource() (*openapi.Resource, error) {
 		Description: "Check if user exists, compare password, and issue an access token",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Login successful",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.InvalidCredentials, apierror.EmailNotVerified, apierror.ApprovalPending, apierror.AccountSuspended, apierror.AccountDeleted, apierror.HookRejected),
 		},
 	}
 
-	resource := openapi.NewResource("Login", doc, r.handlers.Login)
+	resource := internal.NewResource("Login", doc, r.handlers.Login)
 
 	return &resource, nil
 }
@@ -124,16 +126,16 @@ func (r *AuthResources) LogoutResource() (*openapi.Resource, error) {
 		Description: "Logout a user",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Logout successful",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}),
 		},
 	}
 
-	resource := openapi.NewResource("Logout", doc, r.handlers.Logout)
+	resource := internal.NewResource("Logout", doc, r.handlers.Logout)
 
 	return &resource, nil
 }
@@ -154,16 +156,16 @@ func (r *AuthResources) SendEmailVerificationResource() (*openapi.Resource, erro
 		Description: "Send a verification email to the user",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Email sent",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.InvalidRequest, apierror.ValidationFailed),
 		},
 	}
 
-	resource := openapi.NewResource("SendEmailVerification", doc, r.handlers.SendEmailVerification)
+	resource := internal.NewResource("SendEmailVerification", doc, r.handlers.SendEmailVerification)
 
 	return &resource, nil
 }
@@ -184,16 +186,16 @@ func (r *AuthResources) SendPasswordResetLinkResource() (*openapi.Resource, erro
 		Description: "Send a password reset link to the user",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Password reset link sent",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.InvalidRequest, apierror.ValidationFailed),
 		},
 	}
 
-	resource := openapi.NewResource("SendPasswordResetLink", doc, r.handlers.SendPasswordResetLink)
+	resource := internal.NewResource("SendPasswordResetLink", doc, r.handlers.SendPasswordResetLink)
 
 	return &resource, nil
 }
@@ -214,16 +216,166 @@ func (r *AuthResources) ResetPasswordResource() (*openapi.Resource, error) {
 		Description: "Reset a user's password",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Password reset successful",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.InvalidToken, apierror.TokenRevoked, apierror.PasswordReused, apierror.AccountSuspended, apierror.AccountDeleted),
+		},
+	}
+
+	resource := internal.NewResource("ResetPassword", doc, r.handlers.ResetPassword)
+
+	return &resource, nil
+}
+
+func (r *AuthResources) ChangePasswordResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(ChangePasswordRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(ChangePasswordResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Change the current user's password",
+		Description: "Verify the current password, set a new password that satisfies the password policy, revoke all other sessions, and notify the user by email",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Password changed",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.IncorrectPassword, apierror.PasswordReused),
+		},
+	}
+
+	resource := internal.NewResource("ChangePassword", doc, r.handlers.ChangePassword)
+
+	return &resource, nil
+}
+
+func (r *AuthResources) ChangeEmailResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(ChangeEmailRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(ChangeEmailResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Request a change of the current user's email",
+		Description: "Verify the current password and send a confirmation link to the new email address and a revert link to the current email address. The email is only changed once the new address is confirmed",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Email change requested",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.IncorrectPassword, apierror.EmailUnchanged, apierror.EmailDomainNotAllowed, apierror.DisposableEmail),
+		},
+	}
+
+	resource := internal.NewResource("ChangeEmail", doc, r.handlers.ChangeEmail)
+
+	return &resource, nil
+}
+
+func (r *AuthResources) ConfirmEmailChangeResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(ConfirmEmailChangeRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(ConfirmEmailChangeResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Confirm an email change",
+		Description: "Verify the email change confirmation token and update the user's email to the new address",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Email changed",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.InvalidToken, apierror.TokenRevoked, apierror.EmailChangeInvalid, apierror.EmailTaken, apierror.AccountSuspended, apierror.AccountDeleted),
+		},
+	}
+
+	resource := internal.NewResource("ConfirmEmailChange", doc, r.handlers.ConfirmEmailChange)
+
+	return &resource, nil
+}
+
+func (r *AuthResources) RevertEmailChangeResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(RevertEmailChangeRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(RevertEmailChangeResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Revert an email change",
+		Description: "Verify the email change revert token, restore the previous email address, cancel any pending change, and revoke all sessions",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Email change reverted",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.InvalidToken, apierror.TokenRevoked, apierror.AccountSuspended, apierror.AccountDeleted),
+		},
+	}
+
+	resource := internal.NewResource("RevertEmailChange", doc, r.handlers.RevertEmailChange)
+
+	return &resource, nil
+}
+
+func (r *AuthResources) UpdateNotificationPreferencesResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(UpdateNotificationPreferencesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(UpdateNotificationPreferencesResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Update the current user's notification preferences",
+		Description: "Replace the list of security notifications the user has opted out of. Only new_login and two_factor_enabled notifications can be turned off; all other security notifications are always sent",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Notification preferences updated",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.NotificationNotOptional),
 		},
 	}
 
-	resource := openapi.NewResource("ResetPassword", doc, r.handlers.ResetPassword)
+	resource := internal.NewResource("UpdateNotificationPreferences", doc, r.handlers.UpdateNotificationPreferences)
 
 	return &resource, nil
 }
@@ -244,16 +396,16 @@ func (r *AuthResources) OtpSendResource() (*openapi.Resource, error) {
 		Description: "Send an OTP code to the user",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "OTP code sent",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.InvalidRequest, apierror.ValidationFailed),
 		},
 	}
 
-	resource := openapi.NewResource("OtpSend", doc, r.handlers.OtpSend)
+	resource := internal.NewResource("OtpSend", doc, r.handlers.OtpSend)
 
 	return &resource, nil
 }
@@ -274,16 +426,106 @@ func (r *AuthResources) OtpVerifyResource() (*openapi.Resource, error) {
 		Description: "Verify an OTP code",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "OTP code verified",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.OtpInvalid, apierror.OtpExpired, apierror.AccountSuspended, apierror.AccountDeleted),
+		},
+	}
+
+	resource := internal.NewResource("OtpVerify", doc, r.handlers.OtpVerify)
+
+	return &resource, nil
+}
+
+func (r *AuthResources) SwitchOrganizationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(SwitchOrganizationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(SwitchOrganizationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Switch the active organization",
+		Description: "Set the active organization of the current session, and issue new tokens whose org_id and org_role claims reflect it. Leave organization_id empty to clear the active organization",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully switched organization",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.OrganizationNotFound, apierror.HookRejected),
+		},
+	}
+
+	resource := internal.NewResource("SwitchOrganization", doc, r.handlers.SwitchOrganization)
+
+	return &resource, nil
+}
+
+func (r *AuthResources) AcceptInvitationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(AcceptInvitationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(AcceptInvitationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Accept an invitation",
+		Description: "Redeem an invitation token. If the invited email has no account, one is created with the given password and a verified email. If it has an account, the user is added to the organization they were invited to",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully accepted invitation",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.InvalidToken, apierror.EmailTaken, apierror.AlreadyMember, apierror.ApprovalPending, apierror.AccountSuspended, apierror.AccountDeleted, apierror.EmailDomainNotAllowed, apierror.DisposableEmail, apierror.HookRejected),
+		},
+	}
+
+	resource := internal.NewResource("AcceptInvitation", doc, r.handlers.AcceptInvitation)
+
+	return &resource, nil
+}
+
+func (r *AuthResources) ImpersonateResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(ImpersonateRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(ImpersonateResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Impersonate user",
+		Description: "Start a short-lived session as another user, to see what they see. The tokens are returned rather than set as cookies, and carry an act claim whose sub is the caller. Changing the password, email or notification preferences, deleting or exporting the account, and creating API keys are forbidden during impersonation. Requires the impersonator role, and every role of the impersonated user",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{Content: openapi.Json(requestSchema)},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "Successfully started impersonation",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.ImpersonationNotAllowed, apierror.UserNotFound, apierror.AccountStatusConflict, apierror.HookRejected),
 		},
 	}
 
-	resource := openapi.NewResource("OtpVerify", doc, r.handlers.OtpVerify)
+	resource := internal.NewResource("Impersonate", doc, r.handlers.Impersonate)
 
 	return &resource, nil
 }
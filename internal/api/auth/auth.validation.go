@@ -1,36 +1,36 @@
 package auth_features
 
 import (
-	"errors"
-
+	"github.com/abyanmajid/thorfinn/internal/apierror"
 	"github.com/abyanmajid/v"
 )
 
-func validateRegisterPayload(payload RegisterRequest) error {
-	email := v.String("Email").Email().Parse(payload.Email)
-	password := v.String("Password").Min(8).Parse(payload.Password)
+func validateUserIdInterface(userIdInterface interface{}) (string, error) {
+	userId := v.String("UserId").Parse(userIdInterface)
 
-	if !email.Ok {
-		return errors.New("invalid email")
+	if !userId.Ok {
+		return "", apierror.New(apierror.InvalidToken, "token is invalid or has expired")
 	}
 
-	if !password.Ok {
-		return errors.New("password must be at least 8 characters long")
-	}
+	return userId.Value, nil
+}
+
+func validateEmailInterface(emailInterface interface{}) (string, error) {
+	email := v.String("Email").Email().Parse(emailInterface)
 
-	if password.Value != payload.ConfirmPassword {
-		return errors.New("passwords do not match")
+	if !email.Ok {
+		return "", apierror.New(apierror.InvalidToken, "token is invalid or has expired")
 	}
 
-	return nil
+	return email.Value, nil
 }
 
-func validateUserIdInterface(userIdInterface interface{}) (string, error) {
-	userId := v.String("UserId").Parse(userIdInterface)
+func validateChangeIdInterface(changeIdInterface interface{}) (string, error) {
+	changeId := v.String("ChangeId").UUID().Parse(changeIdInterface)
 
-	if !userId.Ok {
-		return "", errors.New("invalid user id")
+	if !changeId.Ok {
+		return "", apierror.New(apierror.InvalidToken, "token is invalid or has expired")
 	}
 
-	return userId.Value, nil
+	return changeId.Value, nil
 }
@@ -0,0 +1,54 @@
+package authz_features
+
+import "github.com/abyanmajid/matcha/openapi"
+
+type DerivedAuthzResources struct {
+	Check       *openapi.Resource
+	CheckBatch  *openapi.Resource
+	Expand      *openapi.Resource
+	GetTuples   *openapi.Resource
+	CreateTuple *openapi.Resource
+	DeleteTuple *openapi.Resource
+}
+
+func Derive(handlers *AuthzHandlers) (*DerivedAuthzResources, error) {
+	authzResources := NewAuthzResources(handlers)
+	checkResource, err := authzResources.CheckResource()
+	if err != nil {
+		return nil, err
+	}
+
+	checkBatchResource, err := authzResources.CheckBatchResource()
+	if err != nil {
+		return nil, err
+	}
+
+	expandResource, err := authzResources.ExpandResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getTuplesResource, err := authzResources.GetTuplesResource()
+	if err != nil {
+		return nil, err
+	}
+
+	createTupleResource, err := authzResources.CreateTupleResource()
+	if err != nil {
+		return nil, err
+	}
+
+	deleteTupleResource, err := authzResources.DeleteTupleResource()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedAuthzResources{
+		Check:       checkResource,
+		CheckBatch:  checkBatchResource,
+		Expand:      expandResource,
+		GetTuples:   getTuplesResource,
+		CreateTuple: createTupleResource,
+		DeleteTuple: deleteTupleResource,
+	}, nil
+}
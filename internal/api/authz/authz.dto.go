@@ -0,0 +1,87 @@
+package authz_features
+
+import (
+	"github.com/abyanmajid/thorfinn/internal/authz"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Check asks whether subject (type:id, or type:id#relation for a userset) has
+// relation on object (type:id).
+type Check struct {
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	Subject  string `json:"subject"`
+}
+
+type CheckResult struct {
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	Subject  string `json:"subject"`
+	Allowed  bool   `json:"allowed"`
+}
+
+// RelationTuple grants subject relation on object.
+type RelationTuple struct {
+	Object    string             `json:"object"`
+	Relation  string             `json:"relation"`
+	Subject   string             `json:"subject"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type CheckRequest struct {
+	Object   string `json:"object" validate:"required,max=255"`
+	Relation string `json:"relation" validate:"required,max=64"`
+	Subject  string `json:"subject" validate:"required,max=255"`
+}
+
+type CheckResponse struct {
+	Message string `json:"message"`
+	Allowed bool   `json:"allowed"`
+}
+
+type CheckBatchRequest struct {
+	Checks []Check `json:"checks" validate:"required,min=1,max=100"`
+}
+
+type CheckBatchResponse struct {
+	Message string        `json:"message"`
+	Results []CheckResult `json:"results"`
+}
+
+type ExpandRequest struct {
+	Object   string `json:"object" validate:"required,max=255"`
+	Relation string `json:"relation" validate:"required,max=64"`
+}
+
+type ExpandResponse struct {
+	Message string      `json:"message"`
+	Tree    *authz.Node `json:"tree"`
+}
+
+type GetTuplesRequest struct{}
+
+type GetTuplesResponse struct {
+	Message string          `json:"message"`
+	Tuples  []RelationTuple `json:"tuples"`
+}
+
+type CreateTupleRequest struct {
+	Object   string `json:"object" validate:"required,max=255"`
+	Relation string `json:"relation" validate:"required,max=64"`
+	Subject  string `json:"subject" validate:"required,max=255"`
+}
+
+type CreateTupleResponse struct {
+	Message string        `json:"message"`
+	Tuple   RelationTuple `json:"tuple"`
+}
+
+type DeleteTupleRequest struct {
+	Object   string `json:"object" validate:"required,max=255"`
+	Relation string `json:"relation" validate:"required,max=64"`
+	Subject  string `json:"subject" validate:"required,max=255"`
+}
+
+type DeleteTupleResponse struct {
+	Message string `json:"message"`
+}
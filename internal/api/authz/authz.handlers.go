@@ -0,0 +1,277 @@
+package authz_features
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/matcha/email"
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/audit"
+	"github.com/abyanmajid/thorfinn/internal/authz"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/session"
+)
+
+type AuthzHandlers struct {
+	isDev         bool
+	config        *internal.EnvConfig
+	queries       *database.Queries
+	mailer        *email.Client
+	authenticator *session.Authenticator
+	checker       *authz.Checker
+	auditor       *audit.Auditor
+}
+
+func NewHandlers(isDev bool, config *internal.EnvConfig, queries *database.Queries, mailer *email.Client) *AuthzHandlers {
+	return &AuthzHandlers{
+		isDev:         isDev,
+		config:        config,
+		queries:       queries,
+		mailer:        mailer,
+		authenticator: session.NewAuthenticator(config, queries),
+		checker:       authz.NewChecker(config, queries),
+		auditor:       audit.NewAuditor(config, queries),
+	}
+}
+
+func (h *AuthzHandlers) Check(c *ctx.Request[CheckRequest]) *ctx.Response[CheckResponse] {
+	logger.Info("Invoked: Check")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin, session.RoleAuthzReader)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CheckResponse](err)
+	}
+
+	fields := map[string][]string{}
+	object, subject := parseCheck(c.Body.Object, c.Body.Relation, c.Body.Subject, "", fields)
+	if len(fields) > 0 {
+		logger.Error("Invalid check")
+		return internal.ApiError[CheckResponse](apierror.Validation(fields))
+	}
+
+	logger.Debug("Checking relation")
+	allowed, err := h.checker.Check(c.Request.Context(), object, c.Body.Relation, subject)
+	if err != nil {
+		logger.Error("Error checking relation: %v", err)
+		return internal.GenericError[CheckResponse]()
+	}
+
+	return &ctx.Response[CheckResponse]{
+		Response: CheckResponse{
+			Message: "Successfully checked relation",
+			Allowed: allowed,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthzHandlers) CheckBatch(c *ctx.Request[CheckBatchRequest]) *ctx.Response[CheckBatchResponse] {
+	logger.Info("Invoked: CheckBatch")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin, session.RoleAuthzReader)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CheckBatchResponse](err)
+	}
+
+	fields := map[string][]string{}
+	objects := make([]authz.Object, len(c.Body.Checks))
+	subjects := make([]authz.Subject, len(c.Body.Checks))
+	for i, check := range c.Body.Checks {
+		objects[i], subjects[i] = parseCheck(check.Object, check.Relation, check.Subject, fmt.Sprintf("checks[%d].", i), fields)
+	}
+	if len(fields) > 0 {
+		logger.Error("Invalid checks")
+		return internal.ApiError[CheckBatchResponse](apierror.Validation(fields))
+	}
+
+	logger.Debug("Checking relations")
+	results := make([]CheckResult, 0, len(c.Body.Checks))
+	for i, check := range c.Body.Checks {
+		allowed, err := h.checker.Check(c.Request.Context(), objects[i], check.Relation, subjects[i])
+		if err != nil {
+			logger.Error("Error checking relation: %v", err)
+			return internal.GenericError[CheckBatchResponse]()
+		}
+
+		results = append(results, CheckResult{
+			Object:   check.Object,
+			Relation: check.Relation,
+			Subject:  check.Subject,
+			Allowed:  allowed,
+		})
+	}
+
+	return &ctx.Response[CheckBatchResponse]{
+		Response: CheckBatchResponse{
+			Message: "Successfully checked relations",
+			Results: results,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthzHandlers) Expand(c *ctx.Request[ExpandRequest]) *ctx.Response[ExpandResponse] {
+	logger.Info("Invoked: Expand")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin, session.RoleAuthzReader)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[ExpandResponse](err)
+	}
+
+	fields := map[string][]string{}
+	object, ok := authz.ParseObject(c.Body.Object)
+	if !ok {
+		fields["object"] = []string{objectFormat}
+	}
+	if !authz.ValidRelation(c.Body.Relation) {
+		fields["relation"] = []string{relationRule}
+	}
+	if len(fields) > 0 {
+		logger.Error("Invalid expansion")
+		return internal.ApiError[ExpandResponse](apierror.Validation(fields))
+	}
+
+	logger.Debug("Expanding relation")
+	tree, err := h.checker.Expand(c.Request.Context(), object, c.Body.Relation)
+	if err != nil {
+		logger.Error("Error expanding relation: %v", err)
+		return internal.GenericError[ExpandResponse]()
+	}
+
+	return &ctx.Response[ExpandResponse]{
+		Response: ExpandResponse{
+			Message: "Successfully expanded relation",
+			Tree:    tree,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthzHandlers) GetTuples(c *ctx.Request[GetTuplesRequest]) *ctx.Response[GetTuplesResponse] {
+	logger.Info("Invoked: GetTuples")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin, session.RoleAuthzReader)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetTuplesResponse](err)
+	}
+
+	object, ok := authz.ParseObject(c.GetQueryParam("object"))
+	if !ok {
+		logger.Error("Invalid object")
+		return internal.ApiError[GetTuplesResponse](apierror.Validation(map[string][]string{"object": {objectFormat}}))
+	}
+
+	logger.Debug("Listing relation tuples")
+	rows, err := h.queries.ListObjectRelationTuples(c.Request.Context(), database.ListObjectRelationTuplesParams{
+		ObjectType: object.Type,
+		ObjectID:   object.ID,
+	})
+	if err != nil {
+		logger.Error("Error listing relation tuples: %v", err)
+		return internal.GenericError[GetTuplesResponse]()
+	}
+
+	tuples := make([]RelationTuple, 0, len(rows))
+	for _, row := range rows {
+		tuples = append(tuples, newRelationTuple(row))
+	}
+
+	return &ctx.Response[GetTuplesResponse]{
+		Response: GetTuplesResponse{
+			Message: "Successfully fetched relation tuples",
+			Tuples:  tuples,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *AuthzHandlers) CreateTuple(c *ctx.Request[CreateTupleRequest]) *ctx.Response[CreateTupleResponse] {
+	logger.Info("Invoked: CreateTuple")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CreateTupleResponse](err)
+	}
+
+	params, err := parseTuple(c.Body.Object, c.Body.Relation, c.Body.Subject)
+	if err != nil {
+		logger.Error("Invalid relation tuple: %v", err)
+		return internal.ApiError[CreateTupleResponse](err)
+	}
+
+	logger.Debug("Creating relation tuple")
+	tuple, err := h.queries.CreateRelationTuple(c.Request.Context(), params)
+	if err != nil {
+		logger.Error("Error creating relation tuple: %v", err)
+		return internal.GenericError[CreateTupleResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionRelationTupleCreated,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"object": c.Body.Object, "relation": c.Body.Relation, "subject": c.Body.Subject},
+	})
+
+	return &ctx.Response[CreateTupleResponse]{
+		Response: CreateTupleResponse{
+			Message: "Successfully created relation tuple",
+			Tuple:   newRelationTuple(tuple),
+		},
+		StatusCode: http.StatusCreated,
+		Error:      nil,
+	}
+}
+
+func (h *AuthzHandlers) DeleteTuple(c *ctx.Request[DeleteTupleRequest]) *ctx.Response[DeleteTupleResponse] {
+	logger.Info("Invoked: DeleteTuple")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[DeleteTupleResponse](err)
+	}
+
+	params, err := parseTuple(c.Body.Object, c.Body.Relation, c.Body.Subject)
+	if err != nil {
+		logger.Error("Invalid relation tuple: %v", err)
+		return internal.ApiError[DeleteTupleResponse](err)
+	}
+
+	logger.Debug("Deleting relation tuple")
+	deleted, err := h.queries.DeleteRelationTuple(c.Request.Context(), database.DeleteRelationTupleParams(params))
+	if err != nil {
+		logger.Error("Error deleting relation tuple: %v", err)
+		return internal.GenericError[DeleteTupleResponse]()
+	}
+
+	if deleted == 0 {
+		logger.Error("Relation tuple not found")
+		return internal.ApiError[DeleteTupleResponse](authz.ErrTupleNotFound)
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionRelationTupleDeleted,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"object": c.Body.Object, "relation": c.Body.Relation, "subject": c.Body.Subject},
+	})
+
+	return &ctx.Response[DeleteTupleResponse]{
+		Response: DeleteTupleResponse{
+			Message: "Successfully deleted relation tuple",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
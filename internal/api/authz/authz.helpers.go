@@ -0,0 +1,68 @@
+package authz_features
+
+import (
+	"fmt"
+
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/authz"
+	"github.com/abyanmajid/thorfinn/internal/database"
+)
+
+const (
+	objectFormat  = "Must be of the form type:id"
+	subjectFormat = "Must be of the form type:id or type:id#relation"
+	relationRule  = "Must only contain lowercase letters, digits and underscores, and not start with a digit"
+)
+
+// parseCheck parses the object, relation and subject of a check, adding the
+// failures to fields under keys starting with prefix.
+func parseCheck(object string, relation string, subject string, prefix string, fields map[string][]string) (authz.Object, authz.Subject) {
+	parsedObject, ok := authz.ParseObject(object)
+	if !ok {
+		fields[prefix+"object"] = []string{objectFormat}
+	}
+
+	if !authz.ValidRelation(relation) {
+		fields[prefix+"relation"] = []string{relationRule}
+	}
+
+	parsedSubject, ok := authz.ParseSubject(subject)
+	if !ok {
+		fields[prefix+"subject"] = []string{subjectFormat}
+	}
+
+	return parsedObject, parsedSubject
+}
+
+// parseTuple parses a tuple to be written, rejecting relations Thorfinn
+// derives by itself.
+func parseTuple(object string, relation string, subject string) (database.CreateRelationTupleParams, error) {
+	fields := map[string][]string{}
+
+	parsedObject, parsedSubject := parseCheck(object, relation, subject, "", fields)
+	if len(fields) == 0 && authz.IsBuiltIn(parsedObject.Type, relation) {
+		fields["relation"] = []string{fmt.Sprintf("Is managed by Thorfinn and can't be written for %s objects", parsedObject.Type)}
+	}
+
+	if len(fields) > 0 {
+		return database.CreateRelationTupleParams{}, apierror.Validation(fields)
+	}
+
+	return database.CreateRelationTupleParams{
+		ObjectType:      parsedObject.Type,
+		ObjectID:        parsedObject.ID,
+		Relation:        relation,
+		SubjectType:     parsedSubject.Type,
+		SubjectID:       parsedSubject.ID,
+		SubjectRelation: parsedSubject.Relation,
+	}, nil
+}
+
+func newRelationTuple(tuple database.ThorfinnRelationTuple) RelationTuple {
+	return RelationTuple{
+		Object:    authz.Object{Type: tuple.ObjectType, ID: tuple.ObjectID}.String(),
+		Relation:  tuple.Relation,
+		Subject:   authz.Subject{Type: tuple.SubjectType, ID: tuple.SubjectID, Relation: tuple.SubjectRelation}.String(),
+		CreatedAt: tuple.CreatedAt,
+	}
+}
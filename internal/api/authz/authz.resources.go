@@ -0,0 +1,214 @@
+package authz_features
+
+import (
+	"net/http"
+
+	"github.com/abyanmajid/matcha/openapi"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+)
+
+type AuthzResources struct {
+	handlers *AuthzHandlers
+}
+
+func NewAuthzResources(handlers *AuthzHandlers) *AuthzResources {
+	return &AuthzResources{
+		handlers: handlers,
+	}
+}
+
+func (r *AuthzResources) CheckResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CheckRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CheckResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Check relation",
+		Description: "Check whether a subject has a relation on an object, from relation tuples, the authorization schema, organization memberships and global roles. Subjects are written type:id, or type:id#relation for a userset, and objects type:id. Users only have relations while their account is active. Requires the admin or authz_reader role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully checked relation",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("Check", doc, r.handlers.Check)
+
+	return &resource, nil
+}
+
+func (r *AuthzResources) CheckBatchResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CheckBatchRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CheckBatchResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Check relations",
+		Description: "Run up to 100 checks at once, returning a result for each in the same order. Requires the admin or authz_reader role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully checked relations",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("CheckBatch", doc, r.handlers.CheckBatch)
+
+	return &resource, nil
+}
+
+func (r *AuthzResources) ExpandResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(ExpandRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(ExpandResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Expand relation",
+		Description: "Get the tree of who has a relation on an object, for debugging: the subjects granted it directly, and the usersets, included relations and inherited relations it is also granted through. Requires the admin or authz_reader role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully expanded relation",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("Expand", doc, r.handlers.Expand)
+
+	return &resource, nil
+}
+
+func (r *AuthzResources) GetTuplesResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetTuplesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetTuplesResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get relation tuples",
+		Description: "Get the relation tuples of an object. Requires the admin or authz_reader role",
+		Schema: openapi.Schema{
+			Parameters: []openapi.Parameter{
+				{In: "query", Name: "object", Description: "The object to get the tuples of, written type:id"},
+			},
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched relation tuples",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("GetTuples", doc, r.handlers.GetTuples)
+
+	return &resource, nil
+}
+
+func (r *AuthzResources) CreateTupleResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CreateTupleRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CreateTupleResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Create relation tuple",
+		Description: "Grant a subject a relation on an object. Creating a tuple that already exists has no effect. Organization owner, admin and member relations and role member relations are managed by Thorfinn and can't be written. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "Successfully created relation tuple",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("CreateTuple", doc, r.handlers.CreateTuple)
+
+	return &resource, nil
+}
+
+func (r *AuthzResources) DeleteTupleResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(DeleteTupleRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(DeleteTupleResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Delete relation tuple",
+		Description: "Revoke a relation granted to a subject by a tuple. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully deleted relation tuple",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.RelationTupleNotFound),
+		},
+	}
+
+	resource := internal.NewResource("DeleteTuple", doc, r.handlers.DeleteTuple)
+
+	return &resource, nil
+}
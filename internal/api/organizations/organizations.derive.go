@@ -0,0 +1,110 @@
+package organizations_features
+
+import "github.com/abyanmajid/matcha/openapi"
+
+type DerivedOrganizationsResources struct {
+	CreateOrganization *openapi.Resource
+	GetOrganizations   *openapi.Resource
+	GetOrganization    *openapi.Resource
+	UpdateOrganization *openapi.Resource
+	DeleteOrganization *openapi.Resource
+	GetMembers         *openapi.Resource
+	UpdateMember       *openapi.Resource
+	RemoveMember       *openapi.Resource
+	CreateInvitation   *openapi.Resource
+	GetInvitations     *openapi.Resource
+	RevokeInvitation   *openapi.Resource
+	CreateApiKey       *openapi.Resource
+	GetApiKeys         *openapi.Resource
+	RevokeApiKey       *openapi.Resource
+}
+
+func Derive(handlers *OrganizationsHandlers) (*DerivedOrganizationsResources, error) {
+	organizationsResources := NewOrganizationsResources(handlers)
+	createOrganizationResource, err := organizationsResources.CreateOrganizationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getOrganizationsResource, err := organizationsResources.GetOrganizationsResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getOrganizationResource, err := organizationsResources.GetOrganizationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	updateOrganizationResource, err := organizationsResources.UpdateOrganizationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	deleteOrganizationResource, err := organizationsResources.DeleteOrganizationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getMembersResource, err := organizationsResources.GetMembersResource()
+	if err != nil {
+		return nil, err
+	}
+
+	updateMemberResource, err := organizationsResources.UpdateMemberResource()
+	if err != nil {
+		return nil, err
+	}
+
+	removeMemberResource, err := organizationsResources.RemoveMemberResource()
+	if err != nil {
+		return nil, err
+	}
+
+	createInvitationResource, err := organizationsResources.CreateInvitationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getInvitationsResource, err := organizationsResources.GetInvitationsResource()
+	if err != nil {
+		return nil, err
+	}
+
+	revokeInvitationResource, err := organizationsResources.RevokeInvitationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	createApiKeyResource, err := organizationsResources.CreateApiKeyResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getApiKeysResource, err := organizationsResources.GetApiKeysResource()
+	if err != nil {
+		return nil, err
+	}
+
+	revokeApiKeyResource, err := organizationsResources.RevokeApiKeyResource()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedOrganizationsResources{
+		CreateOrganization: createOrganizationResource,
+		GetOrganizations:   getOrganizationsResource,
+		GetOrganization:    getOrganizationResource,
+		UpdateOrganization: updateOrganizationResource,
+		DeleteOrganization: deleteOrganizationResource,
+		GetMembers:         getMembersResource,
+		UpdateMember:       updateMemberResource,
+		RemoveMember:       removeMemberResource,
+		CreateInvitation:   createInvitationResource,
+		GetInvitations:     getInvitationsResource,
+		RevokeInvitation:   revokeInvitationResource,
+		CreateApiKey:       createApiKeyResource,
+		GetApiKeys:         getApiKeysResource,
+		RevokeApiKey:       revokeApiKeyResource,
+	}, nil
+}
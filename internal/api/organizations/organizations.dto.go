@@ -0,0 +1,162 @@
+package organizations_features
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Organization is the representation of an organization returned by the API.
+// Role is the caller's role in it, and is empty for global admins who aren't
+// members.
+type Organization struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Slug      string             `json:"slug"`
+	Role      string             `json:"role,omitempty"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Member struct {
+	UserID      string             `json:"user_id"`
+	Email       string             `json:"email"`
+	DisplayName pgtype.Text        `json:"display_name"`
+	Role        string             `json:"role"`
+	JoinedAt    pgtype.Timestamptz `json:"joined_at"`
+}
+
+// Invitation is a pending invitation to join an organization.
+type Invitation struct {
+	ID        string             `json:"id"`
+	Email     string             `json:"email"`
+	Role      string             `json:"role"`
+	InvitedBy pgtype.Text        `json:"invited_by"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// ApiKey is an API key of an organization. It authenticates as the member
+// who created it, only for requests about the organization.
+type ApiKey struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	Scopes     []string           `json:"scopes"`
+	CreatedBy  string             `json:"created_by"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	Slug string `json:"slug" validate:"required,slug,max=63"`
+}
+
+type CreateOrganizationResponse struct {
+	Message      string       `json:"message"`
+	Organization Organization `json:"organization"`
+}
+
+type GetOrganizationsRequest struct{}
+
+type GetOrganizationsResponse struct {
+	Message       string         `json:"message"`
+	Organizations []Organization `json:"organizations"`
+}
+
+type GetOrganizationRequest struct{}
+
+type GetOrganizationResponse struct {
+	Message      string       `json:"message"`
+	Organization Organization `json:"organization"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name *string `json:"name,omitempty" validate:"required,max=100"`
+	Slug *string `json:"slug,omitempty" validate:"required,slug,max=63"`
+}
+
+type UpdateOrganizationResponse struct {
+	Message      string       `json:"message"`
+	Organization Organization `json:"organization"`
+}
+
+type DeleteOrganizationRequest struct{}
+
+type DeleteOrganizationResponse struct {
+	Message string `json:"message"`
+}
+
+type GetMembersRequest struct{}
+
+type GetMembersResponse struct {
+	Message string   `json:"message"`
+	Members []Member `json:"members"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner|admin|member"`
+}
+
+type UpdateMemberResponse struct {
+	Message string `json:"message"`
+	Member  Member `json:"member"`
+}
+
+type RemoveMemberRequest struct{}
+
+type RemoveMemberResponse struct {
+	Message string `json:"message"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=owner|admin|member"`
+}
+
+type CreateInvitationResponse struct {
+	Message    string     `json:"message"`
+	Invitation Invitation `json:"invitation"`
+}
+
+type GetInvitationsRequest struct{}
+
+type GetInvitationsResponse struct {
+	Message     string       `json:"message"`
+	Invitations []Invitation `json:"invitations"`
+}
+
+type RevokeInvitationRequest struct{}
+
+type RevokeInvitationResponse struct {
+	Message string `json:"message"`
+}
+
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateApiKeyResponse carries the only copy of the key. Only its hash is
+// stored, so it can't be shown again.
+type CreateApiKeyResponse struct {
+	Message string `json:"message"`
+	Key     string `json:"key"`
+	ApiKey  ApiKey `json:"api_key"`
+}
+
+type GetApiKeysRequest struct{}
+
+type GetApiKeysResponse struct {
+	Message string   `json:"message"`
+	ApiKeys []ApiKey `json:"api_keys"`
+}
+
+type RevokeApiKeyRequest struct{}
+
+type RevokeApiKeyResponse struct {
+	Message string `json:"message"`
+}
@@ -0,0 +1,719 @@
+package organizations_features
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/matcha/email"
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/apikeys"
+	"github.com/abyanmajid/thorfinn/internal/audit"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/invitations"
+	"github.com/abyanmajid/thorfinn/internal/organizations"
+	"github.com/abyanmajid/thorfinn/internal/session"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type OrganizationsHandlers struct {
+	isDev         bool
+	config        *internal.EnvConfig
+	queries       *database.Queries
+	mailer        *email.Client
+	authenticator *session.Authenticator
+	inviter       *invitations.Inviter
+	auditor       *audit.Auditor
+}
+
+func NewHandlers(isDev bool, config *internal.EnvConfig, queries *database.Queries, mailer *email.Client) *OrganizationsHandlers {
+	return &OrganizationsHandlers{
+		isDev:         isDev,
+		config:        config,
+		queries:       queries,
+		mailer:        mailer,
+		authenticator: session.NewAuthenticator(config, queries),
+		inviter:       invitations.NewInviter(config, queries, mailer),
+		auditor:       audit.NewAuditor(config, queries),
+	}
+}
+
+func (h *OrganizationsHandlers) CreateOrganization(c *ctx.Request[CreateOrganizationRequest]) *ctx.Response[CreateOrganizationResponse] {
+	logger.Info("Invoked: CreateOrganization")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[CreateOrganizationResponse](err)
+	}
+
+	logger.Debug("Finding organization by slug")
+	_, err = h.queries.FindOrganizationBySlug(c.Request.Context(), c.Body.Slug)
+	if err == nil {
+		logger.Error("Slug is already in use")
+		return internal.CustomError[CreateOrganizationResponse](apierror.SlugTaken, "this slug is already in use")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Error finding organization by slug: %v", err)
+		return internal.GenericError[CreateOrganizationResponse]()
+	}
+
+	logger.Debug("Creating organization")
+	organization, err := h.queries.CreateOrganization(c.Request.Context(), database.CreateOrganizationParams{
+		ID:      uuid.New().String(),
+		Name:    c.Body.Name,
+		Slug:    c.Body.Slug,
+		OwnerID: principal.User.ID,
+	})
+	if err != nil {
+		logger.Error("Error creating organization: %v", err)
+		return internal.GenericError[CreateOrganizationResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionOrganizationCreated,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"organization_id": organization.ID, "slug": organization.Slug},
+	})
+
+	return &ctx.Response[CreateOrganizationResponse]{
+		Response: CreateOrganizationResponse{
+			Message:      "Successfully created organization",
+			Organization: newOrganization(organization, organizations.RoleOwner),
+		},
+		StatusCode: http.StatusCreated,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) GetOrganizations(c *ctx.Request[GetOrganizationsRequest]) *ctx.Response[GetOrganizationsResponse] {
+	logger.Info("Invoked: GetOrganizations")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[GetOrganizationsResponse](err)
+	}
+
+	logger.Debug("Fetching organizations")
+	rows, err := h.queries.ListUserOrganizations(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		logger.Error("Error getting organizations: %v", err)
+		return internal.GenericError[GetOrganizationsResponse]()
+	}
+
+	result := []Organization{}
+	for _, row := range rows {
+		result = append(result, Organization{
+			ID:        row.ID,
+			Name:      row.Name,
+			Slug:      row.Slug,
+			Role:      row.Role,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		})
+	}
+
+	return &ctx.Response[GetOrganizationsResponse]{
+		Response: GetOrganizationsResponse{
+			Message:       "Successfully fetched organizations",
+			Organizations: result,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) GetOrganization(c *ctx.Request[GetOrganizationRequest]) *ctx.Response[GetOrganizationResponse] {
+	logger.Info("Invoked: GetOrganization")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleMember)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetOrganizationResponse](err)
+	}
+
+	return &ctx.Response[GetOrganizationResponse]{
+		Response: GetOrganizationResponse{
+			Message:      "Successfully fetched organization",
+			Organization: newOrganization(access.organization, access.memberRole),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) UpdateOrganization(c *ctx.Request[UpdateOrganizationRequest]) *ctx.Response[UpdateOrganizationResponse] {
+	logger.Info("Invoked: UpdateOrganization")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[UpdateOrganizationResponse](err)
+	}
+
+	name := access.organization.Name
+	if c.Body.Name != nil {
+		name = *c.Body.Name
+	}
+
+	slug := access.organization.Slug
+	if c.Body.Slug != nil && *c.Body.Slug != slug {
+		logger.Debug("Finding organization by slug")
+		_, err = h.queries.FindOrganizationBySlug(c.Request.Context(), *c.Body.Slug)
+		if err == nil {
+			logger.Error("Slug is already in use")
+			return internal.CustomError[UpdateOrganizationResponse](apierror.SlugTaken, "this slug is already in use")
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error("Error finding organization by slug: %v", err)
+			return internal.GenericError[UpdateOrganizationResponse]()
+		}
+
+		slug = *c.Body.Slug
+	}
+
+	logger.Debug("Updating organization")
+	organization, err := h.queries.UpdateOrganization(c.Request.Context(), database.UpdateOrganizationParams{
+		ID:   access.organization.ID,
+		Name: name,
+		Slug: slug,
+	})
+	if err != nil {
+		logger.Error("Error updating organization: %v", err)
+		return internal.GenericError[UpdateOrganizationResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionOrganizationUpdated,
+		Actor:    access.principal.Actor(),
+		Metadata: map[string]any{"organization_id": organization.ID, "name": organization.Name, "slug": organization.Slug},
+	})
+
+	return &ctx.Response[UpdateOrganizationResponse]{
+		Response: UpdateOrganizationResponse{
+			Message:      "Successfully updated organization",
+			Organization: newOrganization(organization, access.memberRole),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) DeleteOrganization(c *ctx.Request[DeleteOrganizationRequest]) *ctx.Response[DeleteOrganizationResponse] {
+	logger.Info("Invoked: DeleteOrganization")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleOwner)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[DeleteOrganizationResponse](err)
+	}
+
+	logger.Debug("Deleting organization")
+	err = h.queries.DeleteOrganization(c.Request.Context(), access.organization.ID)
+	if err != nil {
+		logger.Error("Error deleting organization: %v", err)
+		return internal.GenericError[DeleteOrganizationResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionOrganizationDeleted,
+		Actor:    access.principal.Actor(),
+		Metadata: map[string]any{"organization_id": access.organization.ID, "slug": access.organization.Slug},
+	})
+
+	return &ctx.Response[DeleteOrganizationResponse]{
+		Response: DeleteOrganizationResponse{
+			Message: "Successfully deleted organization",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) GetMembers(c *ctx.Request[GetMembersRequest]) *ctx.Response[GetMembersResponse] {
+	logger.Info("Invoked: GetMembers")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleMember)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetMembersResponse](err)
+	}
+
+	logger.Debug("Fetching members")
+	rows, err := h.queries.ListOrganizationMembers(c.Request.Context(), access.organization.ID)
+	if err != nil {
+		logger.Error("Error getting members: %v", err)
+		return internal.GenericError[GetMembersResponse]()
+	}
+
+	members := []Member{}
+	for _, row := range rows {
+		members = append(members, newMemberFromRow(row))
+	}
+
+	return &ctx.Response[GetMembersResponse]{
+		Response: GetMembersResponse{
+			Message: "Successfully fetched members",
+			Members: members,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) UpdateMember(c *ctx.Request[UpdateMemberRequest]) *ctx.Response[UpdateMemberResponse] {
+	logger.Info("Invoked: UpdateMember")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[UpdateMemberResponse](err)
+	}
+
+	userId := c.GetPathParam("user_id")
+
+	logger.Debug("Finding membership")
+	membership, err := h.queries.FindMembership(c.Request.Context(), database.FindMembershipParams{
+		OrganizationID: access.organization.ID,
+		UserID:         userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Member not found")
+		return internal.CustomError[UpdateMemberResponse](apierror.MemberNotFound, "member not found")
+	}
+	if err != nil {
+		logger.Error("Error finding membership: %v", err)
+		return internal.GenericError[UpdateMemberResponse]()
+	}
+
+	if !canManage(access.role, membership.Role, c.Body.Role) {
+		logger.Error("Only owners can grant or revoke the owner role")
+		return internal.ApiError[UpdateMemberResponse](organizations.ErrForbidden)
+	}
+
+	if c.Body.Role != organizations.RoleOwner {
+		err = h.checkNotLastOwner(c.Request.Context(), membership)
+		if err != nil {
+			logger.Error("Error checking owners: %v", err)
+			return internal.ApiError[UpdateMemberResponse](err)
+		}
+	}
+
+	logger.Debug("Finding user by id")
+	user, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if err != nil {
+		logger.Error("Error finding user by id: %v", err)
+		return internal.GenericError[UpdateMemberResponse]()
+	}
+
+	previousRole := membership.Role
+
+	logger.Debug("Updating membership")
+	membership, err = h.queries.UpdateMembershipRole(c.Request.Context(), database.UpdateMembershipRoleParams{
+		OrganizationID: access.organization.ID,
+		UserID:         userId,
+		Role:           c.Body.Role,
+	})
+	if err != nil {
+		logger.Error("Error updating membership: %v", err)
+		return internal.GenericError[UpdateMemberResponse]()
+	}
+
+	if membership.Role != previousRole {
+		logger.Debug("Revoking the member's sessions in the organization")
+		err = h.queries.RevokeOrganizationSessions(c.Request.Context(), database.RevokeOrganizationSessionsParams{
+			UserID:               userId,
+			ActiveOrganizationID: pgtype.Text{String: access.organization.ID, Valid: true},
+		})
+		if err != nil {
+			logger.Error("Error revoking sessions: %v", err)
+			return internal.GenericError[UpdateMemberResponse]()
+		}
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionMemberUpdated,
+		Actor:        access.principal.Actor(),
+		TargetUserID: userId,
+		Metadata:     map[string]any{"organization_id": access.organization.ID, "previous_role": previousRole, "role": membership.Role},
+	})
+
+	return &ctx.Response[UpdateMemberResponse]{
+		Response: UpdateMemberResponse{
+			Message: "Successfully updated member",
+			Member:  newMember(membership, user),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) RemoveMember(c *ctx.Request[RemoveMemberRequest]) *ctx.Response[RemoveMemberResponse] {
+	logger.Info("Invoked: RemoveMember")
+
+	userId := c.GetPathParam("user_id")
+
+	// Any member can leave an organization, but only admins can remove others.
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleMember)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[RemoveMemberResponse](err)
+	}
+
+	logger.Debug("Finding membership")
+	membership, err := h.queries.FindMembership(c.Request.Context(), database.FindMembershipParams{
+		OrganizationID: access.organization.ID,
+		UserID:         userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Member not found")
+		return internal.CustomError[RemoveMemberResponse](apierror.MemberNotFound, "member not found")
+	}
+	if err != nil {
+		logger.Error("Error finding membership: %v", err)
+		return internal.GenericError[RemoveMemberResponse]()
+	}
+
+	if userId != access.principal.User.ID && !canManage(access.role, membership.Role, organizations.RoleMember) {
+		logger.Error("Caller can't remove this member")
+		return internal.ApiError[RemoveMemberResponse](organizations.ErrForbidden)
+	}
+
+	err = h.checkNotLastOwner(c.Request.Context(), membership)
+	if err != nil {
+		logger.Error("Error checking owners: %v", err)
+		return internal.ApiError[RemoveMemberResponse](err)
+	}
+
+	logger.Debug("Deleting membership")
+	err = h.queries.DeleteMembership(c.Request.Context(), database.DeleteMembershipParams{
+		OrganizationID: access.organization.ID,
+		UserID:         userId,
+	})
+	if err != nil {
+		logger.Error("Error deleting membership: %v", err)
+		return internal.GenericError[RemoveMemberResponse]()
+	}
+
+	logger.Debug("Revoking the member's sessions in the organization")
+	err = h.queries.RevokeOrganizationSessions(c.Request.Context(), database.RevokeOrganizationSessionsParams{
+		UserID:               userId,
+		ActiveOrganizationID: pgtype.Text{String: access.organization.ID, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Error revoking sessions: %v", err)
+		return internal.GenericError[RemoveMemberResponse]()
+	}
+
+	logger.Debug("Revoking the member's API keys for the organization")
+	err = h.queries.RevokeMemberApiKeys(c.Request.Context(), database.RevokeMemberApiKeysParams{
+		OrganizationID: pgtype.Text{String: access.organization.ID, Valid: true},
+		UserID:         userId,
+	})
+	if err != nil {
+		logger.Error("Error revoking API keys: %v", err)
+		return internal.GenericError[RemoveMemberResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionMemberRemoved,
+		Actor:        access.principal.Actor(),
+		TargetUserID: userId,
+		Metadata:     map[string]any{"organization_id": access.organization.ID, "role": membership.Role},
+	})
+
+	return &ctx.Response[RemoveMemberResponse]{
+		Response: RemoveMemberResponse{
+			Message: "Successfully removed member",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) CreateInvitation(c *ctx.Request[CreateInvitationRequest]) *ctx.Response[CreateInvitationResponse] {
+	logger.Info("Invoked: CreateInvitation")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CreateInvitationResponse](err)
+	}
+
+	if !canManage(access.role, organizations.RoleMember, c.Body.Role) {
+		logger.Error("Only owners can invite owners")
+		return internal.ApiError[CreateInvitationResponse](organizations.ErrForbidden)
+	}
+
+	logger.Debug("Finding user by email")
+	user, err := h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Error finding user by email: %v", err)
+		return internal.GenericError[CreateInvitationResponse]()
+	}
+
+	if err == nil {
+		logger.Debug("Finding existing membership")
+		_, err = h.queries.FindMembership(c.Request.Context(), database.FindMembershipParams{
+			OrganizationID: access.organization.ID,
+			UserID:         user.ID,
+		})
+		if err == nil {
+			logger.Error("User is already a member")
+			return internal.CustomError[CreateInvitationResponse](apierror.AlreadyMember, "user is already a member of this organization")
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error("Error finding membership: %v", err)
+			return internal.GenericError[CreateInvitationResponse]()
+		}
+	}
+
+	logger.Debug("Creating invitation")
+	invitation, err := h.inviter.Invite(c.Request.Context(), invitations.Params{
+		Email:        c.Body.Email,
+		Organization: &access.organization,
+		Role:         c.Body.Role,
+		InvitedBy:    access.principal.User,
+	})
+	if err != nil {
+		logger.Error("Error creating invitation: %v", err)
+		return internal.GenericError[CreateInvitationResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionInvitationCreated,
+		Actor:    access.principal.Actor(),
+		Metadata: map[string]any{"organization_id": access.organization.ID, "invitation_id": invitation.ID, "email": invitation.Email, "role": invitation.Role.String},
+	})
+
+	return &ctx.Response[CreateInvitationResponse]{
+		Response: CreateInvitationResponse{
+			Message:    "Successfully sent invitation",
+			Invitation: newInvitation(invitation),
+		},
+		StatusCode: http.StatusCreated,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) GetInvitations(c *ctx.Request[GetInvitationsRequest]) *ctx.Response[GetInvitationsResponse] {
+	logger.Info("Invoked: GetInvitations")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetInvitationsResponse](err)
+	}
+
+	logger.Debug("Listing pending invitations")
+	rows, err := h.queries.ListPendingInvitations(c.Request.Context(), pgtype.Text{String: access.organization.ID, Valid: true})
+	if err != nil {
+		logger.Error("Error listing pending invitations: %v", err)
+		return internal.GenericError[GetInvitationsResponse]()
+	}
+
+	pending := make([]Invitation, 0, len(rows))
+	for _, row := range rows {
+		pending = append(pending, newInvitation(row))
+	}
+
+	return &ctx.Response[GetInvitationsResponse]{
+		Response: GetInvitationsResponse{
+			Message:     "Successfully fetched invitations",
+			Invitations: pending,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) RevokeInvitation(c *ctx.Request[RevokeInvitationRequest]) *ctx.Response[RevokeInvitationResponse] {
+	logger.Info("Invoked: RevokeInvitation")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[RevokeInvitationResponse](err)
+	}
+
+	logger.Debug("Finding invitation by id")
+	invitation, err := h.queries.FindInvitationById(c.Request.Context(), c.GetPathParam("invitation_id"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && invitation.OrganizationID.String != access.organization.ID) {
+		logger.Error("Invitation not found")
+		return internal.ApiError[RevokeInvitationResponse](invitations.ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding invitation by id: %v", err)
+		return internal.GenericError[RevokeInvitationResponse]()
+	}
+
+	if !canManage(access.role, organizations.RoleMember, invitation.Role.String) {
+		logger.Error("Only owners can revoke invitations of owners")
+		return internal.ApiError[RevokeInvitationResponse](organizations.ErrForbidden)
+	}
+
+	logger.Debug("Deleting invitation")
+	err = h.queries.DeleteInvitation(c.Request.Context(), invitation.ID)
+	if err != nil {
+		logger.Error("Error deleting invitation: %v", err)
+		return internal.GenericError[RevokeInvitationResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionInvitationRevoked,
+		Actor:    access.principal.Actor(),
+		Metadata: map[string]any{"organization_id": access.organization.ID, "invitation_id": invitation.ID, "email": invitation.Email},
+	})
+
+	return &ctx.Response[RevokeInvitationResponse]{
+		Response: RevokeInvitationResponse{
+			Message: "Successfully revoked invitation",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) CreateApiKey(c *ctx.Request[CreateApiKeyRequest]) *ctx.Response[CreateApiKeyResponse] {
+	logger.Info("Invoked: CreateApiKey")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CreateApiKeyResponse](err)
+	}
+
+	err = access.principal.ForbidImpersonation()
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CreateApiKeyResponse](err)
+	}
+
+	err = apikeys.Validate(c.Body.Scopes, c.Body.ExpiresAt)
+	if err != nil {
+		logger.Error("Error validating API key: %v", err)
+		return internal.ApiError[CreateApiKeyResponse](err)
+	}
+
+	logger.Debug("Generating API key")
+	key, prefix, hash, err := apikeys.Generate(h.config.ApiKeyPrefix)
+	if err != nil {
+		logger.Error("Error generating API key: %v", err)
+		return internal.GenericError[CreateApiKeyResponse]()
+	}
+
+	expiresAt := pgtype.Timestamptz{}
+	if c.Body.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *c.Body.ExpiresAt, Valid: true}
+	}
+
+	logger.Debug("Creating API key")
+	apiKey, err := h.queries.CreateApiKey(c.Request.Context(), database.CreateApiKeyParams{
+		ID:             uuid.New().String(),
+		UserID:         access.principal.User.ID,
+		OrganizationID: pgtype.Text{String: access.organization.ID, Valid: true},
+		Name:           c.Body.Name,
+		Prefix:         prefix,
+		KeyHash:        hash,
+		Scopes:         c.Body.Scopes,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		logger.Error("Error creating API key: %v", err)
+		return internal.GenericError[CreateApiKeyResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionApiKeyCreated,
+		Actor:    access.principal.Actor(),
+		Metadata: map[string]any{"organization_id": access.organization.ID, "key_id": apiKey.ID, "name": apiKey.Name, "scopes": apiKey.Scopes},
+	})
+
+	return &ctx.Response[CreateApiKeyResponse]{
+		Response: CreateApiKeyResponse{
+			Message: "Successfully created API key",
+			Key:     key,
+			ApiKey:  newApiKey(apiKey),
+		},
+		StatusCode: http.StatusCreated,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) GetApiKeys(c *ctx.Request[GetApiKeysRequest]) *ctx.Response[GetApiKeysResponse] {
+	logger.Info("Invoked: GetApiKeys")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetApiKeysResponse](err)
+	}
+
+	logger.Debug("Listing API keys")
+	rows, err := h.queries.ListOrganizationApiKeys(c.Request.Context(), pgtype.Text{String: access.organization.ID, Valid: true})
+	if err != nil {
+		logger.Error("Error listing API keys: %v", err)
+		return internal.GenericError[GetApiKeysResponse]()
+	}
+
+	keys := make([]ApiKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, newApiKey(row))
+	}
+
+	return &ctx.Response[GetApiKeysResponse]{
+		Response: GetApiKeysResponse{
+			Message: "Successfully fetched API keys",
+			ApiKeys: keys,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *OrganizationsHandlers) RevokeApiKey(c *ctx.Request[RevokeApiKeyRequest]) *ctx.Response[RevokeApiKeyResponse] {
+	logger.Info("Invoked: RevokeApiKey")
+
+	access, err := h.authorize(c.Request, c.GetPathParam("id"), organizations.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[RevokeApiKeyResponse](err)
+	}
+
+	logger.Debug("Finding API key by id")
+	apiKey, err := h.queries.FindApiKeyById(c.Request.Context(), c.GetPathParam("key_id"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && apiKey.OrganizationID.String != access.organization.ID) {
+		logger.Error("API key not found")
+		return internal.ApiError[RevokeApiKeyResponse](apikeys.ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding API key by id: %v", err)
+		return internal.GenericError[RevokeApiKeyResponse]()
+	}
+
+	logger.Debug("Revoking API key")
+	err = h.queries.RevokeApiKey(c.Request.Context(), apiKey.ID)
+	if err != nil {
+		logger.Error("Error revoking API key: %v", err)
+		return internal.GenericError[RevokeApiKeyResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionApiKeyRevoked,
+		Actor:    access.principal.Actor(),
+		Metadata: map[string]any{"organization_id": access.organization.ID, "key_id": apiKey.ID, "name": apiKey.Name},
+	})
+
+	return &ctx.Response[RevokeApiKeyResponse]{
+		Response: RevokeApiKeyResponse{
+			Message: "Successfully revoked API key",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
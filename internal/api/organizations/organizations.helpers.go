@@ -0,0 +1,159 @@
+package organizations_features
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/organizations"
+	"github.com/abyanmajid/thorfinn/internal/session"
+)
+
+// access is what the caller of an organization endpoint is allowed to do in
+// the organization. role is the caller's effective role, and memberRole the
+// role of their membership, which is empty for global admins who aren't
+// members.
+type access struct {
+	principal    *session.Principal
+	organization database.ThorfinnOrganization
+	role         string
+	memberRole   string
+}
+
+// authorize authenticates the request and requires the caller to have at
+// least the min role in the organization. Global admins are treated as owners
+// of every organization. Organizations the caller isn't a member of are
+// reported as not found.
+func (h *OrganizationsHandlers) authorize(r *http.Request, organizationId string, min string) (*access, error) {
+	principal, err := h.authenticator.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, err := h.queries.FindOrganizationById(r.Context(), organizationId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, organizations.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding organization: %v", err)
+	}
+
+	memberRole := ""
+	membership, err := h.queries.FindMembership(r.Context(), database.FindMembershipParams{
+		OrganizationID: organizationId,
+		UserID:         principal.User.ID,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error finding membership: %v", err)
+	}
+	if err == nil {
+		memberRole = membership.Role
+	}
+
+	role := memberRole
+	if principal.HasRole(session.RoleAdmin) {
+		role = organizations.RoleOwner
+	}
+
+	if role == "" {
+		return nil, organizations.ErrNotFound
+	}
+
+	if !organizations.AtLeast(role, min) {
+		return nil, organizations.ErrForbidden
+	}
+
+	return &access{
+		principal:    principal,
+		organization: organization,
+		role:         role,
+		memberRole:   memberRole,
+	}, nil
+}
+
+// canManage reports whether a caller with the given role can change a member
+// from one role to another. Only owners can grant or revoke the owner role.
+func canManage(role string, from string, to string) bool {
+	if from == organizations.RoleOwner || to == organizations.RoleOwner {
+		return role == organizations.RoleOwner
+	}
+
+	return organizations.AtLeast(role, organizations.RoleAdmin)
+}
+
+// checkNotLastOwner returns organizations.ErrLastOwner if the organization
+// would be left without owners once member stops being one.
+func (h *OrganizationsHandlers) checkNotLastOwner(ctx context.Context, member database.ThorfinnMembership) error {
+	if member.Role != organizations.RoleOwner {
+		return nil
+	}
+
+	owners, err := h.queries.CountOrganizationOwners(ctx, member.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("error counting owners: %v", err)
+	}
+
+	if owners <= 1 {
+		return organizations.ErrLastOwner
+	}
+
+	return nil
+}
+
+func newOrganization(organization database.ThorfinnOrganization, role string) Organization {
+	return Organization{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		Slug:      organization.Slug,
+		Role:      role,
+		CreatedAt: organization.CreatedAt,
+		UpdatedAt: organization.UpdatedAt,
+	}
+}
+
+func newMember(membership database.ThorfinnMembership, user database.ThorfinnUser) Member {
+	return Member{
+		UserID:      membership.UserID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Role:        membership.Role,
+		JoinedAt:    membership.CreatedAt,
+	}
+}
+
+func newMemberFromRow(row database.ListOrganizationMembersRow) Member {
+	return Member{
+		UserID:      row.UserID,
+		Email:       row.Email,
+		DisplayName: row.DisplayName,
+		Role:        row.Role,
+		JoinedAt:    row.CreatedAt,
+	}
+}
+
+func newInvitation(invitation database.ThorfinnInvitation) Invitation {
+	return Invitation{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role.String,
+		InvitedBy: invitation.InvitedBy,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
+
+func newApiKey(key database.ThorfinnApiKey) ApiKey {
+	return ApiKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedBy:  key.UserID,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,467 @@
+package organizations_features
+
+import (
+	"net/http"
+
+	"github.com/abyanmajid/matcha/openapi"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+)
+
+type OrganizationsResources struct {
+	handlers *OrganizationsHandlers
+}
+
+func NewOrganizationsResources(handlers *OrganizationsHandlers) *OrganizationsResources {
+	return &OrganizationsResources{
+		handlers: handlers,
+	}
+}
+
+func (r *OrganizationsResources) CreateOrganizationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CreateOrganizationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CreateOrganizationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Create organization",
+		Description: "Create an organization. The caller becomes its owner",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "Successfully created organization",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.SlugTaken),
+		},
+	}
+
+	resource := internal.NewResource("CreateOrganization", doc, r.handlers.CreateOrganization)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) GetOrganizationsResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetOrganizationsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetOrganizationsResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get your organizations",
+		Description: "Get the organizations the caller is a member of, with their role in each",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched organizations",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted),
+		},
+	}
+
+	resource := internal.NewResource("GetOrganizations", doc, r.handlers.GetOrganizations)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) GetOrganizationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetOrganizationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetOrganizationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get organization",
+		Description: "Get an organization the caller is a member of. Global admins can get any organization",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched organization",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.OrganizationNotFound),
+		},
+	}
+
+	resource := internal.NewResource("GetOrganization", doc, r.handlers.GetOrganization)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) UpdateOrganizationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(UpdateOrganizationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(UpdateOrganizationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Update organization",
+		Description: "Update the name or slug of an organization. Requires the admin or owner role in the organization",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully updated organization",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound, apierror.SlugTaken),
+		},
+	}
+
+	resource := internal.NewResource("UpdateOrganization", doc, r.handlers.UpdateOrganization)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) DeleteOrganizationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(DeleteOrganizationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(DeleteOrganizationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Delete organization",
+		Description: "Delete an organization and all of its memberships. Requires the owner role in the organization",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully deleted organization",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound),
+		},
+	}
+
+	resource := internal.NewResource("DeleteOrganization", doc, r.handlers.DeleteOrganization)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) GetMembersResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetMembersRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetMembersResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get members",
+		Description: "Get the members of an organization the caller is a member of",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched members",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.OrganizationNotFound),
+		},
+	}
+
+	resource := internal.NewResource("GetMembers", doc, r.handlers.GetMembers)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) UpdateMemberResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(UpdateMemberRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(UpdateMemberResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Update member",
+		Description: "Change the role of a member. Requires the admin or owner role in the organization, and only owners can grant or revoke the owner role. The last owner can't be demoted",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully updated member",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound, apierror.MemberNotFound, apierror.LastOwner),
+		},
+	}
+
+	resource := internal.NewResource("UpdateMember", doc, r.handlers.UpdateMember)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) RemoveMemberResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(RemoveMemberRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(RemoveMemberResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Remove member",
+		Description: "Remove a member from an organization. Any member can remove themselves; removing others requires the admin or owner role, and only owners can remove owners. The last owner can't be removed",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully removed member",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound, apierror.MemberNotFound, apierror.LastOwner),
+		},
+	}
+
+	resource := internal.NewResource("RemoveMember", doc, r.handlers.RemoveMember)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) CreateInvitationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CreateInvitationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CreateInvitationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Create invitation",
+		Description: "Invite an email address to join an organization with a role. The invitation link expires and can only be used once, and inviting the same email again replaces the pending invitation. Requires the admin or owner role in the organization, and only owners can invite owners",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "Successfully sent invitation",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound, apierror.AlreadyMember),
+		},
+	}
+
+	resource := internal.NewResource("CreateInvitation", doc, r.handlers.CreateInvitation)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) GetInvitationsResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetInvitationsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetInvitationsResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get invitations",
+		Description: "Get the pending invitations of an organization. Requires the admin or owner role in the organization",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched invitations",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound),
+		},
+	}
+
+	resource := internal.NewResource("GetInvitations", doc, r.handlers.GetInvitations)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) RevokeInvitationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(RevokeInvitationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(RevokeInvitationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Revoke invitation",
+		Description: "Revoke a pending invitation to an organization. Requires the admin or owner role in the organization, and only owners can revoke invitations to become an owner",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully revoked invitation",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound, apierror.InvitationNotFound),
+		},
+	}
+
+	resource := internal.NewResource("RevokeInvitation", doc, r.handlers.RevokeInvitation)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) CreateApiKeyResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CreateApiKeyRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CreateApiKeyResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Create API key",
+		Description: "Create an API key for an organization, with scopes and an optional expiry. The key is only returned once. It authenticates as the member who created it, limited to its scopes and to requests about the organization, and is revoked if they leave it. Requires the admin or owner role in the organization",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "Successfully created API key",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound),
+		},
+	}
+
+	resource := internal.NewResource("CreateApiKey", doc, r.handlers.CreateApiKey)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) GetApiKeysResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetApiKeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetApiKeysResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get API keys",
+		Description: "Get the API keys of an organization that haven't been revoked. Requires the admin or owner role in the organization",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched API keys",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound),
+		},
+	}
+
+	resource := internal.NewResource("GetApiKeys", doc, r.handlers.GetApiKeys)
+
+	return &resource, nil
+}
+
+func (r *OrganizationsResources) RevokeApiKeyResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(RevokeApiKeyRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(RevokeApiKeyResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Revoke API key",
+		Description: "Revoke an API key of an organization. Requires the admin or owner role in the organization",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully revoked API key",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.OrganizationNotFound, apierror.ApiKeyNotFound),
+		},
+	}
+
+	resource := internal.NewResource("RevokeApiKey", doc, r.handlers.RevokeApiKey)
+
+	return &resource, nil
+}
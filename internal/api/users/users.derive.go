@@ -6,7 +6,24 @@ type DerivedUsersResources struct {
 	GetAllUsers *openapi.Resource
 	GetUser     *openapi.Resource
 	UpdateUser  *openapi.Resource
+	PatchUser   *openapi.Resource
 	DeleteUser  *openapi.Resource
+	ApproveUser *openapi.Resource
+	SuspendUser *openapi.Resource
+	RestoreUser *openapi.Resource
+	PurgeUser   *openapi.Resource
+	GetMe       *openapi.Resource
+	UpdateMe    *openapi.Resource
+	DeleteMe    *openapi.Resource
+	ExportMe    *openapi.Resource
+
+	CreateInvitation *openapi.Resource
+	GetInvitations   *openapi.Resource
+	RevokeInvitation *openapi.Resource
+
+	CreateApiKey *openapi.Resource
+	GetApiKeys   *openapi.Resource
+	RevokeApiKey *openapi.Resource
 }
 
 func Derive(handlers *UsersHandlers) (*DerivedUsersResources, error) {
@@ -26,15 +43,107 @@ func Derive(handlers *UsersHandlers) (*DerivedUsersResources, error) {
 		return nil, err
 	}
 
+	patchUserResource, err := userResources.PatchUserResource()
+	if err != nil {
+		return nil, err
+	}
+
 	deleteUserResource, err := userResources.DeleteUserResource()
 	if err != nil {
 		return nil, err
 	}
 
+	approveUserResource, err := userResources.ApproveUserResource()
+	if err != nil {
+		return nil, err
+	}
+
+	suspendUserResource, err := userResources.SuspendUserResource()
+	if err != nil {
+		return nil, err
+	}
+
+	restoreUserResource, err := userResources.RestoreUserResource()
+	if err != nil {
+		return nil, err
+	}
+
+	purgeUserResource, err := userResources.PurgeUserResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getMeResource, err := userResources.GetMeResource()
+	if err != nil {
+		return nil, err
+	}
+
+	updateMeResource, err := userResources.UpdateMeResource()
+	if err != nil {
+		return nil, err
+	}
+
+	deleteMeResource, err := userResources.DeleteMeResource()
+	if err != nil {
+		return nil, err
+	}
+
+	exportMeResource, err := userResources.ExportMeResource()
+	if err != nil {
+		return nil, err
+	}
+
+	createInvitationResource, err := userResources.CreateInvitationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getInvitationsResource, err := userResources.GetInvitationsResource()
+	if err != nil {
+		return nil, err
+	}
+
+	revokeInvitationResource, err := userResources.RevokeInvitationResource()
+	if err != nil {
+		return nil, err
+	}
+
+	createApiKeyResource, err := userResources.CreateApiKeyResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getApiKeysResource, err := userResources.GetApiKeysResource()
+	if err != nil {
+		return nil, err
+	}
+
+	revokeApiKeyResource, err := userResources.RevokeApiKeyResource()
+	if err != nil {
+		return nil, err
+	}
+
 	return &DerivedUsersResources{
 		GetAllUsers: getAllUsersResource,
 		GetUser:     getUserResource,
 		UpdateUser:  updateUserResource,
+		PatchUser:   patchUserResource,
 		DeleteUser:  deleteUserResource,
+		ApproveUser: approveUserResource,
+		SuspendUser: suspendUserResource,
+		RestoreUser: restoreUserResource,
+		PurgeUser:   purgeUserResource,
+		GetMe:       getMeResource,
+		UpdateMe:    updateMeResource,
+		DeleteMe:    deleteMeResource,
+		ExportMe:    exportMeResource,
+
+		CreateInvitation: createInvitationResource,
+		GetInvitations:   getInvitationsResource,
+		RevokeInvitation: revokeInvitationResource,
+
+		CreateApiKey: createApiKeyResource,
+		GetApiKeys:   getApiKeysResource,
+		RevokeApiKey: revokeApiKeyResource,
 	}, nil
 }
@@ -1,36 +1,309 @@
 package users_features
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// User is the representation of a user returned by the API. It leaves out
+// the password hash.
+type User struct {
+	ID                  string             `json:"id"`
+	Email               string             `json:"email"`
+	Verified            bool               `json:"verified"`
+	TwoFactorEnabled    bool               `json:"two_factor_enabled"`
+	Roles               []string           `json:"roles"`
+	NotificationOptOuts []string           `json:"notification_opt_outs"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt         pgtype.Timestamptz `json:"last_login_at"`
+	DisplayName         pgtype.Text        `json:"display_name"`
+	GivenName           pgtype.Text        `json:"given_name"`
+	FamilyName          pgtype.Text        `json:"family_name"`
+	AvatarUrl           pgtype.Text        `json:"avatar_url"`
+	Locale              pgtype.Text        `json:"locale"`
+	Timezone            pgtype.Text        `json:"timezone"`
+	UserMetadata        json.RawMessage    `json:"user_metadata"`
+	AppMetadata         json.RawMessage    `json:"app_metadata"`
+	Status              string             `json:"status"`
+	StatusReason        pgtype.Text        `json:"status_reason"`
+	PurgeAfter          pgtype.Timestamptz `json:"purge_after"`
+	DeletedAt           pgtype.Timestamptz `json:"deleted_at"`
+}
+
 type GetAllUsersRequest struct{}
 
 type GetAllUsersResponse struct {
-	Message string                  `json:"message"`
-	Users   []database.ListUsersRow `json:"users"`
+	Message    string                  `json:"message"`
+	Users      []database.ListUsersRow `json:"users"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+	Total      *int64                  `json:"total,omitempty"`
 }
 
 type GetUserRequest struct{}
 
 type GetUserResponse struct {
-	Message string                `json:"message"`
-	User    database.ThorfinnUser `json:"user"`
+	Message string `json:"message"`
+	User    User   `json:"user"`
 }
 
 type UpdateUserRequest struct {
-	Email            *string `json:"email,omitempty"`
-	Password         *string `json:"password,omitempty"`
-	Verified         *bool   `json:"verified,omitempty"`
-	TwoFactorEnabled *bool   `json:"two_factor_enabled,omitempty"`
+	Email            *string        `json:"email,omitempty" validate:"email"`
+	Password         *string        `json:"password,omitempty" validate:"min=8"`
+	Verified         *bool          `json:"verified,omitempty"`
+	TwoFactorEnabled *bool          `json:"two_factor_enabled,omitempty"`
+	Roles            *[]string      `json:"roles,omitempty"`
+	DisplayName      *string        `json:"display_name,omitempty" validate:"max=100"`
+	GivenName        *string        `json:"given_name,omitempty" validate:"max=100"`
+	FamilyName       *string        `json:"family_name,omitempty" validate:"max=100"`
+	AvatarUrl        *string        `json:"avatar_url,omitempty" validate:"omitempty,url,max=2048"`
+	Locale           *string        `json:"locale,omitempty" validate:"omitempty,locale,max=35"`
+	Timezone         *string        `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	UserMetadata     map[string]any `json:"user_metadata,omitempty"`
+	AppMetadata      map[string]any `json:"app_metadata,omitempty"`
 }
 
 type UpdateUserResponse struct {
 	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+// PatchUserRequest is a JSON Merge Patch (RFC 7396) of a user. The fields are
+// decoded like those of UpdateUserRequest, and the raw patch is kept so that
+// null values can remove profile fields and metadata keys.
+type PatchUserRequest struct {
+	Email            *string        `json:"email,omitempty" validate:"email"`
+	Password         *string        `json:"password,omitempty" validate:"min=8"`
+	Verified         *bool          `json:"verified,omitempty"`
+	TwoFactorEnabled *bool          `json:"two_factor_enabled,omitempty"`
+	Roles            *[]string      `json:"roles,omitempty"`
+	DisplayName      *string        `json:"display_name,omitempty" validate:"max=100"`
+	GivenName        *string        `json:"given_name,omitempty" validate:"max=100"`
+	FamilyName       *string        `json:"family_name,omitempty" validate:"max=100"`
+	AvatarUrl        *string        `json:"avatar_url,omitempty" validate:"omitempty,url,max=2048"`
+	Locale           *string        `json:"locale,omitempty" validate:"omitempty,locale,max=35"`
+	Timezone         *string        `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	UserMetadata     map[string]any `json:"user_metadata,omitempty"`
+	AppMetadata      map[string]any `json:"app_metadata,omitempty"`
+
+	patch map[string]any
+}
+
+func (r *PatchUserRequest) UnmarshalJSON(data []byte) error {
+	type fields PatchUserRequest
+	if err := json.Unmarshal(data, (*fields)(r)); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &r.patch)
+}
+
+type PatchUserResponse struct {
+	Message string `json:"message"`
+	User    User   `json:"user"`
 }
 
 type DeleteUserRequest struct{}
 
 type DeleteUserResponse struct {
 	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+type ApproveUserRequest struct{}
+
+type ApproveUserResponse struct {
+	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type SuspendUserResponse struct {
+	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+type RestoreUserRequest struct{}
+
+type RestoreUserResponse struct {
+	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+type PurgeUserRequest struct{}
+
+type PurgeUserResponse struct {
+	Message string `json:"message"`
+}
+
+type GetMeRequest struct{}
+
+type GetMeResponse struct {
+	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+// UpdateMeRequest updates the fields that are set. CurrentPassword is only
+// needed to turn two-factor authentication off.
+type UpdateMeRequest struct {
+	TwoFactorEnabled    *bool          `json:"two_factor_enabled,omitempty"`
+	CurrentPassword     *string        `json:"current_password,omitempty"`
+	NotificationOptOuts *[]string      `json:"notification_opt_outs,omitempty"`
+	DisplayName         *string        `json:"display_name,omitempty" validate:"max=100"`
+	GivenName           *string        `json:"given_name,omitempty" validate:"max=100"`
+	FamilyName          *string        `json:"family_name,omitempty" validate:"max=100"`
+	AvatarUrl           *string        `json:"avatar_url,omitempty" validate:"omitempty,url,max=2048"`
+	Locale              *string        `json:"locale,omitempty" validate:"omitempty,locale,max=35"`
+	Timezone            *string        `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	UserMetadata        map[string]any `json:"user_metadata,omitempty"`
+}
+
+type UpdateMeResponse struct {
+	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
+type DeleteMeRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type DeleteMeResponse struct {
+	Message  string             `json:"message"`
+	DeleteAt pgtype.Timestamptz `json:"delete_at"`
+}
+
+type ExportMeRequest struct{}
+
+type ExportMeResponse struct {
+	Message string     `json:"message"`
+	Export  UserExport `json:"export"`
+}
+
+// UserExport is everything stored about a user, in a machine-readable form.
+// Secrets such as password hashes, tokens and OTP codes are left out.
+type UserExport struct {
+	ExportedAt      time.Time            `json:"exported_at"`
+	Profile         User                 `json:"profile"`
+	Sessions        []ExportedSession    `json:"sessions"`
+	Organizations   []ExportedMembership `json:"organizations"`
+	PasswordChanges []pgtype.Timestamptz `json:"password_changes"`
+	MfaEnrollments  []MfaEnrollment      `json:"mfa_enrollments"`
+	AuditEvents     []ExportedAuditEvent `json:"audit_events"`
+}
+
+// ExportedSession is a session of the user. ImpersonatedBy is set for
+// sessions an impersonator started as the user.
+type ExportedSession struct {
+	ID             string             `json:"id"`
+	IpAddress      string             `json:"ip_address"`
+	UserAgent      string             `json:"user_agent"`
+	ImpersonatedBy pgtype.Text        `json:"impersonated_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt      pgtype.Timestamptz `json:"revoked_at"`
+}
+
+type ExportedMembership struct {
+	OrganizationID string `json:"organization_id"`
+	Name           string `json:"name"`
+	Slug           string `json:"slug"`
+	Role           string `json:"role"`
+}
+
+// ExportedAuditEvent is an event of the audit log the user performed or was
+// the target of.
+type ExportedAuditEvent struct {
+	Action         string             `json:"action"`
+	Outcome        string             `json:"outcome"`
+	ActorID        pgtype.Text        `json:"actor_id"`
+	ImpersonatedBy pgtype.Text        `json:"impersonated_by"`
+	TargetUserID   pgtype.Text        `json:"target_user_id"`
+	IpAddress      string             `json:"ip_address"`
+	UserAgent      string             `json:"user_agent"`
+	Metadata       json.RawMessage    `json:"metadata"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type MfaEnrollment struct {
+	Method      string `json:"method"`
+	Destination string `json:"destination"`
+}
+
+// Invitation is a pending invitation to create an account on the instance.
+// Role is the global role the invitee gets, if any.
+type Invitation struct {
+	ID        string             `json:"id"`
+	Email     string             `json:"email"`
+	Role      string             `json:"role,omitempty"`
+	InvitedBy pgtype.Text        `json:"invited_by"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role,omitempty" validate:"oneof=admin|impersonator|authz_reader"`
+}
+
+type CreateInvitationResponse struct {
+	Message    string     `json:"message"`
+	Invitation Invitation `json:"invitation"`
+}
+
+type GetInvitationsRequest struct{}
+
+type GetInvitationsResponse struct {
+	Message     string       `json:"message"`
+	Invitations []Invitation `json:"invitations"`
+}
+
+type RevokeInvitationRequest struct{}
+
+type RevokeInvitationResponse struct {
+	Message string `json:"message"`
+}
+
+// ApiKey is the representation of an API key returned by the API. Prefix is
+// the start of the key, to help users recognize it.
+type ApiKey struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	Scopes     []string           `json:"scopes"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateApiKeyResponse carries the only copy of the key. Only its hash is
+// stored, so it can't be shown again.
+type CreateApiKeyResponse struct {
+	Message string `json:"message"`
+	Key     string `json:"key"`
+	ApiKey  ApiKey `json:"api_key"`
+}
+
+type GetApiKeysRequest struct{}
+
+type GetApiKeysResponse struct {
+	Message string   `json:"message"`
+	ApiKeys []ApiKey `json:"api_keys"`
+}
+
+type RevokeApiKeyRequest struct{}
+
+type RevokeApiKeyResponse struct {
+	Message string `json:"message"`
 }
@@ -0,0 +1,34 @@
+package users_features
+
+import (
+	"testing"
+
+	"github.com/abyanmajid/thorfinn/internal/validation"
+)
+
+func TestUpdateRequestsRejectEmptyEmailAndPassword(t *testing.T) {
+	empty := ""
+
+	bodies := map[string]any{
+		"UpdateUserRequest": UpdateUserRequest{Email: &empty, Password: &empty},
+		"PatchUserRequest":  PatchUserRequest{Email: &empty, Password: &empty},
+	}
+
+	for name, body := range bodies {
+		fields := validation.Validate(body)
+		for _, field := range []string{"email", "password"} {
+			if len(fields[field]) == 0 {
+				t.Errorf("%s: expected an empty %s to be rejected", name, field)
+			}
+		}
+	}
+}
+
+func TestUpdateRequestsAllowClearingProfileFields(t *testing.T) {
+	empty := ""
+
+	body := UpdateUserRequest{AvatarUrl: &empty, Locale: &empty, Timezone: &empty, DisplayName: &empty}
+	if fields := validation.Validate(body); len(fields) > 0 {
+		t.Errorf("expected empty profile fields to be accepted, got %v", fields)
+	}
+}
@@ -1,45 +1,124 @@
 package users_features
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/abyanmajid/matcha/ctx"
 	"github.com/abyanmajid/matcha/email"
 	"github.com/abyanmajid/matcha/logger"
 	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/apikeys"
+	"github.com/abyanmajid/thorfinn/internal/audit"
 	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/invitations"
+	"github.com/abyanmajid/thorfinn/internal/lifecycle"
+	"github.com/abyanmajid/thorfinn/internal/metadata"
+	"github.com/abyanmajid/thorfinn/internal/notifications"
+	"github.com/abyanmajid/thorfinn/internal/password"
+	"github.com/abyanmajid/thorfinn/internal/registration"
+	"github.com/abyanmajid/thorfinn/internal/session"
+	"github.com/abyanmajid/thorfinn/internal/webhooks"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type UsersHandlers struct {
-	isDev   bool
-	config  *internal.EnvConfig
-	queries *database.Queries
-	mailer  *email.Client
+	isDev           bool
+	config          *internal.EnvConfig
+	queries         *database.Queries
+	mailer          *email.Client
+	passwordHasher  *password.Hasher
+	passwordHistory *password.History
+	notifier        *notifications.Notifier
+	authenticator   *session.Authenticator
+	metadata        *metadata.Validator
+	inviter         *invitations.Inviter
+	registration    *registration.Policy
+	auditor         *audit.Auditor
+	dispatcher      *webhooks.Dispatcher
 }
 
 func NewHandlers(isDev bool, config *internal.EnvConfig, queries *database.Queries, mailer *email.Client) *UsersHandlers {
+	passwordHasher := password.NewHasher(config)
+
 	return &UsersHandlers{
-		isDev:   isDev,
-		config:  config,
-		queries: queries,
-		mailer:  mailer,
+		isDev:           isDev,
+		config:          config,
+		queries:         queries,
+		mailer:          mailer,
+		passwordHasher:  passwordHasher,
+		passwordHistory: password.NewHistory(config, queries, passwordHasher),
+		notifier:        notifications.NewNotifier(config, mailer),
+		metadata:        metadata.NewValidator(config),
+		authenticator:   session.NewAuthenticator(config, queries),
+		inviter:         invitations.NewInviter(config, queries, mailer),
+		registration:    registration.NewPolicy(config),
+		auditor:         audit.NewAuditor(config, queries),
+		dispatcher:      webhooks.NewDispatcher(config, queries),
 	}
 }
 
 func (h *UsersHandlers) GetAllUsers(c *ctx.Request[GetAllUsersRequest]) *ctx.Response[GetAllUsersResponse] {
 	logger.Info("Invoked: GetAllUsers")
 
-	logger.Debug("Fetching all users")
-	users, err := h.queries.ListUsers(c.Request.Context())
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetAllUsersResponse](err)
+	}
+
+	logger.Debug("Parsing query parameters")
+	query, err := parseListUsersQuery(c)
 	if err != nil {
-		logger.Error("Error getting all users: %v", err)
+		logger.Error("Error parsing query parameters: %v", err)
+		return internal.ApiError[GetAllUsersResponse](err)
+	}
+
+	logger.Debug("Fetching users")
+	users, err := h.queries.ListUsers(c.Request.Context(), query.params)
+	if err != nil {
+		logger.Error("Error getting users: %v", err)
 		return internal.GenericError[GetAllUsersResponse]()
 	}
 
+	// One more user than requested is fetched to know whether there is a next page.
+	nextCursor := ""
+	if len(users) == int(query.params.Limit) {
+		users = users[:len(users)-1]
+
+		nextCursor, err = encodeUsersCursor(newUsersCursor(users[len(users)-1], query.params.SortBy, query.params.Descending))
+		if err != nil {
+			logger.Error("Error encoding cursor: %v", err)
+			return internal.GenericError[GetAllUsersResponse]()
+		}
+	}
+
+	if users == nil {
+		users = []database.ListUsersRow{}
+	}
+
+	var total *int64
+	if query.includeTotal {
+		logger.Debug("Counting users")
+		count, err := h.queries.CountUsers(c.Request.Context(), query.countParams())
+		if err != nil {
+			logger.Error("Error counting users: %v", err)
+			return internal.GenericError[GetAllUsersResponse]()
+		}
+		total = &count
+	}
+
 	return &ctx.Response[GetAllUsersResponse]{
 		Response: GetAllUsersResponse{
-			Message: "Successfully fetched all users",
-			Users:   users,
+			Message:    "Successfully fetched users",
+			Users:      users,
+			NextCursor: nextCursor,
+			Total:      total,
 		},
 		StatusCode: http.StatusOK,
 		Error:      nil,
@@ -49,19 +128,31 @@ func (h *UsersHandlers) GetAllUsers(c *ctx.Request[GetAllUsersRequest]) *ctx.Res
 func (h *UsersHandlers) GetUser(c *ctx.Request[GetUserRequest]) *ctx.Response[GetUserResponse] {
 	logger.Info("Invoked: GetUser")
 
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetUserResponse](err)
+	}
+
 	userId := c.GetPathParam("id")
 
 	logger.Debug("Fetching user by id")
 	user, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[GetUserResponse](apierror.UserNotFound, "user not found")
+	}
 	if err != nil {
 		logger.Error("Error getting user: %v", err)
 		return internal.GenericError[GetUserResponse]()
 	}
 
+	c.Response.Header().Set("ETag", userETag(user))
+
 	return &ctx.Response[GetUserResponse]{
 		Response: GetUserResponse{
 			Message: "Successfully fetched user",
-			User:    user,
+			User:    newUser(user),
 		},
 		StatusCode: http.StatusOK,
 		Error:      nil,
@@ -71,71 +162,1078 @@ func (h *UsersHandlers) GetUser(c *ctx.Request[GetUserRequest]) *ctx.Response[Ge
 func (h *UsersHandlers) UpdateUser(c *ctx.Request[UpdateUserRequest]) *ctx.Response[UpdateUserResponse] {
 	logger.Info("Invoked: UpdateUser")
 
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[UpdateUserResponse](err)
+	}
+
 	userId := c.GetPathParam("id")
 
 	logger.Debug("Fetching existing user details")
 	existingUser, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[UpdateUserResponse](apierror.UserNotFound, "user not found")
+	}
 	if err != nil {
 		logger.Error("Error getting user: %v", err)
 		return internal.GenericError[UpdateUserResponse]()
 	}
 
+	logger.Debug("Checking If-Match header")
+	err = checkIfMatch(c.GetHeader("If-Match"), existingUser)
+	if err != nil {
+		logger.Error("Precondition failed: %v", err)
+		return internal.ApiError[UpdateUserResponse](err)
+	}
+
+	logger.Debug("Updating user")
+	updatedUser, err := h.updateUser(c.Request.Context(), existingUser, c.Body)
+	if err != nil {
+		logger.Error("Error updating user: %v", err)
+		return internal.ApiError[UpdateUserResponse](err)
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserUpdated,
+		Actor:        principal.Actor(),
+		TargetUserID: updatedUser.ID,
+		Metadata:     userChanges(existingUser, updatedUser),
+	})
+
+	c.Response.Header().Set("ETag", userETag(updatedUser))
+
+	return &ctx.Response[UpdateUserResponse]{
+		Response: UpdateUserResponse{
+			Message: "Successfully updated user",
+			User:    newUser(updatedUser),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) PatchUser(c *ctx.Request[PatchUserRequest]) *ctx.Response[PatchUserResponse] {
+	logger.Info("Invoked: PatchUser")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[PatchUserResponse](err)
+	}
+
+	userId := c.GetPathParam("id")
+
+	logger.Debug("Fetching existing user details")
+	existingUser, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[PatchUserResponse](apierror.UserNotFound, "user not found")
+	}
+	if err != nil {
+		logger.Error("Error getting user: %v", err)
+		return internal.GenericError[PatchUserResponse]()
+	}
+
+	logger.Debug("Checking If-Match header")
+	err = checkIfMatch(c.GetHeader("If-Match"), existingUser)
+	if err != nil {
+		logger.Error("Precondition failed: %v", err)
+		return internal.ApiError[PatchUserResponse](err)
+	}
+
+	logger.Debug("Applying merge patch")
+	body, err := applyUserPatch(existingUser, c.Body.patch)
+	if err != nil {
+		logger.Error("Error applying merge patch: %v", err)
+		return internal.ApiError[PatchUserResponse](err)
+	}
+
+	logger.Debug("Updating user")
+	updatedUser, err := h.updateUser(c.Request.Context(), existingUser, body)
+	if err != nil {
+		logger.Error("Error updating user: %v", err)
+		return internal.ApiError[PatchUserResponse](err)
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserUpdated,
+		Actor:        principal.Actor(),
+		TargetUserID: updatedUser.ID,
+		Metadata:     userChanges(existingUser, updatedUser),
+	})
+
+	c.Response.Header().Set("ETag", userETag(updatedUser))
+
+	return &ctx.Response[PatchUserResponse]{
+		Response: PatchUserResponse{
+			Message: "Successfully updated user",
+			User:    newUser(updatedUser),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+// updateUser applies an admin update to existingUser. The update only succeeds
+// if the user hasn't been modified since existingUser was read, and fails with
+// errUserModified otherwise.
+func (h *UsersHandlers) updateUser(ctx context.Context, existingUser database.ThorfinnUser, body UpdateUserRequest) (database.ThorfinnUser, error) {
+	var err error
+
+	if existingUser.Status == lifecycle.StatusDeleted {
+		return database.ThorfinnUser{}, errUserDeleted
+	}
+
 	email := existingUser.Email
-	if c.Body.Email != nil {
-		email = *c.Body.Email
+	if body.Email != nil && *body.Email != existingUser.Email {
+		logger.Debug("Finding user by new email")
+		_, err = h.queries.FindUserByEmail(ctx, *body.Email)
+		if err == nil {
+			return database.ThorfinnUser{}, errEmailTaken
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.ThorfinnUser{}, fmt.Errorf("error finding user by email: %v", err)
+		}
+
+		email = *body.Email
 	}
 
-	password := existingUser.PasswordHash
-	if c.Body.Password != nil {
-		password = *c.Body.Password
+	passwordHash := existingUser.PasswordHash
+	if body.Password != nil {
+		logger.Debug("Checking password history")
+		err = h.passwordHistory.Check(ctx, existingUser.ID, *body.Password)
+		if err != nil {
+			return database.ThorfinnUser{}, err
+		}
+
+		logger.Debug("Hashing password")
+		passwordHash, err = h.passwordHasher.Hash(*body.Password)
+		if err != nil {
+			return database.ThorfinnUser{}, fmt.Errorf("error hashing password: %v", err)
+		}
 	}
 
-	verified := existingUser.Verified
-	if c.Body.Verified != nil {
-		verified = *c.Body.Verified
+	// A new email is unverified, unless the admin says otherwise.
+	verified := existingUser.Verified && email == existingUser.Email
+	if body.Verified != nil {
+		verified = *body.Verified
 	}
 
 	twoFactorEnabled := existingUser.TwoFactorEnabled
-	if c.Body.TwoFactorEnabled != nil {
-		twoFactorEnabled = *c.Body.TwoFactorEnabled
+	if body.TwoFactorEnabled != nil {
+		twoFactorEnabled = *body.TwoFactorEnabled
+	}
+
+	roles := existingUser.Roles
+	if body.Roles != nil {
+		roles = *body.Roles
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+
+	userMetadata := existingUser.UserMetadata
+	if body.UserMetadata != nil {
+		logger.Debug("Validating user metadata")
+		userMetadata, err = h.metadata.ValidateUserMetadata(body.UserMetadata)
+		if err != nil {
+			return database.ThorfinnUser{}, err
+		}
+	}
+
+	appMetadata := existingUser.AppMetadata
+	if body.AppMetadata != nil {
+		logger.Debug("Validating app metadata")
+		appMetadata, err = h.metadata.ValidateAppMetadata(body.AppMetadata)
+		if err != nil {
+			return database.ThorfinnUser{}, err
+		}
 	}
 
 	logger.Debug("Updating user")
-	_, err = h.queries.UpdateUser(c.Request.Context(), database.UpdateUserParams{
-		ID:               userId,
+	updatedUser, err := h.queries.UpdateUser(ctx, database.UpdateUserParams{
+		ID:               existingUser.ID,
 		Email:            email,
-		PasswordHash:     password,
+		PasswordHash:     passwordHash,
 		Verified:         verified,
 		TwoFactorEnabled: twoFactorEnabled,
+		Roles:            roles,
+		DisplayName:      updateText(existingUser.DisplayName, body.DisplayName),
+		GivenName:        updateText(existingUser.GivenName, body.GivenName),
+		FamilyName:       updateText(existingUser.FamilyName, body.FamilyName),
+		AvatarUrl:        updateText(existingUser.AvatarUrl, body.AvatarUrl),
+		Locale:           updateText(existingUser.Locale, body.Locale),
+		Timezone:         updateText(existingUser.Timezone, body.Timezone),
+		UserMetadata:     userMetadata,
+		AppMetadata:      appMetadata,
+		Version:          existingUser.Version,
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.ThorfinnUser{}, errUserModified
+	}
 	if err != nil {
-		logger.Error("Error updating user: %v", err)
-		return internal.GenericError[UpdateUserResponse]()
+		return database.ThorfinnUser{}, fmt.Errorf("error updating user: %v", err)
 	}
 
-	return &ctx.Response[UpdateUserResponse]{
-		Response: UpdateUserResponse{
-			Message: "Successfully updated user",
-		},
-		StatusCode: http.StatusOK,
-		Error:      nil,
+	if body.Password != nil {
+		logger.Debug("Recording password history")
+		err = h.passwordHistory.Record(ctx, existingUser.ID, passwordHash)
+		if err != nil {
+			return database.ThorfinnUser{}, fmt.Errorf("error recording password history: %v", err)
+		}
+
+		logger.Debug("Revoking all sessions")
+		err = h.queries.RevokeUserSessions(ctx, existingUser.ID)
+		if err != nil {
+			return database.ThorfinnUser{}, fmt.Errorf("error revoking sessions: %v", err)
+		}
+
+		h.notifier.Notify(updatedUser, notifications.KindPasswordChanged, notifications.Details{})
 	}
+
+	if updatedUser.Email != existingUser.Email {
+		h.notifier.Notify(existingUser, notifications.KindEmailChanged, notifications.Details{
+			NewEmail: updatedUser.Email,
+		})
+		h.dispatcher.Publish(ctx, webhooks.EventUserEmailChanged, updatedUser, map[string]any{"old_email": existingUser.Email})
+	}
+
+	if updatedUser.Verified && !existingUser.Verified {
+		h.dispatcher.Publish(ctx, webhooks.EventUserEmailVerified, updatedUser, nil)
+	}
+
+	if updatedUser.TwoFactorEnabled && !existingUser.TwoFactorEnabled {
+		h.notifier.Notify(updatedUser, notifications.KindTwoFactorEnabled, notifications.Details{})
+		h.dispatcher.Publish(ctx, webhooks.EventUserTwoFactorEnabled, updatedUser, nil)
+	}
+
+	if !updatedUser.TwoFactorEnabled && existingUser.TwoFactorEnabled {
+		h.notifier.Notify(updatedUser, notifications.KindTwoFactorDisabled, notifications.Details{})
+		h.dispatcher.Publish(ctx, webhooks.EventUserTwoFactorDisabled, updatedUser, nil)
+	}
+
+	return updatedUser, nil
 }
 
 func (h *UsersHandlers) DeleteUser(c *ctx.Request[DeleteUserRequest]) *ctx.Response[DeleteUserResponse] {
 	logger.Info("Invoked: DeleteUser")
 
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[DeleteUserResponse](err)
+	}
+
 	userId := c.GetPathParam("id")
 
-	logger.Debug("Deleting user")
-	err := h.queries.DeleteUser(c.Request.Context(), userId)
+	logger.Debug("Fetching existing user details")
+	existingUser, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[DeleteUserResponse](apierror.UserNotFound, "user not found")
+	}
+	if err != nil {
+		logger.Error("Error getting user: %v", err)
+		return internal.GenericError[DeleteUserResponse]()
+	}
+
+	if existingUser.Status == lifecycle.StatusPendingDeletion || existingUser.Status == lifecycle.StatusDeleted {
+		logger.Error("User is already %s", existingUser.Status)
+		return internal.CustomError[DeleteUserResponse](apierror.AccountStatusConflict, fmt.Sprintf("user is already %s", existingUser.Status))
+	}
+
+	logger.Debug("Scheduling user for deletion")
+	deletedUser, err := h.queries.UpdateUserStatus(c.Request.Context(), database.UpdateUserStatusParams{
+		ID:         userId,
+		Status:     lifecycle.StatusPendingDeletion,
+		PurgeAfter: pgtype.Timestamptz{Time: lifecycle.PurgeAfter(h.config), Valid: true},
+	})
 	if err != nil {
-		logger.Error("Error deleting user: %v", err)
+		logger.Error("Error scheduling user for deletion: %v", err)
+		return internal.GenericError[DeleteUserResponse]()
 	}
 
+	logger.Debug("Revoking sessions")
+	err = h.queries.RevokeUserSessions(c.Request.Context(), userId)
+	if err != nil {
+		logger.Error("Error revoking sessions: %v", err)
+		return internal.GenericError[DeleteUserResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserDeleted,
+		Actor:        principal.Actor(),
+		TargetUserID: userId,
+		Metadata:     map[string]any{"purge_after": deletedUser.PurgeAfter.Time},
+	})
+	h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserDeletionScheduled, deletedUser, map[string]any{"purge_after": deletedUser.PurgeAfter.Time})
+
 	return &ctx.Response[DeleteUserResponse]{
 		Response: DeleteUserResponse{
-			Message: "Successfully deleted user",
+			Message: "Successfully scheduled user for deletion",
+			User:    newUser(deletedUser),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) ApproveUser(c *ctx.Request[ApproveUserRequest]) *ctx.Response[ApproveUserResponse] {
+	logger.Info("Invoked: ApproveUser")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[ApproveUserResponse](err)
+	}
+
+	userId := c.GetPathParam("id")
+
+	logger.Debug("Fetching existing user details")
+	existingUser, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[ApproveUserResponse](apierror.UserNotFound, "user not found")
+	}
+	if err != nil {
+		logger.Error("Error getting user: %v", err)
+		return internal.GenericError[ApproveUserResponse]()
+	}
+
+	if existingUser.Status != lifecycle.StatusPendingApproval {
+		logger.Error("User is %s", existingUser.Status)
+		return internal.CustomError[ApproveUserResponse](apierror.AccountStatusConflict, fmt.Sprintf("only users pending approval can be approved, but user is %s", existingUser.Status))
+	}
+
+	logger.Debug("Approving user")
+	approvedUser, err := h.queries.UpdateUserStatus(c.Request.Context(), database.UpdateUserStatusParams{
+		ID:     userId,
+		Status: lifecycle.StatusActive,
+	})
+	if err != nil {
+		logger.Error("Error approving user: %v", err)
+		return internal.GenericError[ApproveUserResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserApproved,
+		Actor:        principal.Actor(),
+		TargetUserID: userId,
+	})
+
+	h.notifier.Notify(approvedUser, notifications.KindAccountApproved, notifications.Details{})
+
+	return &ctx.Response[ApproveUserResponse]{
+		Response: ApproveUserResponse{
+			Message: "Successfully approved user",
+			User:    newUser(approvedUser),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) SuspendUser(c *ctx.Request[SuspendUserRequest]) *ctx.Response[SuspendUserResponse] {
+	logger.Info("Invoked: SuspendUser")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[SuspendUserResponse](err)
+	}
+
+	userId := c.GetPathParam("id")
+
+	logger.Debug("Fetching existing user details")
+	existingUser, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[SuspendUserResponse](apierror.UserNotFound, "user not found")
+	}
+	if err != nil {
+		logger.Error("Error getting user: %v", err)
+		return internal.GenericError[SuspendUserResponse]()
+	}
+
+	// A suspended user can be suspended again to change the reason.
+	if existingUser.Status != lifecycle.StatusActive && existingUser.Status != lifecycle.StatusSuspended {
+		logger.Error("User is %s", existingUser.Status)
+		return internal.CustomError[SuspendUserResponse](apierror.AccountStatusConflict, fmt.Sprintf("user is %s", existingUser.Status))
+	}
+
+	logger.Debug("Suspending user")
+	suspendedUser, err := h.queries.UpdateUserStatus(c.Request.Context(), database.UpdateUserStatusParams{
+		ID:           userId,
+		Status:       lifecycle.StatusSuspended,
+		StatusReason: pgtype.Text{String: c.Body.Reason, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Error suspending user: %v", err)
+		return internal.GenericError[SuspendUserResponse]()
+	}
+
+	logger.Debug("Revoking sessions")
+	err = h.queries.RevokeUserSessions(c.Request.Context(), userId)
+	if err != nil {
+		logger.Error("Error revoking sessions: %v", err)
+		return internal.GenericError[SuspendUserResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserSuspended,
+		Actor:        principal.Actor(),
+		TargetUserID: userId,
+		Metadata:     map[string]any{"reason": c.Body.Reason},
+	})
+
+	return &ctx.Response[SuspendUserResponse]{
+		Response: SuspendUserResponse{
+			Message: "Successfully suspended user",
+			User:    newUser(suspendedUser),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) RestoreUser(c *ctx.Request[RestoreUserRequest]) *ctx.Response[RestoreUserResponse] {
+	logger.Info("Invoked: RestoreUser")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[RestoreUserResponse](err)
+	}
+
+	userId := c.GetPathParam("id")
+
+	logger.Debug("Fetching existing user details")
+	existingUser, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[RestoreUserResponse](apierror.UserNotFound, "user not found")
+	}
+	if err != nil {
+		logger.Error("Error getting user: %v", err)
+		return internal.GenericError[RestoreUserResponse]()
+	}
+
+	if existingUser.Status != lifecycle.StatusSuspended && existingUser.Status != lifecycle.StatusPendingDeletion {
+		logger.Error("User is %s", existingUser.Status)
+		return internal.CustomError[RestoreUserResponse](apierror.AccountStatusConflict, fmt.Sprintf("only suspended users and users pending deletion can be restored, but user is %s", existingUser.Status))
+	}
+
+	logger.Debug("Restoring user")
+	restoredUser, err := h.queries.UpdateUserStatus(c.Request.Context(), database.UpdateUserStatusParams{
+		ID:     userId,
+		Status: lifecycle.StatusActive,
+	})
+	if err != nil {
+		logger.Error("Error restoring user: %v", err)
+		return internal.GenericError[RestoreUserResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserRestored,
+		Actor:        principal.Actor(),
+		TargetUserID: userId,
+		Metadata:     map[string]any{"previous_status": existingUser.Status},
+	})
+
+	if existingUser.Status == lifecycle.StatusPendingDeletion {
+		h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserDeletionCancelled, restoredUser, nil)
+	}
+
+	return &ctx.Response[RestoreUserResponse]{
+		Response: RestoreUserResponse{
+			Message: "Successfully restored user",
+			User:    newUser(restoredUser),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) PurgeUser(c *ctx.Request[PurgeUserRequest]) *ctx.Response[PurgeUserResponse] {
+	logger.Info("Invoked: PurgeUser")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[PurgeUserResponse](err)
+	}
+
+	userId := c.GetPathParam("id")
+
+	logger.Debug("Fetching existing user details")
+	existingUser, err := h.queries.FindUserById(c.Request.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("User not found")
+		return internal.CustomError[PurgeUserResponse](apierror.UserNotFound, "user not found")
+	}
+	if err != nil {
+		logger.Error("Error getting user: %v", err)
+		return internal.GenericError[PurgeUserResponse]()
+	}
+
+	if existingUser.Status == lifecycle.StatusDeleted {
+		logger.Error("User is already deleted")
+		return internal.CustomError[PurgeUserResponse](apierror.AccountStatusConflict, "user is already deleted")
+	}
+
+	logger.Debug("Purging user")
+	purgedUser, err := h.queries.PurgeUser(c.Request.Context(), userId)
+	if err != nil {
+		logger.Error("Error purging user: %v", err)
+		return internal.GenericError[PurgeUserResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionUserPurged,
+		Actor:        principal.Actor(),
+		TargetUserID: userId,
+	})
+	h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserDeleted, purgedUser, nil)
+
+	return &ctx.Response[PurgeUserResponse]{
+		Response: PurgeUserResponse{
+			Message: "Successfully purged user",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) GetMe(c *ctx.Request[GetMeRequest]) *ctx.Response[GetMeResponse] {
+	logger.Info("Invoked: GetMe")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[GetMeResponse](err)
+	}
+
+	return &ctx.Response[GetMeResponse]{
+		Response: GetMeResponse{
+			Message: "Successfully fetched your profile",
+			User:    newUser(principal.User),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) UpdateMe(c *ctx.Request[UpdateMeRequest]) *ctx.Response[UpdateMeResponse] {
+	logger.Info("Invoked: UpdateMe")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[UpdateMeResponse](err)
+	}
+
+	if c.Body.TwoFactorEnabled != nil || c.Body.NotificationOptOuts != nil {
+		err = principal.ForbidImpersonation()
+		if err != nil {
+			logger.Error("Error authorizing request: %v", err)
+			return internal.ApiError[UpdateMeResponse](err)
+		}
+	}
+
+	existingUser := principal.User
+
+	twoFactorEnabled := existingUser.TwoFactorEnabled
+	if c.Body.TwoFactorEnabled != nil {
+		twoFactorEnabled = *c.Body.TwoFactorEnabled
+	}
+
+	if existingUser.TwoFactorEnabled && !twoFactorEnabled {
+		err = principal.ForbidApiKey()
+		if err != nil {
+			logger.Error("Error authorizing request: %v", err)
+			return internal.ApiError[UpdateMeResponse](err)
+		}
+
+		if c.Body.CurrentPassword == nil {
+			logger.Error("Current password is required to disable two-factor authentication")
+			return internal.ApiError[UpdateMeResponse](apierror.Validation(map[string][]string{
+				"current_password": {"This field is required"},
+			}))
+		}
+
+		logger.Debug("Comparing password with hash")
+		err = h.passwordHasher.Verify(existingUser.PasswordHash, *c.Body.CurrentPassword)
+		if err != nil {
+			logger.Error("Error verifying password: %v", err)
+			return internal.CustomError[UpdateMeResponse](apierror.IncorrectPassword, "password is incorrect")
+		}
+	}
+
+	notificationOptOuts := existingUser.NotificationOptOuts
+	if c.Body.NotificationOptOuts != nil {
+		notificationOptOuts = *c.Body.NotificationOptOuts
+	}
+	if notificationOptOuts == nil {
+		notificationOptOuts = []string{}
+	}
+
+	logger.Debug("Validating notification opt-outs")
+	err = notifications.ValidateOptOuts(notificationOptOuts)
+	if err != nil {
+		logger.Error("Error validating notification opt-outs: %v", err)
+		return internal.ApiError[UpdateMeResponse](err)
+	}
+
+	userMetadata := existingUser.UserMetadata
+	if c.Body.UserMetadata != nil {
+		logger.Debug("Validating user metadata")
+		userMetadata, err = h.metadata.ValidateUserMetadata(c.Body.UserMetadata)
+		if err != nil {
+			logger.Error("Error validating user metadata: %v", err)
+			return internal.ApiError[UpdateMeResponse](err)
+		}
+	}
+
+	logger.Debug("Updating user profile")
+	updatedUser, err := h.queries.UpdateUserProfile(c.Request.Context(), database.UpdateUserProfileParams{
+		ID:                  existingUser.ID,
+		TwoFactorEnabled:    twoFactorEnabled,
+		NotificationOptOuts: notificationOptOuts,
+		DisplayName:         updateText(existingUser.DisplayName, c.Body.DisplayName),
+		GivenName:           updateText(existingUser.GivenName, c.Body.GivenName),
+		FamilyName:          updateText(existingUser.FamilyName, c.Body.FamilyName),
+		AvatarUrl:           updateText(existingUser.AvatarUrl, c.Body.AvatarUrl),
+		Locale:              updateText(existingUser.Locale, c.Body.Locale),
+		Timezone:            updateText(existingUser.Timezone, c.Body.Timezone),
+		UserMetadata:        userMetadata,
+	})
+	if err != nil {
+		logger.Error("Error updating user profile: %v", err)
+		return internal.GenericError[UpdateMeResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionProfileUpdated,
+		Actor:        principal.Actor(),
+		TargetUserID: updatedUser.ID,
+		Metadata:     userChanges(existingUser, updatedUser),
+	})
+
+	details := notifications.Details{
+		IpAddress: internal.ClientIp(h.config, c.Request),
+		UserAgent: c.GetHeader("User-Agent"),
+	}
+
+	if updatedUser.TwoFactorEnabled && !existingUser.TwoFactorEnabled {
+		h.notifier.Notify(updatedUser, notifications.KindTwoFactorEnabled, details)
+		h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserTwoFactorEnabled, updatedUser, nil)
+	}
+
+	if !updatedUser.TwoFactorEnabled && existingUser.TwoFactorEnabled {
+		h.notifier.Notify(updatedUser, notifications.KindTwoFactorDisabled, details)
+		h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserTwoFactorDisabled, updatedUser, nil)
+	}
+
+	return &ctx.Response[UpdateMeResponse]{
+		Response: UpdateMeResponse{
+			Message: "Successfully updated your profile",
+			User:    newUser(updatedUser),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) DeleteMe(c *ctx.Request[DeleteMeRequest]) *ctx.Response[DeleteMeResponse] {
+	logger.Info("Invoked: DeleteMe")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[DeleteMeResponse](err)
+	}
+
+	err = principal.ForbidImpersonation()
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[DeleteMeResponse](err)
+	}
+
+	logger.Debug("Comparing password with hash")
+	err = h.passwordHasher.Verify(principal.User.PasswordHash, c.Body.Password)
+	if err != nil {
+		logger.Error("Error verifying password: %v", err)
+		return internal.CustomError[DeleteMeResponse](apierror.IncorrectPassword, "password is incorrect")
+	}
+
+	logger.Debug("Scheduling account for deletion")
+	deletedUser, err := h.queries.UpdateUserStatus(c.Request.Context(), database.UpdateUserStatusParams{
+		ID:                      principal.User.ID,
+		Status:                  lifecycle.StatusPendingDeletion,
+		StatusReason:            pgtype.Text{String: lifecycle.ReasonUserRequested, Valid: true},
+		PurgeAfter:              pgtype.Timestamptz{Time: lifecycle.PurgeAfter(h.config), Valid: true},
+		DeletionRequestedByUser: true,
+	})
+	if err != nil {
+		logger.Error("Error scheduling account for deletion: %v", err)
+		return internal.GenericError[DeleteMeResponse]()
+	}
+
+	logger.Debug("Revoking sessions")
+	err = h.queries.RevokeUserSessions(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		logger.Error("Error revoking sessions: %v", err)
+		return internal.GenericError[DeleteMeResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionAccountDeletionRequested,
+		Actor:        principal.Actor(),
+		TargetUserID: deletedUser.ID,
+		Metadata:     map[string]any{"purge_after": deletedUser.PurgeAfter.Time},
+	})
+
+	h.notifier.Notify(deletedUser, notifications.KindDeletionScheduled, notifications.Details{
+		IpAddress: internal.ClientIp(h.config, c.Request),
+		UserAgent: c.GetHeader("User-Agent"),
+		DeleteAt:  deletedUser.PurgeAfter.Time,
+	})
+	h.dispatcher.Publish(c.Request.Context(), webhooks.EventUserDeletionScheduled, deletedUser, map[string]any{"purge_after": deletedUser.PurgeAfter.Time})
+
+	return &ctx.Response[DeleteMeResponse]{
+		Response: DeleteMeResponse{
+			Message:  "Your account will be deleted. Log in before then to cancel",
+			DeleteAt: deletedUser.PurgeAfter,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) ExportMe(c *ctx.Request[ExportMeRequest]) *ctx.Response[ExportMeResponse] {
+	logger.Info("Invoked: ExportMe")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[ExportMeResponse](err)
+	}
+
+	err = principal.ForbidImpersonation()
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[ExportMeResponse](err)
+	}
+
+	logger.Debug("Fetching sessions")
+	sessions, err := h.queries.ListUserSessions(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		logger.Error("Error getting sessions: %v", err)
+		return internal.GenericError[ExportMeResponse]()
+	}
+
+	logger.Debug("Fetching organizations")
+	memberships, err := h.queries.ListUserOrganizations(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		logger.Error("Error getting organizations: %v", err)
+		return internal.GenericError[ExportMeResponse]()
+	}
+
+	logger.Debug("Fetching password changes")
+	passwordChanges, err := h.queries.ListPasswordChangeDates(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		logger.Error("Error getting password changes: %v", err)
+		return internal.GenericError[ExportMeResponse]()
+	}
+
+	logger.Debug("Fetching audit events")
+	auditEvents, err := h.queries.ListUserAuditEvents(c.Request.Context(), pgtype.Text{String: principal.User.ID, Valid: true})
+	if err != nil {
+		logger.Error("Error getting audit events: %v", err)
+		return internal.GenericError[ExportMeResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionDataExported,
+		Actor:        principal.Actor(),
+		TargetUserID: principal.User.ID,
+	})
+
+	c.Response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="thorfinn-export-%s.json"`, principal.User.ID))
+
+	return &ctx.Response[ExportMeResponse]{
+		Response: ExportMeResponse{
+			Message: "Successfully exported your data",
+			Export:  newUserExport(principal.User, sessions, memberships, passwordChanges, auditEvents),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) CreateInvitation(c *ctx.Request[CreateInvitationRequest]) *ctx.Response[CreateInvitationResponse] {
+	logger.Info("Invoked: CreateInvitation")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CreateInvitationResponse](err)
+	}
+
+	logger.Debug("Checking email against registration policy")
+	err = h.registration.CheckEmail(c.Body.Email)
+	if err != nil {
+		logger.Error("Email is not allowed: %v", err)
+		return internal.ApiError[CreateInvitationResponse](err)
+	}
+
+	logger.Debug("Finding user by email")
+	_, err = h.queries.FindUserByEmail(c.Request.Context(), c.Body.Email)
+	if err == nil {
+		logger.Error("Email is already in use")
+		return internal.CustomError[CreateInvitationResponse](apierror.EmailTaken, "this email is already in use")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Error finding user by email: %v", err)
+		return internal.GenericError[CreateInvitationResponse]()
+	}
+
+	logger.Debug("Creating invitation")
+	invitation, err := h.inviter.Invite(c.Request.Context(), invitations.Params{
+		Email:     c.Body.Email,
+		Role:      c.Body.Role,
+		InvitedBy: principal.User,
+	})
+	if err != nil {
+		logger.Error("Error creating invitation: %v", err)
+		return internal.GenericError[CreateInvitationResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionInvitationCreated,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"invitation_id": invitation.ID, "email": invitation.Email, "role": invitation.Role.String},
+	})
+
+	return &ctx.Response[CreateInvitationResponse]{
+		Response: CreateInvitationResponse{
+			Message:    "Successfully sent invitation",
+			Invitation: newInvitation(invitation),
+		},
+		StatusCode: http.StatusCreated,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) GetInvitations(c *ctx.Request[GetInvitationsRequest]) *ctx.Response[GetInvitationsResponse] {
+	logger.Info("Invoked: GetInvitations")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetInvitationsResponse](err)
+	}
+
+	logger.Debug("Listing pending invitations")
+	rows, err := h.queries.ListPendingInvitations(c.Request.Context(), pgtype.Text{})
+	if err != nil {
+		logger.Error("Error listing pending invitations: %v", err)
+		return internal.GenericError[GetInvitationsResponse]()
+	}
+
+	pending := make([]Invitation, 0, len(rows))
+	for _, row := range rows {
+		pending = append(pending, newInvitation(row))
+	}
+
+	return &ctx.Response[GetInvitationsResponse]{
+		Response: GetInvitationsResponse{
+			Message:     "Successfully fetched invitations",
+			Invitations: pending,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) RevokeInvitation(c *ctx.Request[RevokeInvitationRequest]) *ctx.Response[RevokeInvitationResponse] {
+	logger.Info("Invoked: RevokeInvitation")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[RevokeInvitationResponse](err)
+	}
+
+	logger.Debug("Finding invitation by id")
+	invitation, err := h.queries.FindInvitationById(c.Request.Context(), c.GetPathParam("id"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && invitation.OrganizationID.Valid) {
+		logger.Error("Invitation not found")
+		return internal.ApiError[RevokeInvitationResponse](invitations.ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding invitation by id: %v", err)
+		return internal.GenericError[RevokeInvitationResponse]()
+	}
+
+	logger.Debug("Deleting invitation")
+	err = h.queries.DeleteInvitation(c.Request.Context(), invitation.ID)
+	if err != nil {
+		logger.Error("Error deleting invitation: %v", err)
+		return internal.GenericError[RevokeInvitationResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionInvitationRevoked,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"invitation_id": invitation.ID, "email": invitation.Email},
+	})
+
+	return &ctx.Response[RevokeInvitationResponse]{
+		Response: RevokeInvitationResponse{
+			Message: "Successfully revoked invitation",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) CreateApiKey(c *ctx.Request[CreateApiKeyRequest]) *ctx.Response[CreateApiKeyResponse] {
+	logger.Info("Invoked: CreateApiKey")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[CreateApiKeyResponse](err)
+	}
+
+	err = principal.ForbidImpersonation()
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CreateApiKeyResponse](err)
+	}
+
+	err = apikeys.Validate(c.Body.Scopes, c.Body.ExpiresAt)
+	if err != nil {
+		logger.Error("Error validating API key: %v", err)
+		return internal.ApiError[CreateApiKeyResponse](err)
+	}
+
+	logger.Debug("Generating API key")
+	key, prefix, hash, err := apikeys.Generate(h.config.ApiKeyPrefix)
+	if err != nil {
+		logger.Error("Error generating API key: %v", err)
+		return internal.GenericError[CreateApiKeyResponse]()
+	}
+
+	expiresAt := pgtype.Timestamptz{}
+	if c.Body.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *c.Body.ExpiresAt, Valid: true}
+	}
+
+	logger.Debug("Creating API key")
+	apiKey, err := h.queries.CreateApiKey(c.Request.Context(), database.CreateApiKeyParams{
+		ID:        uuid.New().String(),
+		UserID:    principal.User.ID,
+		Name:      c.Body.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    c.Body.Scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		logger.Error("Error creating API key: %v", err)
+		return internal.GenericError[CreateApiKeyResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionApiKeyCreated,
+		Actor:        principal.Actor(),
+		TargetUserID: principal.User.ID,
+		Metadata:     map[string]any{"key_id": apiKey.ID, "name": apiKey.Name, "scopes": apiKey.Scopes},
+	})
+
+	return &ctx.Response[CreateApiKeyResponse]{
+		Response: CreateApiKeyResponse{
+			Message: "Successfully created API key",
+			Key:     key,
+			ApiKey:  newApiKey(apiKey),
+		},
+		StatusCode: http.StatusCreated,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) GetApiKeys(c *ctx.Request[GetApiKeysRequest]) *ctx.Response[GetApiKeysResponse] {
+	logger.Info("Invoked: GetApiKeys")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[GetApiKeysResponse](err)
+	}
+
+	logger.Debug("Listing API keys")
+	rows, err := h.queries.ListUserApiKeys(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		logger.Error("Error listing API keys: %v", err)
+		return internal.GenericError[GetApiKeysResponse]()
+	}
+
+	keys := make([]ApiKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, newApiKey(row))
+	}
+
+	return &ctx.Response[GetApiKeysResponse]{
+		Response: GetApiKeysResponse{
+			Message: "Successfully fetched API keys",
+			ApiKeys: keys,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *UsersHandlers) RevokeApiKey(c *ctx.Request[RevokeApiKeyRequest]) *ctx.Response[RevokeApiKeyResponse] {
+	logger.Info("Invoked: RevokeApiKey")
+
+	principal, err := h.authenticator.Authenticate(c.Request)
+	if err != nil {
+		logger.Error("Error authenticating request: %v", err)
+		return internal.ApiError[RevokeApiKeyResponse](err)
+	}
+
+	logger.Debug("Finding API key by id")
+	apiKey, err := h.queries.FindApiKeyById(c.Request.Context(), c.GetPathParam("id"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (apiKey.UserID != principal.User.ID || apiKey.OrganizationID.Valid)) {
+		logger.Error("API key not found")
+		return internal.ApiError[RevokeApiKeyResponse](apikeys.ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding API key by id: %v", err)
+		return internal.GenericError[RevokeApiKeyResponse]()
+	}
+
+	logger.Debug("Revoking API key")
+	err = h.queries.RevokeApiKey(c.Request.Context(), apiKey.ID)
+	if err != nil {
+		logger.Error("Error revoking API key: %v", err)
+		return internal.GenericError[RevokeApiKeyResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:       audit.ActionApiKeyRevoked,
+		Actor:        principal.Actor(),
+		TargetUserID: principal.User.ID,
+		Metadata:     map[string]any{"key_id": apiKey.ID, "name": apiKey.Name},
+	})
+
+	return &ctx.Response[RevokeApiKeyResponse]{
+		Response: RevokeApiKeyResponse{
+			Message: "Successfully revoked API key",
 		},
 		StatusCode: http.StatusOK,
 		Error:      nil,
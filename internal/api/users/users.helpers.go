@@ -0,0 +1,504 @@
+package users_features
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/lifecycle"
+	"github.com/abyanmajid/thorfinn/internal/metadata"
+	"github.com/abyanmajid/thorfinn/internal/validation"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
+)
+
+var userSortFields = []string{"created_at", "email", "last_login_at"}
+
+// usersCursor identifies the last user of a page. It also records the sort it
+// was issued for, so that it can't be reused with a different sort.
+type usersCursor struct {
+	SortBy     string             `json:"s"`
+	Descending bool               `json:"d"`
+	ID         string             `json:"i"`
+	Email      string             `json:"e,omitempty"`
+	Time       pgtype.Timestamptz `json:"t"`
+}
+
+func newUsersCursor(user database.ListUsersRow, sortBy string, descending bool) usersCursor {
+	cursor := usersCursor{
+		SortBy:     sortBy,
+		Descending: descending,
+		ID:         user.ID,
+	}
+
+	switch sortBy {
+	case "email":
+		cursor.Email = user.Email
+	case "last_login_at":
+		cursor.Time = user.LastLoginAt
+		if !cursor.Time.Valid {
+			cursor.Time = pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true}
+		}
+	default:
+		cursor.Time = user.CreatedAt
+	}
+
+	return cursor
+}
+
+func encodeUsersCursor(cursor usersCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeUsersCursor(encoded string) (usersCursor, error) {
+	var cursor usersCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, err
+	}
+
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+func newUser(user database.ThorfinnUser) User {
+	return User{
+		ID:                  user.ID,
+		Email:               user.Email,
+		Verified:            user.Verified,
+		TwoFactorEnabled:    user.TwoFactorEnabled,
+		Roles:               user.Roles,
+		NotificationOptOuts: user.NotificationOptOuts,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		LastLoginAt:         user.LastLoginAt,
+		DisplayName:         user.DisplayName,
+		GivenName:           user.GivenName,
+		FamilyName:          user.FamilyName,
+		AvatarUrl:           user.AvatarUrl,
+		Locale:              user.Locale,
+		Timezone:            user.Timezone,
+		UserMetadata:        rawMetadata(user.UserMetadata),
+		AppMetadata:         rawMetadata(user.AppMetadata),
+		Status:              user.Status,
+		StatusReason:        user.StatusReason,
+		PurgeAfter:          user.PurgeAfter,
+		DeletedAt:           user.DeletedAt,
+	}
+}
+
+// newUserExport assembles the export of user from the records stored about
+// them.
+func newUserExport(user database.ThorfinnUser, sessions []database.ThorfinnSession, memberships []database.ListUserOrganizationsRow, passwordChanges []pgtype.Timestamptz, auditEvents []database.ThorfinnAuditEvent) UserExport {
+	export := UserExport{
+		ExportedAt:      time.Now().UTC(),
+		Profile:         newUser(user),
+		Sessions:        []ExportedSession{},
+		Organizations:   []ExportedMembership{},
+		PasswordChanges: []pgtype.Timestamptz{},
+		MfaEnrollments:  []MfaEnrollment{},
+		AuditEvents:     []ExportedAuditEvent{},
+	}
+
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, ExportedSession{
+			ID:             session.ID,
+			IpAddress:      session.IpAddress,
+			UserAgent:      session.UserAgent,
+			ImpersonatedBy: session.ImpersonatorID,
+			CreatedAt:      session.CreatedAt,
+			ExpiresAt:      session.ExpiresAt,
+			RevokedAt:      session.RevokedAt,
+		})
+	}
+
+	for _, membership := range memberships {
+		export.Organizations = append(export.Organizations, ExportedMembership{
+			OrganizationID: membership.ID,
+			Name:           membership.Name,
+			Slug:           membership.Slug,
+			Role:           membership.Role,
+		})
+	}
+
+	export.PasswordChanges = append(export.PasswordChanges, passwordChanges...)
+
+	for _, event := range auditEvents {
+		export.AuditEvents = append(export.AuditEvents, ExportedAuditEvent{
+			Action:         event.Action,
+			Outcome:        event.Outcome,
+			ActorID:        event.ActorID,
+			ImpersonatedBy: event.ImpersonatorID,
+			TargetUserID:   event.TargetUserID,
+			IpAddress:      event.IpAddress,
+			UserAgent:      event.UserAgent,
+			Metadata:       json.RawMessage(event.Metadata),
+			CreatedAt:      event.CreatedAt,
+		})
+	}
+
+	if user.TwoFactorEnabled {
+		export.MfaEnrollments = append(export.MfaEnrollments, MfaEnrollment{
+			Method:      "email_otp",
+			Destination: user.Email,
+		})
+	}
+
+	return export
+}
+
+// userChanges describes how updatedUser differs from existingUser, for the
+// audit log. It names the changed fields, and records the roles before and
+// after they change since that grants or takes away access.
+func userChanges(existingUser database.ThorfinnUser, updatedUser database.ThorfinnUser) map[string]any {
+	changed := []string{}
+	changes := map[string]any{}
+
+	fields := []struct {
+		name  string
+		equal bool
+	}{
+		{"email", existingUser.Email == updatedUser.Email},
+		{"verified", existingUser.Verified == updatedUser.Verified},
+		{"two_factor_enabled", existingUser.TwoFactorEnabled == updatedUser.TwoFactorEnabled},
+		{"roles", slices.Equal(existingUser.Roles, updatedUser.Roles)},
+		{"notification_opt_outs", slices.Equal(existingUser.NotificationOptOuts, updatedUser.NotificationOptOuts)},
+		{"display_name", existingUser.DisplayName == updatedUser.DisplayName},
+		{"given_name", existingUser.GivenName == updatedUser.GivenName},
+		{"family_name", existingUser.FamilyName == updatedUser.FamilyName},
+		{"avatar_url", existingUser.AvatarUrl == updatedUser.AvatarUrl},
+		{"locale", existingUser.Locale == updatedUser.Locale},
+		{"timezone", existingUser.Timezone == updatedUser.Timezone},
+		{"user_metadata", bytes.Equal(existingUser.UserMetadata, updatedUser.UserMetadata)},
+		{"app_metadata", bytes.Equal(existingUser.AppMetadata, updatedUser.AppMetadata)},
+	}
+	for _, field := range fields {
+		if !field.equal {
+			changed = append(changed, field.name)
+		}
+	}
+	changes["changed"] = changed
+
+	if !slices.Equal(existingUser.Roles, updatedUser.Roles) {
+		changes["roles"] = map[string]any{"from": existingUser.Roles, "to": updatedUser.Roles}
+	}
+
+	return changes
+}
+
+func newInvitation(invitation database.ThorfinnInvitation) Invitation {
+	return Invitation{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role.String,
+		InvitedBy: invitation.InvitedBy,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
+
+func newApiKey(key database.ThorfinnApiKey) ApiKey {
+	return ApiKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func rawMetadata(document []byte) json.RawMessage {
+	if len(document) == 0 {
+		return json.RawMessage("{}")
+	}
+
+	return json.RawMessage(document)
+}
+
+// updateText applies an optional update to a nullable profile field. A nil
+// update keeps the current value, and an empty string clears it.
+func updateText(current pgtype.Text, update *string) pgtype.Text {
+	if update == nil {
+		return current
+	}
+
+	return pgtype.Text{String: *update, Valid: *update != ""}
+}
+
+type listUsersQuery struct {
+	params       database.ListUsersParams
+	includeTotal bool
+}
+
+func (q listUsersQuery) countParams() database.CountUsersParams {
+	return database.CountUsersParams{
+		Verified:         q.params.Verified,
+		TwoFactorEnabled: q.params.TwoFactorEnabled,
+		CreatedAfter:     q.params.CreatedAfter,
+		CreatedBefore:    q.params.CreatedBefore,
+		EmailPrefix:      q.params.EmailPrefix,
+		EmailContains:    q.params.EmailContains,
+		Status:           q.params.Status,
+	}
+}
+
+// parseListUsersQuery reads the query parameters of GetAllUsers, collecting
+// every invalid parameter into a single validation error.
+func parseListUsersQuery(c *ctx.Request[GetAllUsersRequest]) (listUsersQuery, error) {
+	fields := map[string][]string{}
+	query := listUsersQuery{
+		params: database.ListUsersParams{
+			SortBy:     c.GetQueryParamDefault("sort_by", "created_at"),
+			Descending: true,
+			Limit:      defaultUsersLimit + 1,
+		},
+	}
+
+	if limit := c.GetQueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxUsersLimit {
+			fields["limit"] = []string{fmt.Sprintf("Must be an integer between 1 and %d", maxUsersLimit)}
+		}
+		query.params.Limit = int32(n) + 1
+	}
+
+	if !slices.Contains(userSortFields, query.params.SortBy) {
+		fields["sort_by"] = []string{fmt.Sprintf("Must be one of %s", strings.Join(userSortFields, ", "))}
+	}
+
+	switch order := c.GetQueryParamDefault("order", "desc"); order {
+	case "asc":
+		query.params.Descending = false
+	case "desc":
+		query.params.Descending = true
+	default:
+		fields["order"] = []string{"Must be one of asc, desc"}
+	}
+
+	query.params.Verified = parseBoolParam(c, "verified", fields)
+	query.params.TwoFactorEnabled = parseBoolParam(c, "two_factor_enabled", fields)
+	query.params.CreatedAfter = parseTimeParam(c, "created_after", fields)
+	query.params.CreatedBefore = parseTimeParam(c, "created_before", fields)
+
+	if prefix := c.GetQueryParam("email_prefix"); prefix != "" {
+		query.params.EmailPrefix = pgtype.Text{String: escapeLike(prefix), Valid: true}
+	}
+
+	if substring := c.GetQueryParam("email_contains"); substring != "" {
+		query.params.EmailContains = pgtype.Text{String: escapeLike(substring), Valid: true}
+	}
+
+	if status := c.GetQueryParam("status"); status != "" {
+		if !slices.Contains(lifecycle.Statuses, status) {
+			fields["status"] = []string{fmt.Sprintf("Must be one of %s", strings.Join(lifecycle.Statuses, ", "))}
+		}
+		query.params.Status = pgtype.Text{String: status, Valid: true}
+	}
+
+	includeTotal := parseBoolParam(c, "include_total", fields)
+	query.includeTotal = includeTotal.Valid && includeTotal.Bool
+
+	if after := c.GetQueryParam("after"); after != "" {
+		cursor, err := decodeUsersCursor(after)
+		if err != nil || cursor.ID == "" {
+			fields["after"] = []string{"Must be a cursor returned by a previous request"}
+		} else if cursor.SortBy != query.params.SortBy || cursor.Descending != query.params.Descending {
+			fields["after"] = []string{"Must be a cursor issued for the same sort_by and order"}
+		} else {
+			query.params.AfterID = pgtype.Text{String: cursor.ID, Valid: true}
+			query.params.AfterEmail = cursor.Email
+			query.params.AfterTime = cursor.Time
+		}
+	}
+
+	if len(fields) > 0 {
+		return query, apierror.Validation(fields)
+	}
+
+	return query, nil
+}
+
+func parseBoolParam(c *ctx.Request[GetAllUsersRequest], name string, fields map[string][]string) pgtype.Bool {
+	value := c.GetQueryParam(name)
+	if value == "" {
+		return pgtype.Bool{}
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		fields[name] = []string{"Must be true or false"}
+		return pgtype.Bool{}
+	}
+
+	return pgtype.Bool{Bool: b, Valid: true}
+}
+
+func parseTimeParam(c *ctx.Request[GetAllUsersRequest], name string, fields map[string][]string) pgtype.Timestamptz {
+	value := c.GetQueryParam(name)
+	if value == "" {
+		return pgtype.Timestamptz{}
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		fields[name] = []string{"Must be an RFC 3339 timestamp"}
+		return pgtype.Timestamptz{}
+	}
+
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+var (
+	errUserModified   = apierror.New(apierror.PreconditionFailed, "user was modified by another request")
+	errIfMatchMissing = apierror.New(apierror.PreconditionRequired, "the If-Match header is required to update a user")
+	errUserDeleted    = apierror.New(apierror.AccountStatusConflict, "deleted users can't be updated")
+	errEmailTaken     = apierror.New(apierror.EmailTaken, "this email is already in use")
+)
+
+// userETag returns the entity tag of a user, derived from its version.
+func userETag(user database.ThorfinnUser) string {
+	return fmt.Sprintf(`"%d"`, user.Version)
+}
+
+// checkIfMatch returns errIfMatchMissing if the If-Match header is empty, so
+// that updates can't silently overwrite concurrent changes, and
+// errUserModified unless it is * or lists the current entity tag of user.
+// Weak tags never match.
+func checkIfMatch(header string, user database.ThorfinnUser) error {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return errIfMatchMissing
+	}
+	if header == "*" {
+		return nil
+	}
+
+	etag := userETag(user)
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == etag {
+			return nil
+		}
+	}
+
+	return errUserModified
+}
+
+// applyUserPatch applies a JSON Merge Patch to the current representation of
+// user, and returns the result as a full update of the user.
+func applyUserPatch(user database.ThorfinnUser, patch map[string]any) (UpdateUserRequest, error) {
+	document := map[string]any{
+		"email":              user.Email,
+		"verified":           user.Verified,
+		"two_factor_enabled": user.TwoFactorEnabled,
+		"roles":              user.Roles,
+		"display_name":       textValue(user.DisplayName),
+		"given_name":         textValue(user.GivenName),
+		"family_name":        textValue(user.FamilyName),
+		"avatar_url":         textValue(user.AvatarUrl),
+		"locale":             textValue(user.Locale),
+		"timezone":           textValue(user.Timezone),
+		"user_metadata":      metadata.Decode(user.UserMetadata),
+		"app_metadata":       metadata.Decode(user.AppMetadata),
+	}
+
+	merged, _ := mergePatch(document, patch).(map[string]any)
+
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		return UpdateUserRequest{}, apierror.New(apierror.InvalidRequest, "invalid request body")
+	}
+
+	var body UpdateUserRequest
+	if err := json.Unmarshal(encoded, &body); err != nil {
+		return UpdateUserRequest{}, apierror.New(apierror.InvalidRequest, "invalid request body")
+	}
+
+	fields := validation.Validate(body)
+	for _, name := range []string{"email", "verified", "two_factor_enabled"} {
+		if merged[name] == nil {
+			fields[name] = append(fields[name], "This field is required")
+		}
+	}
+	if len(fields) > 0 {
+		return UpdateUserRequest{}, apierror.Validation(fields)
+	}
+
+	// The merged document holds every field, so a missing field was removed
+	// by the patch.
+	cleared := ""
+	for _, field := range []**string{&body.DisplayName, &body.GivenName, &body.FamilyName, &body.AvatarUrl, &body.Locale, &body.Timezone} {
+		if *field == nil {
+			*field = &cleared
+		}
+	}
+	if body.Roles == nil {
+		body.Roles = &[]string{}
+	}
+
+	// Only revalidate the metadata documents the patch touches, so that
+	// documents stored before a schema change don't block unrelated edits.
+	for name, document := range map[string]*map[string]any{"user_metadata": &body.UserMetadata, "app_metadata": &body.AppMetadata} {
+		if _, ok := patch[name]; !ok {
+			*document = nil
+		} else if *document == nil {
+			*document = map[string]any{}
+		}
+	}
+
+	return body, nil
+}
+
+// mergePatch applies patch to target as described by RFC 7396.
+func mergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+func textValue(text pgtype.Text) any {
+	if !text.Valid {
+		return nil
+	}
+
+	return text.String
+}
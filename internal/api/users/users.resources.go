@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/abyanmajid/matcha/openapi"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
 )
 
 type UsersResources struct {
@@ -29,21 +31,35 @@ func (r *UsersResources) GetAllUsersResource() (*openapi.Resource, error) {
 
 	doc := openapi.ResourceDoc{
 		Summary:     "Get all users",
-		Description: "Get all users",
+		Description: "Get a page of users, optionally filtered and sorted. Pass the next_cursor of a response as the after parameter to fetch the next page; it is omitted on the last page",
 		Schema: openapi.Schema{
+			Parameters: []openapi.Parameter{
+				{In: "query", Name: "limit", Description: "The maximum number of users to return, between 1 and 100. Defaults to 20"},
+				{In: "query", Name: "after", Description: "The next_cursor of the previous page. Must be used with the same sort_by and order"},
+				{In: "query", Name: "sort_by", Description: "The field to sort by: created_at, email or last_login_at. Defaults to created_at"},
+				{In: "query", Name: "order", Description: "The sort order: asc or desc. Defaults to desc. Users who have never logged in sort before all others"},
+				{In: "query", Name: "verified", Description: "Only return users whose email is (true) or is not (false) verified"},
+				{In: "query", Name: "two_factor_enabled", Description: "Only return users who have (true) or have not (false) enabled two-factor authentication"},
+				{In: "query", Name: "created_after", Description: "Only return users created at or after this RFC 3339 timestamp"},
+				{In: "query", Name: "created_before", Description: "Only return users created before this RFC 3339 timestamp"},
+				{In: "query", Name: "email_prefix", Description: "Only return users whose email starts with this value (case sensitive)"},
+				{In: "query", Name: "email_contains", Description: "Only return users whose email contains this value (case insensitive)"},
+				{In: "query", Name: "status", Description: "Only return users with this status: active, suspended, pending_deletion or deleted"},
+				{In: "query", Name: "include_total", Description: "Set to true to include the total number of users matching the filters"},
+			},
 			RequestBody: openapi.RequestBody{
 				Content: openapi.Json(requestSchema),
 			},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
-					Description: "Successfully fetched all users",
+					Description: "Successfully fetched users",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.ValidationFailed),
 		},
 	}
 
-	resource := openapi.NewResource("GetAllUsers", doc, r.handlers.GetAllUsers)
+	resource := internal.NewResource("GetAllUsers", doc, r.handlers.GetAllUsers)
 
 	return &resource, nil
 }
@@ -61,21 +77,21 @@ func (r *UsersResources) GetUserResource() (*openapi.Resource, error) {
 
 	doc := openapi.ResourceDoc{
 		Summary:     "Get user by id",
-		Description: "Get user by id",
+		Description: "Get user by id. The response carries the user's ETag, to be sent back in the If-Match header of an update",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{
 				Content: openapi.Json(requestSchema),
 			},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Successfully fetched user",
 					Content:     openapi.Json(responseSchema),
 				},
-			},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.UserNotFound),
 		},
 	}
 
-	resource := openapi.NewResource("GetUser", doc, r.handlers.GetUser)
+	resource := internal.NewResource("GetUser", doc, r.handlers.GetUser)
 
 	return &resource, nil
 }
@@ -93,21 +109,59 @@ func (r *UsersResources) UpdateUserResource() (*openapi.Resource, error) {
 
 	doc := openapi.ResourceDoc{
 		Summary:     "Update user",
-		Description: "Update user",
+		Description: "Update user. user_metadata and app_metadata replace the stored documents, and are checked against the configured JSON Schemas. Set a profile field to an empty string to clear it. The If-Match header is required, and the update fails with 428 without it. The update fails with 412 if the If-Match header doesn't match the user's current ETag, or if the user was modified since it was read",
 		Schema: openapi.Schema{
+			Parameters: []openapi.Parameter{
+				{In: "header", Name: "If-Match", Description: "Required. The ETag of the user the update is based on, or * to update whatever the current version"},
+			},
 			RequestBody: openapi.RequestBody{
 				Content: openapi.Json(requestSchema),
 			},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
 					Description: "Successfully updated user",
 					Content:     openapi.Json(responseSchema),
 				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.InvalidRequest, apierror.ValidationFailed, apierror.UserNotFound, apierror.PreconditionFailed, apierror.PreconditionRequired, apierror.PasswordReused, apierror.EmailTaken),
+		},
+	}
+
+	resource := internal.NewResource("UpdateUser", doc, r.handlers.UpdateUser)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) PatchUserResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(PatchUserRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(PatchUserResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Patch user",
+		Description: "Update user with a JSON Merge Patch (RFC 7396). Fields left out of the patch are unchanged, and null removes a profile field or a metadata key. The If-Match header is required, and the update fails with 428 without it. The update fails with 412 if the If-Match header doesn't match the user's current ETag, or if the user was modified since it was read",
+		Schema: openapi.Schema{
+			Parameters: []openapi.Parameter{
+				{In: "header", Name: "If-Match", Description: "Required. The ETag of the user the patch is based on, or * to patch whatever the current version"},
+			},
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
 			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully updated user",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.InvalidRequest, apierror.ValidationFailed, apierror.UserNotFound, apierror.PreconditionFailed, apierror.PreconditionRequired, apierror.PasswordReused, apierror.EmailTaken),
 		},
 	}
 
-	resource := openapi.NewResource("UpdateUser", doc, r.handlers.UpdateUser)
+	resource := internal.NewResource("PatchUser", doc, r.handlers.PatchUser)
 
 	return &resource, nil
 }
@@ -125,21 +179,469 @@ func (r *UsersResources) DeleteUserResource() (*openapi.Resource, error) {
 
 	doc := openapi.ResourceDoc{
 		Summary:     "Delete user",
-		Description: "Delete user",
+		Description: "Schedule the user for deletion and revoke their sessions. The user can't sign in, and is purged once the deletion grace period is over unless restored first",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully scheduled user for deletion",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.UserNotFound, apierror.AccountStatusConflict),
+		},
+	}
+
+	resource := internal.NewResource("DeleteUser", doc, r.handlers.DeleteUser)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) ApproveUserResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(ApproveUserRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(ApproveUserResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Approve user",
+		Description: "Approve a user who registered while registration required approval, letting them log in. To reject a user, delete them",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully approved user",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.UserNotFound, apierror.AccountStatusConflict),
+		},
+	}
+
+	resource := internal.NewResource("ApproveUser", doc, r.handlers.ApproveUser)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) SuspendUserResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(SuspendUserRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(SuspendUserResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Suspend user",
+		Description: "Suspend the user with a reason and revoke their sessions. A suspended user can't sign in until restored. Suspending a suspended user updates the reason",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully suspended user",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.ValidationFailed, apierror.UserNotFound, apierror.AccountStatusConflict),
+		},
+	}
+
+	resource := internal.NewResource("SuspendUser", doc, r.handlers.SuspendUser)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) RestoreUserResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(RestoreUserRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(RestoreUserResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Restore user",
+		Description: "Make a suspended user, or a user pending deletion, active again",
 		Schema: openapi.Schema{
 			RequestBody: openapi.RequestBody{
 				Content: openapi.Json(requestSchema),
 			},
-			Responses: map[int]openapi.Response{
+			Responses: apierror.Document(map[int]openapi.Response{
 				http.StatusOK: {
-					Description: "Successfully deleted user",
+					Description: "Successfully restored user",
 					Content:     openapi.Json(responseSchema),
 				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.UserNotFound, apierror.AccountStatusConflict),
+		},
+	}
+
+	resource := internal.NewResource("RestoreUser", doc, r.handlers.RestoreUser)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) PurgeUserResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(PurgeUserRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(PurgeUserResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Purge user",
+		Description: "Immediately erase the user's personal data, sessions and codes, leaving a deleted tombstone. This can't be undone",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
 			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully purged user",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.UserNotFound, apierror.AccountStatusConflict),
+		},
+	}
+
+	resource := internal.NewResource("PurgeUser", doc, r.handlers.PurgeUser)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) GetMeResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetMeRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetMeResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get the current user",
+		Description: "Get the profile of the user the access token belongs to",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched your profile",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted),
+		},
+	}
+
+	resource := internal.NewResource("GetMe", doc, r.handlers.GetMe)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) UpdateMeResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(UpdateMeRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(UpdateMeResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Update the current user",
+		Description: "Update the fields of their own profile a user may change themselves. The email and password are changed through their own verified flows, and verified status, roles and app_metadata can only be changed by an admin. user_metadata replaces the stored document, and is checked against the configured JSON Schema. Set a profile field to an empty string to clear it",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully updated your profile",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.NotificationNotOptional, apierror.ImpersonationNotAllowed, apierror.Forbidden, apierror.IncorrectPassword),
+		},
+	}
+
+	resource := internal.NewResource("UpdateMe", doc, r.handlers.UpdateMe)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) DeleteMeResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(DeleteMeRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(DeleteMeResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Delete the current user",
+		Description: "Schedule the account the access token belongs to for deletion. The current password is required to confirm the deletion. Every session is revoked and a confirmation is emailed. The account is permanently deleted at delete_at, unless the user logs in before then",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Your account will be deleted",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.IncorrectPassword),
+		},
+	}
+
+	resource := internal.NewResource("DeleteMe", doc, r.handlers.DeleteMe)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) ExportMeResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(ExportMeRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(ExportMeResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Export the current user's data",
+		Description: "Export everything stored about the account the access token belongs to: its profile, sessions, organization memberships, password change dates and MFA enrollments. Secrets such as password hashes, tokens and OTP codes are left out. The response is sent as a JSON attachment",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully exported your data",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted),
+		},
+	}
+
+	resource := internal.NewResource("ExportMe", doc, r.handlers.ExportMe)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) CreateInvitationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CreateInvitationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CreateInvitationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Create invitation",
+		Description: "Invite an email address to create an account, optionally with a global role. The invitee sets a password when accepting, and their email is verified by accepting. The invitation link expires and can only be used once, and inviting the same email again replaces the pending invitation",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "Successfully sent invitation",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.EmailTaken, apierror.EmailDomainNotAllowed, apierror.DisposableEmail),
+		},
+	}
+
+	resource := internal.NewResource("CreateInvitation", doc, r.handlers.CreateInvitation)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) GetInvitationsResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetInvitationsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetInvitationsResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get invitations",
+		Description: "Get the pending invitations to create an account",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched invitations",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("GetInvitations", doc, r.handlers.GetInvitations)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) RevokeInvitationResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(RevokeInvitationRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(RevokeInvitationResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Revoke invitation",
+		Description: "Revoke a pending invitation to create an account",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully revoked invitation",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.InvitationNotFound),
+		},
+	}
+
+	resource := internal.NewResource("RevokeInvitation", doc, r.handlers.RevokeInvitation)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) CreateApiKeyResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CreateApiKeyRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CreateApiKeyResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Create API key",
+		Description: "Create an API key for the current user, with scopes and an optional expiry. The key is only returned once. It authenticates as the current user, limited to its scopes, by sending it as a bearer token",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "Successfully created API key",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("CreateApiKey", doc, r.handlers.CreateApiKey)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) GetApiKeysResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetApiKeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetApiKeysResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get API keys",
+		Description: "Get the current user's API keys that haven't been revoked",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched API keys",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("GetApiKeys", doc, r.handlers.GetApiKeys)
+
+	return &resource, nil
+}
+
+func (r *UsersResources) RevokeApiKeyResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(RevokeApiKeyRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(RevokeApiKeyResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Revoke API key",
+		Description: "Revoke one of the current user's API keys",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully revoked API key",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.ApiKeyNotFound),
 		},
 	}
 
-	resource := openapi.NewResource("DeleteUser", doc, r.handlers.DeleteUser)
+	resource := internal.NewResource("RevokeApiKey", doc, r.handlers.RevokeApiKey)
 
 	return &resource, nil
 }
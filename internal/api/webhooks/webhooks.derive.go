@@ -0,0 +1,61 @@
+package webhooks_features
+
+import "github.com/abyanmajid/matcha/openapi"
+
+type DerivedWebhooksResources struct {
+	CreateWebhook        *openapi.Resource
+	GetWebhooks          *openapi.Resource
+	GetWebhook           *openapi.Resource
+	UpdateWebhook        *openapi.Resource
+	DeleteWebhook        *openapi.Resource
+	GetWebhookDeliveries *openapi.Resource
+	RedeliverWebhook     *openapi.Resource
+}
+
+func Derive(handlers *WebhooksHandlers) (*DerivedWebhooksResources, error) {
+	webhooksResources := NewWebhooksResources(handlers)
+	createWebhookResource, err := webhooksResources.CreateWebhookResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getWebhooksResource, err := webhooksResources.GetWebhooksResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getWebhookResource, err := webhooksResources.GetWebhookResource()
+	if err != nil {
+		return nil, err
+	}
+
+	updateWebhookResource, err := webhooksResources.UpdateWebhookResource()
+	if err != nil {
+		return nil, err
+	}
+
+	deleteWebhookResource, err := webhooksResources.DeleteWebhookResource()
+	if err != nil {
+		return nil, err
+	}
+
+	getWebhookDeliveriesResource, err := webhooksResources.GetWebhookDeliveriesResource()
+	if err != nil {
+		return nil, err
+	}
+
+	redeliverWebhookResource, err := webhooksResources.RedeliverWebhookResource()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedWebhooksResources{
+		CreateWebhook:        createWebhookResource,
+		GetWebhooks:          getWebhooksResource,
+		GetWebhook:           getWebhookResource,
+		UpdateWebhook:        updateWebhookResource,
+		DeleteWebhook:        deleteWebhookResource,
+		GetWebhookDeliveries: getWebhookDeliveriesResource,
+		RedeliverWebhook:     redeliverWebhookResource,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package webhooks_features
+
+import (
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Webhook is the representation of a webhook endpoint returned by the API. An
+// endpoint with no events is subscribed to all of them, and DisabledReason is
+// set when it was disabled after too many failed deliveries.
+type Webhook struct {
+	ID                  string             `json:"id"`
+	Url                 string             `json:"url"`
+	Events              []string           `json:"events"`
+	Description         string             `json:"description"`
+	Enabled             bool               `json:"enabled"`
+	DisabledReason      pgtype.Text        `json:"disabled_reason"`
+	ConsecutiveFailures int32              `json:"consecutive_failures"`
+	CreatedBy           pgtype.Text        `json:"created_by"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook endpoint.
+// Redeliveries share the EventID of the original delivery.
+type WebhookDelivery struct {
+	ID             string             `json:"id"`
+	EventID        string             `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	Payload        json.RawMessage    `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	LastAttemptAt  pgtype.Timestamptz `json:"last_attempt_at"`
+	ResponseStatus pgtype.Int4        `json:"response_status"`
+	ResponseBody   string             `json:"response_body"`
+	Error          string             `json:"error"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type CreateWebhookRequest struct {
+	Url         string   `json:"url" validate:"required,url,max=2048"`
+	Events      []string `json:"events"`
+	Description string   `json:"description" validate:"max=500"`
+}
+
+// CreateWebhookResponse carries the only copy of the secret deliveries are
+// signed with.
+type CreateWebhookResponse struct {
+	Message string  `json:"message"`
+	Secret  string  `json:"secret"`
+	Webhook Webhook `json:"webhook"`
+}
+
+type GetWebhooksRequest struct{}
+
+type GetWebhooksResponse struct {
+	Message  string    `json:"message"`
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type GetWebhookRequest struct{}
+
+type GetWebhookResponse struct {
+	Message string  `json:"message"`
+	Webhook Webhook `json:"webhook"`
+}
+
+type UpdateWebhookRequest struct {
+	Url         *string   `json:"url,omitempty" validate:"required,url,max=2048"`
+	Events      *[]string `json:"events,omitempty"`
+	Description *string   `json:"description,omitempty" validate:"max=500"`
+	Enabled     *bool     `json:"enabled,omitempty"`
+}
+
+type UpdateWebhookResponse struct {
+	Message string  `json:"message"`
+	Webhook Webhook `json:"webhook"`
+}
+
+type DeleteWebhookRequest struct{}
+
+type DeleteWebhookResponse struct {
+	Message string `json:"message"`
+}
+
+type GetWebhookDeliveriesRequest struct{}
+
+type GetWebhookDeliveriesResponse struct {
+	Message    string            `json:"message"`
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+type RedeliverWebhookRequest struct{}
+
+type RedeliverWebhookResponse struct {
+	Message  string          `json:"message"`
+	Delivery WebhookDelivery `json:"delivery"`
+}
@@ -0,0 +1,365 @@
+package webhooks_features
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/matcha/email"
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/audit"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/session"
+	"github.com/abyanmajid/thorfinn/internal/webhooks"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type WebhooksHandlers struct {
+	isDev         bool
+	config        *internal.EnvConfig
+	queries       *database.Queries
+	mailer        *email.Client
+	authenticator *session.Authenticator
+	auditor       *audit.Auditor
+	dispatcher    *webhooks.Dispatcher
+}
+
+func NewHandlers(isDev bool, config *internal.EnvConfig, queries *database.Queries, mailer *email.Client) *WebhooksHandlers {
+	return &WebhooksHandlers{
+		isDev:         isDev,
+		config:        config,
+		queries:       queries,
+		mailer:        mailer,
+		authenticator: session.NewAuthenticator(config, queries),
+		auditor:       audit.NewAuditor(config, queries),
+		dispatcher:    webhooks.NewDispatcher(config, queries),
+	}
+}
+
+func (h *WebhooksHandlers) CreateWebhook(c *ctx.Request[CreateWebhookRequest]) *ctx.Response[CreateWebhookResponse] {
+	logger.Info("Invoked: CreateWebhook")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[CreateWebhookResponse](err)
+	}
+
+	err = webhooks.ValidateEvents(c.Body.Events)
+	if err != nil {
+		logger.Error("Error validating events: %v", err)
+		return internal.ApiError[CreateWebhookResponse](err)
+	}
+
+	events := c.Body.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	logger.Debug("Generating webhook secret")
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		logger.Error("Error generating webhook secret: %v", err)
+		return internal.GenericError[CreateWebhookResponse]()
+	}
+
+	logger.Debug("Creating webhook endpoint")
+	endpoint, err := h.queries.CreateWebhookEndpoint(c.Request.Context(), database.CreateWebhookEndpointParams{
+		ID:          uuid.New().String(),
+		Url:         c.Body.Url,
+		Secret:      secret,
+		Events:      events,
+		Description: c.Body.Description,
+		CreatedBy:   pgtype.Text{String: principal.User.ID, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Error creating webhook endpoint: %v", err)
+		return internal.GenericError[CreateWebhookResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionWebhookCreated,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"webhook_id": endpoint.ID, "url": endpoint.Url, "events": endpoint.Events},
+	})
+
+	return &ctx.Response[CreateWebhookResponse]{
+		Response: CreateWebhookResponse{
+			Message: "Successfully created webhook",
+			Secret:  secret,
+			Webhook: newWebhook(endpoint),
+		},
+		StatusCode: http.StatusCreated,
+		Error:      nil,
+	}
+}
+
+func (h *WebhooksHandlers) GetWebhooks(c *ctx.Request[GetWebhooksRequest]) *ctx.Response[GetWebhooksResponse] {
+	logger.Info("Invoked: GetWebhooks")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetWebhooksResponse](err)
+	}
+
+	logger.Debug("Fetching webhook endpoints")
+	endpoints, err := h.queries.ListWebhookEndpoints(c.Request.Context())
+	if err != nil {
+		logger.Error("Error getting webhook endpoints: %v", err)
+		return internal.GenericError[GetWebhooksResponse]()
+	}
+
+	result := []Webhook{}
+	for _, endpoint := range endpoints {
+		result = append(result, newWebhook(endpoint))
+	}
+
+	return &ctx.Response[GetWebhooksResponse]{
+		Response: GetWebhooksResponse{
+			Message:  "Successfully fetched webhooks",
+			Webhooks: result,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *WebhooksHandlers) GetWebhook(c *ctx.Request[GetWebhookRequest]) *ctx.Response[GetWebhookResponse] {
+	logger.Info("Invoked: GetWebhook")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetWebhookResponse](err)
+	}
+
+	logger.Debug("Finding webhook endpoint by id")
+	endpoint, err := h.queries.FindWebhookEndpointById(c.Request.Context(), c.GetPathParam("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Webhook endpoint not found")
+		return internal.ApiError[GetWebhookResponse](webhooks.ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding webhook endpoint by id: %v", err)
+		return internal.GenericError[GetWebhookResponse]()
+	}
+
+	return &ctx.Response[GetWebhookResponse]{
+		Response: GetWebhookResponse{
+			Message: "Successfully fetched webhook",
+			Webhook: newWebhook(endpoint),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *WebhooksHandlers) UpdateWebhook(c *ctx.Request[UpdateWebhookRequest]) *ctx.Response[UpdateWebhookResponse] {
+	logger.Info("Invoked: UpdateWebhook")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[UpdateWebhookResponse](err)
+	}
+
+	logger.Debug("Finding webhook endpoint by id")
+	endpoint, err := h.queries.FindWebhookEndpointById(c.Request.Context(), c.GetPathParam("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Webhook endpoint not found")
+		return internal.ApiError[UpdateWebhookResponse](webhooks.ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding webhook endpoint by id: %v", err)
+		return internal.GenericError[UpdateWebhookResponse]()
+	}
+
+	params := database.UpdateWebhookEndpointParams{
+		ID:          endpoint.ID,
+		Url:         endpoint.Url,
+		Events:      endpoint.Events,
+		Description: endpoint.Description,
+		Enabled:     endpoint.Enabled,
+	}
+	if c.Body.Url != nil {
+		params.Url = *c.Body.Url
+	}
+	if c.Body.Events != nil {
+		err = webhooks.ValidateEvents(*c.Body.Events)
+		if err != nil {
+			logger.Error("Error validating events: %v", err)
+			return internal.ApiError[UpdateWebhookResponse](err)
+		}
+
+		params.Events = *c.Body.Events
+		if params.Events == nil {
+			params.Events = []string{}
+		}
+	}
+	if c.Body.Description != nil {
+		params.Description = *c.Body.Description
+	}
+	if c.Body.Enabled != nil {
+		params.Enabled = *c.Body.Enabled
+	}
+
+	logger.Debug("Updating webhook endpoint")
+	updated, err := h.queries.UpdateWebhookEndpoint(c.Request.Context(), params)
+	if err != nil {
+		logger.Error("Error updating webhook endpoint: %v", err)
+		return internal.GenericError[UpdateWebhookResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionWebhookUpdated,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"webhook_id": updated.ID, "url": updated.Url, "events": updated.Events, "enabled": updated.Enabled},
+	})
+
+	return &ctx.Response[UpdateWebhookResponse]{
+		Response: UpdateWebhookResponse{
+			Message: "Successfully updated webhook",
+			Webhook: newWebhook(updated),
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *WebhooksHandlers) DeleteWebhook(c *ctx.Request[DeleteWebhookRequest]) *ctx.Response[DeleteWebhookResponse] {
+	logger.Info("Invoked: DeleteWebhook")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[DeleteWebhookResponse](err)
+	}
+
+	logger.Debug("Finding webhook endpoint by id")
+	endpoint, err := h.queries.FindWebhookEndpointById(c.Request.Context(), c.GetPathParam("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Webhook endpoint not found")
+		return internal.ApiError[DeleteWebhookResponse](webhooks.ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding webhook endpoint by id: %v", err)
+		return internal.GenericError[DeleteWebhookResponse]()
+	}
+
+	logger.Debug("Deleting webhook endpoint")
+	err = h.queries.DeleteWebhookEndpoint(c.Request.Context(), endpoint.ID)
+	if err != nil {
+		logger.Error("Error deleting webhook endpoint: %v", err)
+		return internal.GenericError[DeleteWebhookResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionWebhookDeleted,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"webhook_id": endpoint.ID, "url": endpoint.Url},
+	})
+
+	return &ctx.Response[DeleteWebhookResponse]{
+		Response: DeleteWebhookResponse{
+			Message: "Successfully deleted webhook",
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *WebhooksHandlers) GetWebhookDeliveries(c *ctx.Request[GetWebhookDeliveriesRequest]) *ctx.Response[GetWebhookDeliveriesResponse] {
+	logger.Info("Invoked: GetWebhookDeliveries")
+
+	_, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[GetWebhookDeliveriesResponse](err)
+	}
+
+	logger.Debug("Finding webhook endpoint by id")
+	endpoint, err := h.queries.FindWebhookEndpointById(c.Request.Context(), c.GetPathParam("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Webhook endpoint not found")
+		return internal.ApiError[GetWebhookDeliveriesResponse](webhooks.ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding webhook endpoint by id: %v", err)
+		return internal.GenericError[GetWebhookDeliveriesResponse]()
+	}
+
+	logger.Debug("Parsing query parameters")
+	params, err := parseListDeliveriesQuery(c, endpoint.ID)
+	if err != nil {
+		logger.Error("Error parsing query parameters: %v", err)
+		return internal.ApiError[GetWebhookDeliveriesResponse](err)
+	}
+
+	logger.Debug("Fetching webhook deliveries")
+	deliveries, err := h.queries.ListWebhookDeliveries(c.Request.Context(), params)
+	if err != nil {
+		logger.Error("Error getting webhook deliveries: %v", err)
+		return internal.GenericError[GetWebhookDeliveriesResponse]()
+	}
+
+	result := []WebhookDelivery{}
+	for _, delivery := range deliveries {
+		result = append(result, newWebhookDelivery(delivery))
+	}
+
+	return &ctx.Response[GetWebhookDeliveriesResponse]{
+		Response: GetWebhookDeliveriesResponse{
+			Message:    "Successfully fetched webhook deliveries",
+			Deliveries: result,
+		},
+		StatusCode: http.StatusOK,
+		Error:      nil,
+	}
+}
+
+func (h *WebhooksHandlers) RedeliverWebhook(c *ctx.Request[RedeliverWebhookRequest]) *ctx.Response[RedeliverWebhookResponse] {
+	logger.Info("Invoked: RedeliverWebhook")
+
+	principal, err := h.authenticator.Authorize(c.Request, session.RoleAdmin)
+	if err != nil {
+		logger.Error("Error authorizing request: %v", err)
+		return internal.ApiError[RedeliverWebhookResponse](err)
+	}
+
+	logger.Debug("Finding webhook delivery by id")
+	delivery, err := h.queries.FindWebhookDeliveryById(c.Request.Context(), c.GetPathParam("delivery_id"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && delivery.EndpointID != c.GetPathParam("id")) {
+		logger.Error("Webhook delivery not found")
+		return internal.ApiError[RedeliverWebhookResponse](webhooks.ErrDeliveryNotFound)
+	}
+	if err != nil {
+		logger.Error("Error finding webhook delivery by id: %v", err)
+		return internal.GenericError[RedeliverWebhookResponse]()
+	}
+
+	logger.Debug("Queueing webhook delivery again")
+	redelivery, err := h.dispatcher.Redeliver(c.Request.Context(), delivery)
+	if err != nil {
+		logger.Error("Error queueing webhook delivery again: %v", err)
+		return internal.GenericError[RedeliverWebhookResponse]()
+	}
+
+	h.auditor.Record(c.Request, audit.Event{
+		Action:   audit.ActionWebhookRedelivered,
+		Actor:    principal.Actor(),
+		Metadata: map[string]any{"webhook_id": delivery.EndpointID, "delivery_id": delivery.ID, "redelivery_id": redelivery.ID},
+	})
+
+	return &ctx.Response[RedeliverWebhookResponse]{
+		Response: RedeliverWebhookResponse{
+			Message:  "Successfully queued webhook delivery again",
+			Delivery: newWebhookDelivery(redelivery),
+		},
+		StatusCode: http.StatusAccepted,
+		Error:      nil,
+	}
+}
@@ -0,0 +1,84 @@
+package webhooks_features
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/webhooks"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultDeliveriesLimit = 20
+	maxDeliveriesLimit     = 100
+)
+
+// parseListDeliveriesQuery reads the filters of a request for the deliveries
+// to endpointId, collecting every invalid parameter into a single validation
+// error.
+func parseListDeliveriesQuery(c *ctx.Request[GetWebhookDeliveriesRequest], endpointId string) (database.ListWebhookDeliveriesParams, error) {
+	fields := map[string][]string{}
+	params := database.ListWebhookDeliveriesParams{
+		EndpointID: endpointId,
+		Limit:      defaultDeliveriesLimit,
+	}
+
+	if limit := c.GetQueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxDeliveriesLimit {
+			fields["limit"] = []string{fmt.Sprintf("Must be an integer between 1 and %d", maxDeliveriesLimit)}
+		}
+		params.Limit = int32(n)
+	}
+
+	if status := c.GetQueryParam("status"); status != "" {
+		if !slices.Contains(webhooks.Statuses, status) {
+			fields["status"] = []string{fmt.Sprintf("Must be one of %s", strings.Join(webhooks.Statuses, ", "))}
+		}
+		params.Status = pgtype.Text{String: status, Valid: true}
+	}
+
+	if len(fields) > 0 {
+		return params, apierror.Validation(fields)
+	}
+
+	return params, nil
+}
+
+func newWebhook(endpoint database.ThorfinnWebhookEndpoint) Webhook {
+	return Webhook{
+		ID:                  endpoint.ID,
+		Url:                 endpoint.Url,
+		Events:              endpoint.Events,
+		Description:         endpoint.Description,
+		Enabled:             endpoint.Enabled,
+		DisabledReason:      endpoint.DisabledReason,
+		ConsecutiveFailures: endpoint.ConsecutiveFailures,
+		CreatedBy:           endpoint.CreatedBy,
+		CreatedAt:           endpoint.CreatedAt,
+		UpdatedAt:           endpoint.UpdatedAt,
+	}
+}
+
+func newWebhookDelivery(delivery database.ThorfinnWebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        json.RawMessage(delivery.Payload),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
@@ -0,0 +1,247 @@
+package webhooks_features
+
+import (
+	"net/http"
+
+	"github.com/abyanmajid/matcha/openapi"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+)
+
+type WebhooksResources struct {
+	handlers *WebhooksHandlers
+}
+
+func NewWebhooksResources(handlers *WebhooksHandlers) *WebhooksResources {
+	return &WebhooksResources{
+		handlers: handlers,
+	}
+}
+
+func (r *WebhooksResources) CreateWebhookResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(CreateWebhookRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(CreateWebhookResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Create webhook",
+		Description: "Create a webhook endpoint subscribed to events, or to all of them if events is empty. The secret deliveries are signed with is only returned once. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "Successfully created webhook",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("CreateWebhook", doc, r.handlers.CreateWebhook)
+
+	return &resource, nil
+}
+
+func (r *WebhooksResources) GetWebhooksResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetWebhooksRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetWebhooksResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get webhooks",
+		Description: "Get every webhook endpoint, newest first. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched webhooks",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden),
+		},
+	}
+
+	resource := internal.NewResource("GetWebhooks", doc, r.handlers.GetWebhooks)
+
+	return &resource, nil
+}
+
+func (r *WebhooksResources) GetWebhookResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetWebhookRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetWebhookResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get webhook",
+		Description: "Get a webhook endpoint by id. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched webhook",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.WebhookNotFound),
+		},
+	}
+
+	resource := internal.NewResource("GetWebhook", doc, r.handlers.GetWebhook)
+
+	return &resource, nil
+}
+
+func (r *WebhooksResources) UpdateWebhookResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(UpdateWebhookRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(UpdateWebhookResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Update webhook",
+		Description: "Update the fields of a webhook endpoint that are set. Enabling an endpoint that was disabled after failed deliveries resets its failures, and its pending deliveries are sent again. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully updated webhook",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.InvalidRequest, apierror.ValidationFailed, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.WebhookNotFound),
+		},
+	}
+
+	resource := internal.NewResource("UpdateWebhook", doc, r.handlers.UpdateWebhook)
+
+	return &resource, nil
+}
+
+func (r *WebhooksResources) DeleteWebhookResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(DeleteWebhookRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(DeleteWebhookResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Delete webhook",
+		Description: "Delete a webhook endpoint and its deliveries. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully deleted webhook",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.WebhookNotFound),
+		},
+	}
+
+	resource := internal.NewResource("DeleteWebhook", doc, r.handlers.DeleteWebhook)
+
+	return &resource, nil
+}
+
+func (r *WebhooksResources) GetWebhookDeliveriesResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(GetWebhookDeliveriesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(GetWebhookDeliveriesResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Get webhook deliveries",
+		Description: "Get the latest deliveries to a webhook endpoint, newest first, with the outcome of their last attempt. Requires the admin role",
+		Schema: openapi.Schema{
+			Parameters: []openapi.Parameter{
+				{In: "query", Name: "limit", Description: "The maximum number of deliveries to return, between 1 and 100. Defaults to 20"},
+				{In: "query", Name: "status", Description: "Only return deliveries with this status: pending, succeeded or failed"},
+			},
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "Successfully fetched webhook deliveries",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.ValidationFailed, apierror.WebhookNotFound),
+		},
+	}
+
+	resource := internal.NewResource("GetWebhookDeliveries", doc, r.handlers.GetWebhookDeliveries)
+
+	return &resource, nil
+}
+
+func (r *WebhooksResources) RedeliverWebhookResource() (*openapi.Resource, error) {
+	requestSchema, err := openapi.NewSchema(RedeliverWebhookRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	responseSchema, err := openapi.NewSchema(RedeliverWebhookResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := openapi.ResourceDoc{
+		Summary:     "Redeliver webhook",
+		Description: "Queue a delivery to be sent again, as a new delivery with the same event id and payload. Requires the admin role",
+		Schema: openapi.Schema{
+			RequestBody: openapi.RequestBody{
+				Content: openapi.Json(requestSchema),
+			},
+			Responses: apierror.Document(map[int]openapi.Response{
+				http.StatusAccepted: {
+					Description: "Successfully queued webhook delivery again",
+					Content:     openapi.Json(responseSchema),
+				},
+			}, apierror.Unauthenticated, apierror.SessionRevoked, apierror.AccountSuspended, apierror.AccountDeleted, apierror.Forbidden, apierror.WebhookDeliveryNotFound),
+		},
+	}
+
+	resource := internal.NewResource("RedeliverWebhook", doc, r.handlers.RedeliverWebhook)
+
+	return &resource, nil
+}
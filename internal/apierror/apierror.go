@@ -0,0 +1,197 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/abyanmajid/matcha/openapi"
+)
+
+// Code is a stable, machine-readable identifier for an error. Clients should
+// branch on the code rather than on the message, which may change.
+type Code string
+
+const (
+	Internal                Code = "internal_error"
+	InvalidRequest          Code = "invalid_request"
+	ValidationFailed        Code = "validation_failed"
+	Unauthenticated         Code = "unauthenticated"
+	SessionRevoked          Code = "session_revoked"
+	Forbidden               Code = "forbidden"
+	InvalidCredentials      Code = "invalid_credentials"
+	EmailNotVerified        Code = "email_not_verified"
+	ApprovalPending         Code = "approval_pending"
+	AccountSuspended        Code = "account_suspended"
+	AccountDeleted          Code = "account_deleted"
+	IncorrectPassword       Code = "incorrect_password"
+	InvalidToken            Code = "invalid_token"
+	TokenRevoked            Code = "token_revoked"
+	OtpInvalid              Code = "otp_invalid"
+	OtpExpired              Code = "otp_expired"
+	UserNotFound            Code = "user_not_found"
+	EmailTaken              Code = "email_taken"
+	EmailChangeInvalid      Code = "email_change_invalid"
+	AccountStatusConflict   Code = "account_status_conflict"
+	PreconditionFailed      Code = "precondition_failed"
+	PreconditionRequired    Code = "precondition_required"
+	EmailUnchanged          Code = "email_unchanged"
+	PasswordReused          Code = "password_reused"
+	NotificationNotOptional Code = "notification_not_optional"
+	OrganizationNotFound    Code = "organization_not_found"
+	SlugTaken               Code = "slug_taken"
+	MemberNotFound          Code = "member_not_found"
+	AlreadyMember           Code = "already_member"
+	LastOwner               Code = "last_owner"
+	InvitationNotFound      Code = "invitation_not_found"
+	RegistrationClosed      Code = "registration_closed"
+	InvitationRequired      Code = "invitation_required"
+	EmailDomainNotAllowed   Code = "email_domain_not_allowed"
+	DisposableEmail         Code = "disposable_email"
+	ApiKeyNotFound          Code = "api_key_not_found"
+	RelationTupleNotFound   Code = "relation_tuple_not_found"
+	ImpersonationNotAllowed Code = "impersonation_not_allowed"
+	WebhookNotFound         Code = "webhook_not_found"
+	WebhookDeliveryNotFound Code = "webhook_delivery_not_found"
+	HookRejected            Code = "hook_rejected"
+)
+
+var statuses = map[Code]int{
+	Internal:                http.StatusInternalServerError,
+	InvalidRequest:          http.StatusBadRequest,
+	ValidationFailed:        http.StatusUnprocessableEntity,
+	Unauthenticated:         http.StatusUnauthorized,
+	SessionRevoked:          http.StatusUnauthorized,
+	Forbidden:               http.StatusForbidden,
+	InvalidCredentials:      http.StatusUnauthorized,
+	EmailNotVerified:        http.StatusForbidden,
+	ApprovalPending:         http.StatusForbidden,
+	AccountSuspended:        http.StatusForbidden,
+	AccountDeleted:          http.StatusForbidden,
+	IncorrectPassword:       http.StatusForbidden,
+	InvalidToken:            http.StatusBadRequest,
+	TokenRevoked:            http.StatusBadRequest,
+	OtpInvalid:              http.StatusUnauthorized,
+	OtpExpired:              http.StatusUnauthorized,
+	UserNotFound:            http.StatusNotFound,
+	EmailTaken:              http.StatusConflict,
+	EmailChangeInvalid:      http.StatusConflict,
+	AccountStatusConflict:   http.StatusConflict,
+	PreconditionFailed:      http.StatusPreconditionFailed,
+	PreconditionRequired:    http.StatusPreconditionRequired,
+	EmailUnchanged:          http.StatusUnprocessableEntity,
+	PasswordReused:          http.StatusUnprocessableEntity,
+	NotificationNotOptional: http.StatusUnprocessableEntity,
+	OrganizationNotFound:    http.StatusNotFound,
+	SlugTaken:               http.StatusConflict,
+	MemberNotFound:          http.StatusNotFound,
+	AlreadyMember:           http.StatusConflict,
+	LastOwner:               http.StatusConflict,
+	InvitationNotFound:      http.StatusNotFound,
+	RegistrationClosed:      http.StatusForbidden,
+	InvitationRequired:      http.StatusForbidden,
+	EmailDomainNotAllowed:   http.StatusForbidden,
+	DisposableEmail:         http.StatusForbidden,
+	ApiKeyNotFound:          http.StatusNotFound,
+	RelationTupleNotFound:   http.StatusNotFound,
+	ImpersonationNotAllowed: http.StatusForbidden,
+	WebhookNotFound:         http.StatusNotFound,
+	WebhookDeliveryNotFound: http.StatusNotFound,
+	HookRejected:            http.StatusForbidden,
+}
+
+type Error struct {
+	Code    Code
+	Message string
+	Fields  map[string][]string
+}
+
+func New(code Code, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Validation returns a validation_failed error listing the failures of every
+// invalid field of a request body.
+func Validation(fields map[string][]string) *Error {
+	return &Error{
+		Code:    ValidationFailed,
+		Message: "request body is invalid",
+		Fields:  fields,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Status() int {
+	status, ok := statuses[e.Code]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	return status
+}
+
+// Envelope is the JSON body of every error response.
+type Envelope struct {
+	Error  string              `json:"error"`
+	Code   Code                `json:"code"`
+	Fields map[string][]string `json:"fields,omitempty"`
+}
+
+func (e *Error) Envelope() Envelope {
+	return Envelope{
+		Error:  e.Message,
+		Code:   e.Code,
+		Fields: e.Fields,
+	}
+}
+
+// From returns err if it is an *Error, and a generic internal error otherwise,
+// so that unexpected errors never leak their message to the client.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	return New(Internal, "an error occurred while processing your request")
+}
+
+func envelopeSchema() *openapi.ContentSchema {
+	return &openapi.ContentSchema{
+		Type: "object",
+		Properties: map[string]*openapi.ContentSchema{
+			"error":  {Type: "string"},
+			"code":   {Type: "string"},
+			"fields": {Type: "object"},
+		},
+	}
+}
+
+// Document adds a response for every status the given codes map to, plus the
+// internal error response every endpoint can return, to the documented
+// responses of an endpoint.
+func Document(responses map[int]openapi.Response, codes ...Code) map[int]openapi.Response {
+	codesByStatus := map[int][]string{}
+	for _, code := range append(codes, Internal) {
+		status := New(code, "").Status()
+		codesByStatus[status] = append(codesByStatus[status], string(code))
+	}
+
+	for status, statusCodes := range codesByStatus {
+		sort.Strings(statusCodes)
+
+		responses[status] = openapi.Response{
+			Description: "Error with code " + strings.Join(statusCodes, ", "),
+			Content:     openapi.Json(envelopeSchema()),
+		}
+	}
+
+	return responses
+}
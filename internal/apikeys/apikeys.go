@@ -0,0 +1,155 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+)
+
+// Scopes an API key can be granted. Each grants reading (GET requests, and
+// permission checks) or writing (every other method) the endpoints under one
+// path: /me, /users, /organizations, /invitations, /authz, /webhooks, or
+// /audit-events, which can only be read. API keys can never use the /auth endpoints, nor
+// manage API keys, whatever their scopes.
+const (
+	ScopeMeRead             = "me:read"
+	ScopeMeWrite            = "me:write"
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopeOrganizationsRead  = "organizations:read"
+	ScopeOrganizationsWrite = "organizations:write"
+	ScopeInvitationsRead    = "invitations:read"
+	ScopeInvitationsWrite   = "invitations:write"
+	ScopeAuthzRead          = "authz:read"
+	ScopeAuthzWrite         = "authz:write"
+	ScopeWebhooksRead       = "webhooks:read"
+	ScopeWebhooksWrite      = "webhooks:write"
+	ScopeAuditEventsRead    = "audit-events:read"
+)
+
+const (
+	secretLength          = 32
+	displayedSecretLength = 6
+
+	managementSegment    = "api-keys"
+	organizationsSegment = "organizations"
+)
+
+var Scopes = []string{
+	ScopeMeRead, ScopeMeWrite,
+	ScopeUsersRead, ScopeUsersWrite,
+	ScopeOrganizationsRead, ScopeOrganizationsWrite,
+	ScopeInvitationsRead, ScopeInvitationsWrite,
+	ScopeAuthzRead, ScopeAuthzWrite,
+	ScopeWebhooksRead, ScopeWebhooksWrite,
+	ScopeAuditEventsRead,
+}
+
+// readPaths are endpoints that take their input as a POST body but only read,
+// so that checking permissions only needs a read scope.
+var readPaths = []string{"authz/check", "authz/check/batch", "authz/expand"}
+
+var (
+	ErrInvalid           = apierror.New(apierror.Unauthenticated, "API key is invalid, expired or revoked")
+	ErrNotAllowed        = apierror.New(apierror.Forbidden, "API keys can't be used for this endpoint")
+	ErrMissingScope      = apierror.New(apierror.Forbidden, "API key is missing the scope required by this endpoint")
+	ErrWrongOrganization = apierror.New(apierror.Forbidden, "organization API keys can only be used for their organization")
+	ErrNotFound          = apierror.New(apierror.ApiKeyNotFound, "API key not found")
+)
+
+// Generate returns a new API key, the prefix under which it is displayed once
+// created, and the hash under which it is stored. Keys start with the
+// configured prefix, so that they are easy to recognize, for instance by
+// secret scanners.
+func Generate(keyPrefix string) (key string, displayPrefix string, hash string, err error) {
+	bytes := make([]byte, secretLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", "", err
+	}
+
+	key = fmt.Sprintf("%s_%s", keyPrefix, base64.RawURLEncoding.EncodeToString(bytes))
+
+	return key, key[:len(keyPrefix)+1+displayedSecretLength], Hash(key), nil
+}
+
+func Hash(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// IsKey reports whether token looks like an API key rather than an access
+// token.
+func IsKey(token string, keyPrefix string) bool {
+	return strings.HasPrefix(token, keyPrefix+"_")
+}
+
+// Validate returns a validation error if any of scopes is unknown, or if
+// expiresAt is set but isn't in the future.
+func Validate(scopes []string, expiresAt *time.Time) error {
+	fields := map[string][]string{}
+
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			fields["scopes"] = []string{fmt.Sprintf("Must only contain %s", strings.Join(Scopes, ", "))}
+			break
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		fields["expires_at"] = []string{"Must be in the future"}
+	}
+
+	if len(fields) > 0 {
+		return apierror.Validation(fields)
+	}
+
+	return nil
+}
+
+// RequiredScope returns the scope an API key needs to be used for r, or an
+// empty string if API keys can't be used for it.
+func RequiredScope(r *http.Request) string {
+	segments := pathSegments(r)
+	if len(segments) == 0 || slices.Contains(segments, managementSegment) {
+		return ""
+	}
+
+	access := "write"
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || slices.Contains(readPaths, strings.Join(segments, "/")) {
+		access = "read"
+	}
+
+	scope := segments[0] + ":" + access
+	if !slices.Contains(Scopes, scope) {
+		return ""
+	}
+
+	return scope
+}
+
+// OrganizationID returns the id of the organization r is about, if any.
+func OrganizationID(r *http.Request) string {
+	segments := pathSegments(r)
+	if len(segments) < 2 || segments[0] != organizationsSegment {
+		return ""
+	}
+
+	return segments[1]
+}
+
+func pathSegments(r *http.Request) []string {
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
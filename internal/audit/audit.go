@@ -0,0 +1,333 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Actions recorded in the audit log, named after what they are about and what
+// happened to it.
+const (
+	ActionUserRegistered           = "user.registered"
+	ActionUserLoggedIn             = "user.logged_in"
+	ActionUserLoggedOut            = "user.logged_out"
+	ActionEmailVerificationSent    = "user.email_verification_sent"
+	ActionEmailVerified            = "user.email_verified"
+	ActionPasswordResetRequested   = "user.password_reset_requested"
+	ActionPasswordReset            = "user.password_reset"
+	ActionPasswordChanged          = "user.password_changed"
+	ActionEmailChangeRequested     = "user.email_change_requested"
+	ActionEmailChanged             = "user.email_changed"
+	ActionEmailChangeReverted      = "user.email_change_reverted"
+	ActionNotificationsUpdated     = "user.notifications_updated"
+	ActionOtpSent                  = "user.otp_sent"
+	ActionOtpVerified              = "user.otp_verified"
+	ActionOrganizationSwitched     = "user.organization_switched"
+	ActionInvitationAccepted       = "user.invitation_accepted"
+	ActionImpersonationStarted     = "user.impersonation_started"
+	ActionImpersonatedRequest      = "user.impersonated_request"
+	ActionProfileUpdated           = "user.profile_updated"
+	ActionAccountDeletionRequested = "user.deletion_requested"
+	ActionDataExported             = "user.data_exported"
+	ActionUserUpdated              = "user.updated"
+	ActionUserApproved             = "user.approved"
+	ActionUserSuspended            = "user.suspended"
+	ActionUserRestored             = "user.restored"
+	ActionUserDeleted              = "user.deleted"
+	ActionUserPurged               = "user.purged"
+	ActionInvitationCreated        = "invitation.created"
+	ActionInvitationRevoked        = "invitation.revoked"
+	ActionApiKeyCreated            = "api_key.created"
+	ActionApiKeyRevoked            = "api_key.revoked"
+	ActionOrganizationCreated      = "organization.created"
+	ActionOrganizationUpdated      = "organization.updated"
+	ActionOrganizationDeleted      = "organization.deleted"
+	ActionMemberUpdated            = "organization.member_updated"
+	ActionMemberRemoved            = "organization.member_removed"
+	ActionRelationTupleCreated     = "relation_tuple.created"
+	ActionRelationTupleDeleted     = "relation_tuple.deleted"
+	ActionWebhookCreated           = "webhook.created"
+	ActionWebhookUpdated           = "webhook.updated"
+	ActionWebhookDeleted           = "webhook.deleted"
+	ActionWebhookRedelivered       = "webhook.redelivered"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+var Outcomes = []string{OutcomeSuccess, OutcomeFailure}
+
+const (
+	requestIdHeader   = "X-Request-Id"
+	verificationBatch = 500
+)
+
+// Actor is who performed an action: the signed-in user, the impersonator
+// acting as them, if any, and the API key they used, if any.
+type Actor struct {
+	UserID         string
+	ImpersonatorID string
+	ApiKeyID       string
+}
+
+// Event is an action to record. Outcome defaults to success, and Actor and
+// TargetUserID are left empty when there is no such user, for instance when
+// someone fails to log in to an account that doesn't exist.
+type Event struct {
+	Action       string
+	Outcome      string
+	Actor        Actor
+	TargetUserID string
+	Metadata     map[string]any
+}
+
+// Verification is the result of checking the hash chain. BrokenAt is the id
+// of the first event whose hash or link doesn't match, or 0 if every event
+// checked out.
+type Verification struct {
+	Checked  int
+	BrokenAt int64
+}
+
+// Auditor appends events to the audit log. When AUDIT_HASH_CHAIN is enabled,
+// each event stores the hash of the previous one and its own hash over both,
+// so that editing or removing an event breaks the chain.
+type Auditor struct {
+	config  *internal.EnvConfig
+	queries *database.Queries
+}
+
+func NewAuditor(config *internal.EnvConfig, queries *database.Queries) *Auditor {
+	return &Auditor{
+		config:  config,
+		queries: queries,
+	}
+}
+
+// Record appends event, performed in request r, to the audit log. Failing to
+// record an event is logged rather than returned, so that it never fails the
+// action itself.
+func (a *Auditor) Record(r *http.Request, event Event) {
+	a.record(r.Context(), event, internal.ClientIp(a.config, r), r.UserAgent(), RequestID(r))
+}
+
+// RecordSystem appends event, performed by Thorfinn itself rather than in a
+// request, to the audit log.
+func (a *Auditor) RecordSystem(ctx context.Context, event Event) {
+	a.record(ctx, event, "", "", "")
+}
+
+func (a *Auditor) record(ctx context.Context, event Event, ipAddress string, userAgent string, requestId string) {
+	if event.Outcome == "" {
+		event.Outcome = OutcomeSuccess
+	}
+
+	metadata := map[string]any{}
+	for key, value := range event.Metadata {
+		metadata[key] = value
+	}
+	if event.Actor.ApiKeyID != "" {
+		metadata["api_key_id"] = event.Actor.ApiKeyID
+	}
+
+	encodedMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		logger.Error("Error encoding metadata of audit event %s: %v", event.Action, err)
+		return
+	}
+
+	params := database.CreateAuditEventParams{
+		ActorID:        optionalText(event.Actor.UserID),
+		ImpersonatorID: optionalText(event.Actor.ImpersonatorID),
+		TargetUserID:   optionalText(event.TargetUserID),
+		Action:         event.Action,
+		Outcome:        event.Outcome,
+		IpAddress:      ipAddress,
+		UserAgent:      userAgent,
+		RequestID:      requestId,
+		Metadata:       encodedMetadata,
+		CreatedAt:      pgtype.Timestamptz{Time: time.Now().UTC().Truncate(time.Microsecond), Valid: true},
+	}
+
+	if a.config.AuditHashChain {
+		err = a.appendToChain(ctx, params)
+	} else {
+		err = a.queries.CreateAuditEvent(ctx, params)
+	}
+	if err != nil {
+		logger.Error("Error recording audit event %s: %v", event.Action, err)
+	}
+}
+
+// appendToChain links params to the latest event of the chain. It runs in a
+// transaction holding a lock on the end of the chain, so that concurrent
+// appends, from this process or others, each link to the one before.
+func (a *Auditor) appendToChain(ctx context.Context, params database.CreateAuditEventParams) error {
+	return a.queries.InTx(ctx, func(queries *database.Queries) error {
+		err := queries.LockAuditChain(ctx)
+		if err != nil {
+			return err
+		}
+
+		previousHash, err := queries.FindLatestAuditHash(ctx)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		params.PreviousHash = pgtype.Text{String: previousHash.String, Valid: true}
+
+		hash, err := chainHash(params)
+		if err != nil {
+			return err
+		}
+		params.Hash = pgtype.Text{String: hash, Valid: true}
+
+		return queries.CreateAuditEvent(ctx, params)
+	})
+}
+
+// Verify recomputes the hash chain, from the oldest event still retained to
+// the latest, and reports the first event that doesn't match.
+func (a *Auditor) Verify(ctx context.Context) (Verification, error) {
+	verification := Verification{}
+
+	var lastId int64
+	previousHash := ""
+	for {
+		events, err := a.queries.ListChainedAuditEvents(ctx, database.ListChainedAuditEventsParams{
+			ID:    lastId,
+			Limit: verificationBatch,
+		})
+		if err != nil {
+			return verification, err
+		}
+
+		for _, event := range events {
+			hash, err := chainHash(database.CreateAuditEventParams{
+				ActorID:        event.ActorID,
+				ImpersonatorID: event.ImpersonatorID,
+				TargetUserID:   event.TargetUserID,
+				Action:         event.Action,
+				Outcome:        event.Outcome,
+				IpAddress:      event.IpAddress,
+				UserAgent:      event.UserAgent,
+				RequestID:      event.RequestID,
+				Metadata:       event.Metadata,
+				PreviousHash:   event.PreviousHash,
+				CreatedAt:      event.CreatedAt,
+			})
+			if err != nil {
+				return verification, err
+			}
+
+			// The oldest retained event may link to events deleted by the
+			// retention policy, so only later links are checked.
+			linked := verification.Checked == 0 || event.PreviousHash.String == previousHash
+			if !linked || hash != event.Hash.String {
+				verification.BrokenAt = event.ID
+				return verification, nil
+			}
+
+			verification.Checked++
+			previousHash = event.Hash.String
+			lastId = event.ID
+		}
+
+		if len(events) < verificationBatch {
+			return verification, nil
+		}
+	}
+}
+
+// PurgeExpired deletes the events older than AUDIT_RETENTION_DAYS. It does
+// nothing if the retention period is 0 or less.
+func (a *Auditor) PurgeExpired(ctx context.Context) error {
+	if a.config.AuditRetentionDays <= 0 {
+		return nil
+	}
+
+	cutoff := pgtype.Timestamptz{
+		Time:  time.Now().Add(-time.Duration(a.config.AuditRetentionDays) * 24 * time.Hour),
+		Valid: true,
+	}
+
+	// The database refuses to delete events unless the transaction sets the
+	// cutoff first, and then only those older than it.
+	return a.queries.InTx(ctx, func(queries *database.Queries) error {
+		if err := queries.SetAuditRetentionCutoff(ctx, cutoff); err != nil {
+			return err
+		}
+
+		return queries.DeleteAuditEventsBefore(ctx, cutoff)
+	})
+}
+
+// RequestID returns the id of r, as set by a proxy in the X-Request-Id header,
+// or a new one if there is none.
+func RequestID(r *http.Request) string {
+	if requestId := r.Header.Get(requestIdHeader); requestId != "" {
+		return requestId
+	}
+
+	return uuid.New().String()
+}
+
+// chainHash hashes an event together with the hash of the previous one. The
+// metadata is re-encoded so that the hash doesn't depend on how the database
+// formats it.
+func chainHash(params database.CreateAuditEventParams) (string, error) {
+	var metadata any
+	if err := json.Unmarshal(params.Metadata, &metadata); err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(struct {
+		PreviousHash   string `json:"previous_hash"`
+		ActorID        string `json:"actor_id"`
+		ImpersonatorID string `json:"impersonator_id"`
+		TargetUserID   string `json:"target_user_id"`
+		Action         string `json:"action"`
+		Outcome        string `json:"outcome"`
+		IpAddress      string `json:"ip_address"`
+		UserAgent      string `json:"user_agent"`
+		RequestID      string `json:"request_id"`
+		Metadata       any    `json:"metadata"`
+		CreatedAt      string `json:"created_at"`
+	}{
+		PreviousHash:   params.PreviousHash.String,
+		ActorID:        params.ActorID.String,
+		ImpersonatorID: params.ImpersonatorID.String,
+		TargetUserID:   params.TargetUserID.String,
+		Action:         params.Action,
+		Outcome:        params.Outcome,
+		IpAddress:      params.IpAddress,
+		UserAgent:      params.UserAgent,
+		RequestID:      params.RequestID,
+		Metadata:       metadata,
+		CreatedAt:      params.CreatedAt.Time.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func optionalText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
@@ -0,0 +1,490 @@
+package authz
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/lifecycle"
+	"github.com/abyanmajid/thorfinn/internal/organizations"
+)
+
+// Object types with relations Thorfinn derives from its own data rather than
+// from relation tuples. organization:<id>#owner, #admin and #member hold the
+// members of the organization with that role or a more privileged one, and
+// role:<name>#member holds the users with that global role. These relations
+// can't be written as tuples.
+const (
+	TypeUser         = "user"
+	TypeOrganization = "organization"
+	TypeRole         = "role"
+
+	RelationMember = "member"
+)
+
+// maxDepth bounds how many relations a check or expansion follows in a row,
+// and maxNodes how many relations it evaluates in total, so that cycles and
+// wide fan-outs in tuples or in the schema can't make it run forever.
+const (
+	maxDepth = 16
+	maxNodes = 1000
+)
+
+var (
+	namePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	idPattern   = regexp.MustCompile(`^[^\s#@]+$`)
+)
+
+var ErrTupleNotFound = apierror.New(apierror.RelationTupleNotFound, "relation tuple not found")
+
+// Object is a resource relations are defined on, written type:id.
+type Object struct {
+	Type string
+	ID   string
+}
+
+func (o Object) String() string {
+	return o.Type + ":" + o.ID
+}
+
+// Subject is who a relation is granted to, written type:id for a single
+// subject, or type:id#relation for every subject with that relation on the
+// object type:id (a userset).
+type Subject struct {
+	Type     string
+	ID       string
+	Relation string
+}
+
+func (s Subject) String() string {
+	if s.Relation == "" {
+		return s.Type + ":" + s.ID
+	}
+
+	return s.Type + ":" + s.ID + "#" + s.Relation
+}
+
+func ParseObject(value string) (Object, bool) {
+	objectType, id, found := strings.Cut(value, ":")
+	if !found || !namePattern.MatchString(objectType) || !idPattern.MatchString(id) {
+		return Object{}, false
+	}
+
+	return Object{Type: objectType, ID: id}, true
+}
+
+func ParseSubject(value string) (Subject, bool) {
+	value, relation, hasRelation := strings.Cut(value, "#")
+	if hasRelation && !ValidRelation(relation) {
+		return Subject{}, false
+	}
+
+	object, ok := ParseObject(value)
+	if !ok {
+		return Subject{}, false
+	}
+
+	return Subject{Type: object.Type, ID: object.ID, Relation: relation}, true
+}
+
+func ValidRelation(relation string) bool {
+	return namePattern.MatchString(relation)
+}
+
+// IsBuiltIn reports whether relation on objects of objectType is derived by
+// Thorfinn, and so can't be written as a tuple.
+func IsBuiltIn(objectType string, relation string) bool {
+	switch objectType {
+	case TypeOrganization:
+		return slices.Contains(organizations.Roles, relation)
+	case TypeRole:
+		return relation == RelationMember
+	default:
+		return false
+	}
+}
+
+// Rule defines how a relation is inherited, in addition to the subjects its
+// tuples grant it to. Subjects with any of the Includes relations on the same
+// object have it too, and so do subjects with a Through relation on the
+// objects related to it by From. For instance, a document viewer rule of
+// {"includes": ["editor"], "through": [{"from": "parent", "relation": "viewer"}]}
+// makes editors of a document and viewers of its parent folder viewers of the
+// document.
+type Rule struct {
+	Includes []string  `json:"includes"`
+	Through  []Through `json:"through"`
+}
+
+type Through struct {
+	From     string `json:"from"`
+	Relation string `json:"relation"`
+}
+
+// Schema holds the rules of relations by object type and relation name.
+type Schema map[string]map[string]Rule
+
+// LoadSchema reads a schema from the JSON file at path, or returns an empty
+// schema if path is empty.
+func LoadSchema(path string) (Schema, error) {
+	schema := Schema{}
+	if path == "" {
+		return schema, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %v", err)
+	}
+
+	for objectType, relations := range schema {
+		if !namePattern.MatchString(objectType) {
+			return nil, fmt.Errorf("invalid object type %q", objectType)
+		}
+
+		for relation, rule := range relations {
+			if !ValidRelation(relation) {
+				return nil, fmt.Errorf("invalid relation %q on %s", relation, objectType)
+			}
+
+			if IsBuiltIn(objectType, relation) {
+				return nil, fmt.Errorf("relation %s#%s is built in and can't be redefined", objectType, relation)
+			}
+
+			for _, include := range rule.Includes {
+				if !ValidRelation(include) {
+					return nil, fmt.Errorf("invalid included relation %q in %s#%s", include, objectType, relation)
+				}
+			}
+
+			for _, through := range rule.Through {
+				if !ValidRelation(through.From) || !ValidRelation(through.Relation) {
+					return nil, fmt.Errorf("invalid through rule %s->%s in %s#%s", through.From, through.Relation, objectType, relation)
+				}
+			}
+		}
+	}
+
+	return schema, nil
+}
+
+// Node is the tree of who has a relation on an object: the subjects its
+// tuples grant it to directly, and a child for each userset, included
+// relation and inherited relation it is also granted through. Truncated is
+// set when the node isn't expanded, because the tree is too deep or too
+// large, or the node is already expanded elsewhere in it.
+type Node struct {
+	Object    string   `json:"object"`
+	Relation  string   `json:"relation"`
+	BuiltIn   bool     `json:"built_in"`
+	Truncated bool     `json:"truncated"`
+	Subjects  []string `json:"subjects"`
+	Children  []*Node  `json:"children"`
+}
+
+// evaluation tracks the relations a single check or expansion has evaluated,
+// so that each is only evaluated once and their number is bounded.
+type evaluation struct {
+	visited map[string]bool
+}
+
+func newEvaluation() *evaluation {
+	return &evaluation{visited: map[string]bool{}}
+}
+
+// visit reports whether relation on object may be evaluated, which is when it
+// hasn't been yet and the evaluation hasn't reached maxNodes.
+func (e *evaluation) visit(object Object, relation string) bool {
+	key := object.String() + "#" + relation
+	if e.visited[key] || len(e.visited) >= maxNodes {
+		return false
+	}
+
+	e.visited[key] = true
+	return true
+}
+
+// Checker answers whether subjects have relations on objects, from relation
+// tuples, the configured schema, and Thorfinn's organizations and roles.
+type Checker struct {
+	queries *database.Queries
+	schema  Schema
+}
+
+func NewChecker(config *internal.EnvConfig, queries *database.Queries) *Checker {
+	schema, err := LoadSchema(config.AuthzSchemaPath)
+	if err != nil {
+		logger.Fatal("Error loading authorization schema: %v", err)
+	}
+
+	return &Checker{
+		queries: queries,
+		schema:  schema,
+	}
+}
+
+// Check reports whether subject has relation on object. Users only have
+// relations while their account is active.
+func (c *Checker) Check(ctx context.Context, object Object, relation string, subject Subject) (bool, error) {
+	if subject.Type == TypeUser && subject.Relation == "" {
+		user, err := c.queries.FindUserById(ctx, subject.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if !lifecycle.IsActive(user) {
+			return false, nil
+		}
+	}
+
+	return c.check(ctx, newEvaluation(), object, relation, subject, 0)
+}
+
+// check evaluates each relation on each object at most once, as it either
+// found subject the first time, ending the check, or didn't.
+func (c *Checker) check(ctx context.Context, eval *evaluation, object Object, relation string, subject Subject, depth int) (bool, error) {
+	if depth > maxDepth || !eval.visit(object, relation) {
+		return false, nil
+	}
+
+	if IsBuiltIn(object.Type, relation) {
+		return c.checkBuiltIn(ctx, object, relation, subject)
+	}
+
+	tuples, err := c.queries.ListRelationTuples(ctx, database.ListRelationTuplesParams{
+		ObjectType: object.Type,
+		ObjectID:   object.ID,
+		Relation:   relation,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, tuple := range tuples {
+		if subjectOf(tuple) == subject {
+			return true, nil
+		}
+	}
+
+	for _, tuple := range tuples {
+		if tuple.SubjectRelation == "" {
+			continue
+		}
+
+		allowed, err := c.check(ctx, eval, Object{Type: tuple.SubjectType, ID: tuple.SubjectID}, tuple.SubjectRelation, subject, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	rule := c.schema[object.Type][relation]
+
+	for _, include := range rule.Includes {
+		allowed, err := c.check(ctx, eval, object, include, subject, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	for _, through := range rule.Through {
+		related, err := c.related(ctx, object, through.From)
+		if err != nil {
+			return false, err
+		}
+
+		for _, parent := range related {
+			allowed, err := c.check(ctx, eval, parent, through.Relation, subject, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (c *Checker) checkBuiltIn(ctx context.Context, object Object, relation string, subject Subject) (bool, error) {
+	if subject.Type != TypeUser || subject.Relation != "" {
+		return false, nil
+	}
+
+	switch object.Type {
+	case TypeOrganization:
+		membership, err := c.queries.FindMembership(ctx, database.FindMembershipParams{
+			OrganizationID: object.ID,
+			UserID:         subject.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		return organizations.AtLeast(membership.Role, relation), nil
+	case TypeRole:
+		user, err := c.queries.FindUserById(ctx, subject.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		return slices.Contains(user.Roles, object.ID), nil
+	default:
+		return false, nil
+	}
+}
+
+// Expand returns the tree of who has relation on object.
+func (c *Checker) Expand(ctx context.Context, object Object, relation string) (*Node, error) {
+	return c.expand(ctx, newEvaluation(), object, relation, 0)
+}
+
+func (c *Checker) expand(ctx context.Context, eval *evaluation, object Object, relation string, depth int) (*Node, error) {
+	node := &Node{
+		Object:   object.String(),
+		Relation: relation,
+		Subjects: []string{},
+		Children: []*Node{},
+	}
+
+	if depth > maxDepth || !eval.visit(object, relation) {
+		node.Truncated = true
+		return node, nil
+	}
+
+	if IsBuiltIn(object.Type, relation) {
+		subjects, err := c.builtInSubjects(ctx, object, relation)
+		if err != nil {
+			return nil, err
+		}
+
+		node.BuiltIn = true
+		node.Subjects = subjects
+
+		return node, nil
+	}
+
+	tuples, err := c.queries.ListRelationTuples(ctx, database.ListRelationTuplesParams{
+		ObjectType: object.Type,
+		ObjectID:   object.ID,
+		Relation:   relation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tuple := range tuples {
+		if tuple.SubjectRelation == "" {
+			node.Subjects = append(node.Subjects, subjectOf(tuple).String())
+			continue
+		}
+
+		child, err := c.expand(ctx, eval, Object{Type: tuple.SubjectType, ID: tuple.SubjectID}, tuple.SubjectRelation, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+
+	rule := c.schema[object.Type][relation]
+
+	for _, include := range rule.Includes {
+		child, err := c.expand(ctx, eval, object, include, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+
+	for _, through := range rule.Through {
+		related, err := c.related(ctx, object, through.From)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, parent := range related {
+			child, err := c.expand(ctx, eval, parent, through.Relation, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+
+	return node, nil
+}
+
+func (c *Checker) builtInSubjects(ctx context.Context, object Object, relation string) ([]string, error) {
+	subjects := []string{}
+
+	switch object.Type {
+	case TypeOrganization:
+		members, err := c.queries.ListOrganizationMembers(ctx, object.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			if organizations.AtLeast(member.Role, relation) {
+				subjects = append(subjects, TypeUser+":"+member.UserID)
+			}
+		}
+	case TypeRole:
+		userIds, err := c.queries.ListUserIdsWithRole(ctx, object.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, userId := range userIds {
+			subjects = append(subjects, TypeUser+":"+userId)
+		}
+	}
+
+	return subjects, nil
+}
+
+// related returns the objects object is related to by relation, ignoring
+// usersets.
+func (c *Checker) related(ctx context.Context, object Object, relation string) ([]Object, error) {
+	tuples, err := c.queries.ListRelationTuples(ctx, database.ListRelationTuplesParams{
+		ObjectType: object.Type,
+		ObjectID:   object.ID,
+		Relation:   relation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []Object{}
+	for _, tuple := range tuples {
+		if tuple.SubjectRelation == "" {
+			objects = append(objects, Object{Type: tuple.SubjectType, ID: tuple.SubjectID})
+		}
+	}
+
+	return objects, nil
+}
+
+func subjectOf(tuple database.ThorfinnRelationTuple) Subject {
+	return Subject{Type: tuple.SubjectType, ID: tuple.SubjectID, Relation: tuple.SubjectRelation}
+}
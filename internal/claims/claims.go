@@ -0,0 +1,281 @@
+package claims
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/matcha/security"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/hooks"
+	"github.com/abyanmajid/thorfinn/internal/metadata"
+)
+
+// Reserved claims are set by the server itself and can't be produced by a
+// mapping or a hook.
+var Reserved = []string{"user_id", "email", "session_id", "token_type", "org_id", "org_role", "act", "iat", "exp", "nbf", "iss", "sub", "aud", "jti"}
+
+type mapping struct {
+	claim  string
+	source string
+}
+
+// Mapper builds the custom claims of access tokens from the user's profile,
+// metadata and roles, the configured mappings, and the BeforeTokenIssue
+// lifecycle hooks.
+type Mapper struct {
+	config   *internal.EnvConfig
+	mappings []mapping
+}
+
+// NewMapper also registers the HTTP hook as a lifecycle hook named
+// HttpHookName, when CLAIMS_HOOK_URL is set.
+func NewMapper(config *internal.EnvConfig) *Mapper {
+	mappings, err := parseMappings(config.TokenClaims)
+	if err != nil {
+		logger.Fatal("Error parsing TOKEN_CLAIMS: %v", err)
+	}
+
+	if httpHook := newHttpHook(config); httpHook != nil {
+		hooks.Register(HttpHookName, httpHook)
+	}
+
+	return &Mapper{
+		config:   config,
+		mappings: mappings,
+	}
+}
+
+// parseMappings parses a comma-separated list of claim=source pairs.
+func parseMappings(value string) ([]mapping, error) {
+	mappings := []mapping{}
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		claim, source, ok := strings.Cut(pair, "=")
+		claim, source = strings.TrimSpace(claim), strings.TrimSpace(source)
+		if !ok || claim == "" || source == "" {
+			return nil, fmt.Errorf("%q is not a claim=source pair", pair)
+		}
+
+		if slices.Contains(Reserved, claim) {
+			return nil, fmt.Errorf("%q is a reserved claim", claim)
+		}
+
+		if !validSource(source) {
+			return nil, fmt.Errorf("%q is not a known claim source", source)
+		}
+
+		mappings = append(mappings, mapping{claim: claim, source: source})
+	}
+
+	return mappings, nil
+}
+
+// Claims returns the custom claims to add to the access token of user. If the
+// token is issued for an active organization, membership is the user's
+// membership in it, and is added as the org_id and org_role claims. The other
+// reserved claims are left for the caller to set. Errors of the BeforeTokenIssue
+// hooks, including rejections, are returned as is.
+func (m *Mapper) Claims(ctx context.Context, user database.ThorfinnUser, membership *database.ThorfinnMembership) (security.JwtClaims, error) {
+	claims := profileClaims(user)
+
+	if membership != nil {
+		claims["org_id"] = membership.OrganizationID
+		claims["org_role"] = membership.Role
+	}
+
+	if projected := project(user.UserMetadata, m.config.UserMetadataClaims); len(projected) > 0 {
+		claims[metadata.UserMetadataField] = projected
+	}
+
+	if projected := project(user.AppMetadata, m.config.AppMetadataClaims); len(projected) > 0 {
+		claims[metadata.AppMetadataField] = projected
+	}
+
+	for _, mapping := range m.mappings {
+		if value, ok := resolve(user, mapping.source); ok {
+			claims[mapping.claim] = value
+		}
+	}
+
+	reserved := reservedClaims(claims)
+
+	if err := hooks.BeforeTokenIssue(ctx, user, claims); err != nil {
+		return nil, err
+	}
+
+	restoreReserved(claims, reserved)
+
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding claims: %v", err)
+	}
+
+	if m.config.ClaimsMaxBytes > 0 && len(encoded) > m.config.ClaimsMaxBytes {
+		return nil, fmt.Errorf("custom claims are %d bytes, more than the limit of %d", len(encoded), m.config.ClaimsMaxBytes)
+	}
+
+	return claims, nil
+}
+
+// reservedClaims returns the reserved claims set in claims.
+func reservedClaims(claims security.JwtClaims) security.JwtClaims {
+	reserved := security.JwtClaims{}
+	for _, claim := range Reserved {
+		if value, ok := claims[claim]; ok {
+			reserved[claim] = value
+		}
+	}
+
+	return reserved
+}
+
+// restoreReserved undoes the changes hooks made to the reserved claims.
+func restoreReserved(claims security.JwtClaims, reserved security.JwtClaims) {
+	for _, claim := range Reserved {
+		value, wasSet := reserved[claim]
+		current, isSet := claims[claim]
+		if !wasSet && !isSet {
+			continue
+		}
+
+		if !wasSet {
+			logger.Error("Dropping reserved claim %s set by a token hook", claim)
+			delete(claims, claim)
+			continue
+		}
+
+		if !isSet || !reflect.DeepEqual(current, value) {
+			logger.Error("Restoring reserved claim %s changed by a token hook", claim)
+			claims[claim] = value
+		}
+	}
+}
+
+// profileClaims returns the profile fields of the user that are set, under
+// their standard OpenID Connect claim names.
+func profileClaims(user database.ThorfinnUser) security.JwtClaims {
+	claims := security.JwtClaims{}
+
+	for _, field := range profileFields {
+		if value := field.value(user); value != "" {
+			claims[field.claim] = value
+		}
+	}
+
+	return claims
+}
+
+type profileField struct {
+	name  string
+	claim string
+	value func(user database.ThorfinnUser) string
+}
+
+var profileFields = []profileField{
+	{"display_name", "name", func(u database.ThorfinnUser) string { return u.DisplayName.String }},
+	{"given_name", "given_name", func(u database.ThorfinnUser) string { return u.GivenName.String }},
+	{"family_name", "family_name", func(u database.ThorfinnUser) string { return u.FamilyName.String }},
+	{"avatar_url", "picture", func(u database.ThorfinnUser) string { return u.AvatarUrl.String }},
+	{"locale", "locale", func(u database.ThorfinnUser) string { return u.Locale.String }},
+	{"timezone", "zoneinfo", func(u database.ThorfinnUser) string { return u.Timezone.String }},
+}
+
+// project picks the top-level keys in the comma-separated list out of the
+// document. Keys missing from the document are left out.
+func project(document []byte, keys string) map[string]any {
+	projected := map[string]any{}
+	if strings.TrimSpace(keys) == "" {
+		return projected
+	}
+
+	decoded := metadata.Decode(document)
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		if value, ok := decoded[key]; ok && key != "" {
+			projected[key] = value
+		}
+	}
+
+	return projected
+}
+
+// validSource reports whether source names something resolve can read: roles,
+// verified, profile.<field>, or a dotted path into user_metadata or
+// app_metadata.
+func validSource(source string) bool {
+	switch source {
+	case "roles", "verified":
+		return true
+	}
+
+	root, path, ok := strings.Cut(source, ".")
+	if !ok || path == "" {
+		return false
+	}
+
+	switch root {
+	case "profile":
+		return slices.ContainsFunc(profileFields, func(field profileField) bool { return field.name == path })
+	case metadata.UserMetadataField, metadata.AppMetadataField:
+		return true
+	default:
+		return false
+	}
+}
+
+// resolve reads the value of source for user. It returns false if the value
+// is not set.
+func resolve(user database.ThorfinnUser, source string) (any, bool) {
+	switch source {
+	case "roles":
+		return user.Roles, true
+	case "verified":
+		return user.Verified, true
+	}
+
+	root, path, _ := strings.Cut(source, ".")
+
+	var document []byte
+	switch root {
+	case "profile":
+		for _, field := range profileFields {
+			if field.name == path {
+				value := field.value(user)
+				return value, value != ""
+			}
+		}
+		return nil, false
+	case metadata.UserMetadataField:
+		document = user.UserMetadata
+	case metadata.AppMetadataField:
+		document = user.AppMetadata
+	default:
+		return nil, false
+	}
+
+	var value any = metadata.Decode(document)
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
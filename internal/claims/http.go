@@ -0,0 +1,159 @@
+package claims
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/matcha/security"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/hooks"
+)
+
+const signatureHeader = "X-Thorfinn-Signature"
+
+// HttpHookName is the name the HTTP hook is registered under, which decides
+// when it runs among the other lifecycle hooks.
+const HttpHookName = "claims_http"
+
+// httpHook posts the user and their mapped claims to a local service, which
+// responds with the claims to add. When a secret is configured, the request
+// body is signed with HMAC-SHA256 so the service can check it came from us.
+type httpHook struct {
+	hooks.Base
+
+	url      string
+	secret   string
+	maxBytes int
+	client   *http.Client
+}
+
+type httpHookRequest struct {
+	User   httpHookUser       `json:"user"`
+	Claims security.JwtClaims `json:"claims"`
+}
+
+type httpHookUser struct {
+	ID           string          `json:"id"`
+	Email        string          `json:"email"`
+	Verified     bool            `json:"verified"`
+	Roles        []string        `json:"roles"`
+	UserMetadata json.RawMessage `json:"user_metadata"`
+	AppMetadata  json.RawMessage `json:"app_metadata"`
+}
+
+type httpHookResponse struct {
+	Claims security.JwtClaims `json:"claims"`
+}
+
+func newHttpHook(config *internal.EnvConfig) *httpHook {
+	if config.ClaimsHookUrl == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(config.ClaimsHookUrl)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		logger.Fatal("CLAIMS_HOOK_URL must be an http or https URL")
+	}
+
+	return &httpHook{
+		url:      config.ClaimsHookUrl,
+		secret:   config.ClaimsHookSecret,
+		maxBytes: config.ClaimsMaxBytes,
+		client: &http.Client{
+			Timeout: time.Duration(config.ClaimsHookTimeoutMs) * time.Millisecond,
+		},
+	}
+}
+
+// BeforeTokenIssue adds the claims the service responds with.
+func (h *httpHook) BeforeTokenIssue(ctx context.Context, user database.ThorfinnUser, claims security.JwtClaims) error {
+	added, err := h.call(ctx, user, claims)
+	if err != nil {
+		return fmt.Errorf("error calling claims hook: %v", err)
+	}
+
+	for claim, value := range added {
+		claims[claim] = value
+	}
+
+	return nil
+}
+
+func (h *httpHook) call(ctx context.Context, user database.ThorfinnUser, claims security.JwtClaims) (security.JwtClaims, error) {
+	body, err := json.Marshal(httpHookRequest{
+		User: httpHookUser{
+			ID:           user.ID,
+			Email:        user.Email,
+			Verified:     user.Verified,
+			Roles:        user.Roles,
+			UserMetadata: json.RawMessage(orEmptyObject(user.UserMetadata)),
+			AppMetadata:  json.RawMessage(orEmptyObject(user.AppMetadata)),
+		},
+		Claims: claims,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %v", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	if h.secret != "" {
+		mac := hmac.New(sha256.New, []byte(h.secret))
+		mac.Write(body)
+		request.Header.Set(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	response, err := h.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("hook responded with status %d", response.StatusCode)
+	}
+
+	reader := io.Reader(response.Body)
+	if h.maxBytes > 0 {
+		reader = io.LimitReader(response.Body, int64(h.maxBytes)+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if h.maxBytes > 0 && len(data) > h.maxBytes {
+		return nil, fmt.Errorf("hook response is larger than %d bytes", h.maxBytes)
+	}
+
+	var decoded httpHookResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("error decoding response: %v", err)
+	}
+
+	return decoded.Claims, nil
+}
+
+func orEmptyObject(document []byte) []byte {
+	if len(document) == 0 {
+		return []byte("{}")
+	}
+
+	return document
+}
@@ -8,6 +8,37 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ThorfinnApiKey struct {
+	ID             string
+	UserID         string
+	OrganizationID pgtype.Text
+	Name           string
+	Prefix         string
+	KeyHash        string
+	Scopes         []string
+	ExpiresAt      pgtype.Timestamptz
+	LastUsedAt     pgtype.Timestamptz
+	RevokedAt      pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type ThorfinnAuditEvent struct {
+	ID             int64
+	ActorID        pgtype.Text
+	ImpersonatorID pgtype.Text
+	TargetUserID   pgtype.Text
+	Action         string
+	Outcome        string
+	IpAddress      string
+	UserAgent      string
+	RequestID      string
+	Metadata       []byte
+	PreviousHash   pgtype.Text
+	Hash           pgtype.Text
+	CreatedAt      pgtype.Timestamptz
+}
+
 type ThorfinnBlacklistedToken struct {
 	ID        string
 	Token     string
@@ -15,20 +46,127 @@ type ThorfinnBlacklistedToken struct {
 	UpdatedAt pgtype.Timestamptz
 }
 
+type ThorfinnInvitation struct {
+	ID             string
+	Email          string
+	OrganizationID pgtype.Text
+	Role           pgtype.Text
+	TokenHash      string
+	InvitedBy      pgtype.Text
+	ExpiresAt      pgtype.Timestamptz
+	AcceptedAt     pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type ThorfinnMembership struct {
+	OrganizationID string
+	UserID         string
+	Role           string
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type ThorfinnOrganization struct {
+	ID        string
+	Name      string
+	Slug      string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
 type ThorfinnOtpCode struct {
 	ID        string
 	Code      string
 	CreatedAt pgtype.Timestamptz
 	UpdatedAt pgtype.Timestamptz
 	ExpiresAt pgtype.Timestamptz
+	UserID    pgtype.Text
+}
+
+type ThorfinnPasswordHistory struct {
+	ID           string
+	UserID       string
+	PasswordHash string
+	CreatedAt    pgtype.Timestamptz
+}
+
+type ThorfinnRelationTuple struct {
+	ObjectType      string
+	ObjectID        string
+	Relation        string
+	SubjectType     string
+	SubjectID       string
+	SubjectRelation string
+	CreatedAt       pgtype.Timestamptz
+}
+
+type ThorfinnSession struct {
+	ID                   string
+	UserID               string
+	IpAddress            string
+	UserAgent            string
+	CreatedAt            pgtype.Timestamptz
+	UpdatedAt            pgtype.Timestamptz
+	ExpiresAt            pgtype.Timestamptz
+	RevokedAt            pgtype.Timestamptz
+	ActiveOrganizationID pgtype.Text
+	ImpersonatorID       pgtype.Text
 }
 
 type ThorfinnUser struct {
-	ID               string
-	Email            string
-	PasswordHash     string
-	Verified         bool
-	TwoFactorEnabled bool
-	CreatedAt        pgtype.Timestamptz
-	UpdatedAt        pgtype.Timestamptz
+	ID                      string
+	Email                   string
+	PasswordHash            string
+	Verified                bool
+	TwoFactorEnabled        bool
+	CreatedAt               pgtype.Timestamptz
+	UpdatedAt               pgtype.Timestamptz
+	NotificationOptOuts     []string
+	LastLoginAt             pgtype.Timestamptz
+	Roles                   []string
+	DisplayName             pgtype.Text
+	GivenName               pgtype.Text
+	FamilyName              pgtype.Text
+	AvatarUrl               pgtype.Text
+	Locale                  pgtype.Text
+	Timezone                pgtype.Text
+	UserMetadata            []byte
+	AppMetadata             []byte
+	Version                 int64
+	Status                  string
+	StatusReason            pgtype.Text
+	PurgeAfter              pgtype.Timestamptz
+	DeletedAt               pgtype.Timestamptz
+	DeletionRequestedByUser bool
+}
+
+type ThorfinnWebhookDelivery struct {
+	ID             string
+	EndpointID     string
+	EventID        string
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamptz
+	LastAttemptAt  pgtype.Timestamptz
+	ResponseStatus pgtype.Int4
+	ResponseBody   string
+	Error          string
+	CreatedAt      pgtype.Timestamptz
+}
+
+type ThorfinnWebhookEndpoint struct {
+	ID                  string
+	Url                 string
+	Secret              string
+	Events              []string
+	Description         string
+	Enabled             bool
+	DisabledReason      pgtype.Text
+	ConsecutiveFailures int32
+	CreatedBy           pgtype.Text
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
 }
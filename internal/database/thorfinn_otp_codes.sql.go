@@ -12,17 +12,23 @@ import (
 )
 
 const createOtpCode = `-- name: CreateOtpCode :one
-INSERT INTO thorfinn_otp_codes (id, code, expires_at) VALUES ($1, $2, $3) RETURNING id, code, created_at, updated_at, expires_at
+INSERT INTO thorfinn_otp_codes (id, code, expires_at, user_id) VALUES ($1, $2, $3, $4) RETURNING id, code, created_at, updated_at, expires_at, user_id
 `
 
 type CreateOtpCodeParams struct {
 	ID        string
 	Code      string
 	ExpiresAt pgtype.Timestamptz
+	UserID    pgtype.Text
 }
 
 func (q *Queries) CreateOtpCode(ctx context.Context, arg CreateOtpCodeParams) (ThorfinnOtpCode, error) {
-	row := q.db.QueryRow(ctx, createOtpCode, arg.ID, arg.Code, arg.ExpiresAt)
+	row := q.db.QueryRow(ctx, createOtpCode,
+		arg.ID,
+		arg.Code,
+		arg.ExpiresAt,
+		arg.UserID,
+	)
 	var i ThorfinnOtpCode
 	err := row.Scan(
 		&i.ID,
@@ -30,10 +36,20 @@ func (q *Queries) CreateOtpCode(ctx context.Context, arg CreateOtpCodeParams) (T
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ExpiresAt,
+		&i.UserID,
 	)
 	return i, err
 }
 
+const deleteExpiredOtpCodes = `-- name: DeleteExpiredOtpCodes :exec
+DELETE FROM thorfinn_otp_codes WHERE expires_at < CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredOtpCodes(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteExpiredOtpCodes)
+	return err
+}
+
 const deleteOtpCode = `-- name: DeleteOtpCode :exec
 DELETE FROM thorfinn_otp_codes WHERE id = $1
 `
@@ -44,7 +60,7 @@ func (q *Queries) DeleteOtpCode(ctx context.Context, id string) error {
 }
 
 const findOtpCodeById = `-- name: FindOtpCodeById :one
-SELECT id, code, created_at, updated_at, expires_at FROM thorfinn_otp_codes WHERE id = $1
+SELECT id, code, created_at, updated_at, expires_at, user_id FROM thorfinn_otp_codes WHERE id = $1
 `
 
 func (q *Queries) FindOtpCodeById(ctx context.Context, id string) (ThorfinnOtpCode, error) {
@@ -56,6 +72,7 @@ func (q *Queries) FindOtpCodeById(ctx context.Context, id string) (ThorfinnOtpCo
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ExpiresAt,
+		&i.UserID,
 	)
 	return i, err
 }
@@ -11,18 +11,61 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createUser = `-- name: CreateUser :one
-INSERT INTO thorfinn_users (id, email, password_hash) VALUES ($1, $2, $3) RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*)
+FROM thorfinn_users
+WHERE ($1::boolean IS NULL OR verified = $1)
+    AND ($2::boolean IS NULL OR two_factor_enabled = $2)
+    AND ($3::timestamptz IS NULL OR created_at >= $3)
+    AND ($4::timestamptz IS NULL OR created_at < $4)
+    AND ($5::text IS NULL OR email LIKE $5 || '%')
+    AND ($6::text IS NULL OR email ILIKE '%' || $6 || '%')
+    AND ($7::text IS NULL OR status = $7)
 `
 
-type CreateUserParams struct {
+type CountUsersParams struct {
+	Verified         pgtype.Bool
+	TwoFactorEnabled pgtype.Bool
+	CreatedAfter     pgtype.Timestamptz
+	CreatedBefore    pgtype.Timestamptz
+	EmailPrefix      pgtype.Text
+	EmailContains    pgtype.Text
+	Status           pgtype.Text
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers,
+		arg.Verified,
+		arg.TwoFactorEnabled,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.EmailPrefix,
+		arg.EmailContains,
+		arg.Status,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createInvitedUser = `-- name: CreateInvitedUser :one
+INSERT INTO thorfinn_users (id, email, password_hash, verified, roles) VALUES ($1, $2, $3, TRUE, $4) RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
+`
+
+type CreateInvitedUserParams struct {
 	ID           string
 	Email        string
 	PasswordHash string
+	Roles        []string
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (ThorfinnUser, error) {
-	row := q.db.QueryRow(ctx, createUser, arg.ID, arg.Email, arg.PasswordHash)
+func (q *Queries) CreateInvitedUser(ctx context.Context, arg CreateInvitedUserParams) (ThorfinnUser, error) {
+	row := q.db.QueryRow(ctx, createInvitedUser,
+		arg.ID,
+		arg.Email,
+		arg.PasswordHash,
+		arg.Roles,
+	)
 	var i ThorfinnUser
 	err := row.Scan(
 		&i.ID,
@@ -32,21 +75,77 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (Thorfin
 		&i.TwoFactorEnabled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
 	)
 	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM thorfinn_users WHERE id = $1
+const createUser = `-- name: CreateUser :one
+INSERT INTO thorfinn_users (id, email, password_hash, status) VALUES ($1, $2, $3, $4) RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id string) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
-	return err
+type CreateUserParams struct {
+	ID           string
+	Email        string
+	PasswordHash string
+	Status       string
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (ThorfinnUser, error) {
+	row := q.db.QueryRow(ctx, createUser,
+		arg.ID,
+		arg.Email,
+		arg.PasswordHash,
+		arg.Status,
+	)
+	var i ThorfinnUser
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Verified,
+		&i.TwoFactorEnabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
+	)
+	return i, err
 }
 
 const findUserByEmail = `-- name: FindUserByEmail :one
-SELECT id, email, password_hash, verified, two_factor_enabled, created_at, updated_at FROM thorfinn_users WHERE email = $1
+SELECT id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user FROM thorfinn_users WHERE email = $1
 `
 
 func (q *Queries) FindUserByEmail(ctx context.Context, email string) (ThorfinnUser, error) {
@@ -60,12 +159,29 @@ func (q *Queries) FindUserByEmail(ctx context.Context, email string) (ThorfinnUs
 		&i.TwoFactorEnabled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
 	)
 	return i, err
 }
 
 const findUserById = `-- name: FindUserById :one
-SELECT id, email, password_hash, verified, two_factor_enabled, created_at, updated_at FROM thorfinn_users WHERE id = $1
+SELECT id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user FROM thorfinn_users WHERE id = $1
 `
 
 func (q *Queries) FindUserById(ctx context.Context, id string) (ThorfinnUser, error) {
@@ -79,27 +195,128 @@ func (q *Queries) FindUserById(ctx context.Context, id string) (ThorfinnUser, er
 		&i.TwoFactorEnabled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
 	)
 	return i, err
 }
 
+const listUserIdsWithRole = `-- name: ListUserIdsWithRole :many
+SELECT id FROM thorfinn_users WHERE $1::text = ANY(roles) ORDER BY id
+`
+
+func (q *Queries) ListUserIdsWithRole(ctx context.Context, role string) ([]string, error) {
+	rows, err := q.db.Query(ctx, listUserIdsWithRole, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, verified, two_factor_enabled, created_at, updated_at
+SELECT id, email, verified, two_factor_enabled, roles, status, created_at, updated_at, last_login_at
 FROM thorfinn_users
-ORDER BY created_at DESC
+WHERE ($1::boolean IS NULL OR verified = $1)
+    AND ($2::boolean IS NULL OR two_factor_enabled = $2)
+    AND ($3::timestamptz IS NULL OR created_at >= $3)
+    AND ($4::timestamptz IS NULL OR created_at < $4)
+    AND ($5::text IS NULL OR email LIKE $5 || '%')
+    AND ($6::text IS NULL OR email ILIKE '%' || $6 || '%')
+    AND ($7::text IS NULL OR status = $7)
+    AND ($8::text IS NULL OR CASE $9::text
+        WHEN 'email' THEN CASE WHEN $10::boolean
+            THEN (email, id) < ($11::text, $8)
+            ELSE (email, id) > ($11::text, $8) END
+        WHEN 'last_login_at' THEN CASE WHEN $10::boolean
+            THEN (COALESCE(last_login_at, '-infinity'), id) < ($12::timestamptz, $8)
+            ELSE (COALESCE(last_login_at, '-infinity'), id) > ($12::timestamptz, $8) END
+        ELSE CASE WHEN $10::boolean
+            THEN (created_at, id) < ($12::timestamptz, $8)
+            ELSE (created_at, id) > ($12::timestamptz, $8) END
+    END)
+ORDER BY
+    CASE WHEN $9::text = 'email' AND NOT $10::boolean THEN email END ASC,
+    CASE WHEN $9::text = 'email' AND $10::boolean THEN email END DESC,
+    CASE WHEN $9::text = 'last_login_at' AND NOT $10::boolean THEN COALESCE(last_login_at, '-infinity') END ASC,
+    CASE WHEN $9::text = 'last_login_at' AND $10::boolean THEN COALESCE(last_login_at, '-infinity') END DESC,
+    CASE WHEN $9::text = 'created_at' AND NOT $10::boolean THEN created_at END ASC,
+    CASE WHEN $9::text = 'created_at' AND $10::boolean THEN created_at END DESC,
+    CASE WHEN NOT $10::boolean THEN id END ASC,
+    CASE WHEN $10::boolean THEN id END DESC
+LIMIT $13
 `
 
+type ListUsersParams struct {
+	Verified         pgtype.Bool
+	TwoFactorEnabled pgtype.Bool
+	CreatedAfter     pgtype.Timestamptz
+	CreatedBefore    pgtype.Timestamptz
+	EmailPrefix      pgtype.Text
+	EmailContains    pgtype.Text
+	Status           pgtype.Text
+	AfterID          pgtype.Text
+	SortBy           string
+	Descending       bool
+	AfterEmail       string
+	AfterTime        pgtype.Timestamptz
+	Limit            int32
+}
+
 type ListUsersRow struct {
 	ID               string
 	Email            string
 	Verified         bool
 	TwoFactorEnabled bool
+	Roles            []string
+	Status           string
 	CreatedAt        pgtype.Timestamptz
 	UpdatedAt        pgtype.Timestamptz
+	LastLoginAt      pgtype.Timestamptz
 }
 
-func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
-	rows, err := q.db.Query(ctx, listUsers)
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Verified,
+		arg.TwoFactorEnabled,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.EmailPrefix,
+		arg.EmailContains,
+		arg.Status,
+		arg.AfterID,
+		arg.SortBy,
+		arg.Descending,
+		arg.AfterEmail,
+		arg.AfterTime,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +329,11 @@ func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
 			&i.Email,
 			&i.Verified,
 			&i.TwoFactorEnabled,
+			&i.Roles,
+			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.LastLoginAt,
 		); err != nil {
 			return nil, err
 		}
@@ -125,11 +345,98 @@ func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
 	return items, nil
 }
 
-const updateUser = `-- name: UpdateUser :one
+const listUsersDueForPurge = `-- name: ListUsersDueForPurge :many
+SELECT id FROM thorfinn_users
+WHERE status = 'pending_deletion' AND purge_after <= CURRENT_TIMESTAMP
+ORDER BY purge_after
+LIMIT $1
+`
+
+func (q *Queries) ListUsersDueForPurge(ctx context.Context, limit int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, listUsersDueForPurge, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeUser = `-- name: PurgeUser :one
+WITH deleted_sessions AS (
+    DELETE FROM thorfinn_sessions WHERE user_id = $1
+), deleted_password_history AS (
+    DELETE FROM thorfinn_password_history WHERE user_id = $1
+), deleted_otp_codes AS (
+    DELETE FROM thorfinn_otp_codes WHERE user_id = $1
+), deleted_memberships AS (
+    DELETE FROM thorfinn_memberships WHERE user_id = $1
+), cleared_invitations AS (
+    UPDATE thorfinn_invitations SET invited_by = NULL WHERE invited_by = $1
+), deleted_api_keys AS (
+    DELETE FROM thorfinn_api_keys WHERE user_id = $1
+), deleted_relation_tuples AS (
+    DELETE FROM thorfinn_relation_tuples WHERE subject_type = 'user' AND subject_id = $1
+)
 UPDATE thorfinn_users
-SET email = $2, password_hash = $3, verified = $4, two_factor_enabled = $5
+SET email = id || '@deleted.invalid', password_hash = '', verified = FALSE, two_factor_enabled = FALSE,
+    notification_opt_outs = '{}', roles = '{}', last_login_at = NULL,
+    display_name = NULL, given_name = NULL, family_name = NULL, avatar_url = NULL, locale = NULL, timezone = NULL,
+    user_metadata = '{}', app_metadata = '{}',
+    status = 'deleted', status_reason = NULL, purge_after = NULL, deleted_at = CURRENT_TIMESTAMP, deletion_requested_by_user = FALSE
 WHERE id = $1
-RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at
+RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
+`
+
+func (q *Queries) PurgeUser(ctx context.Context, id string) (ThorfinnUser, error) {
+	row := q.db.QueryRow(ctx, purgeUser, id)
+	var i ThorfinnUser
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Verified,
+		&i.TwoFactorEnabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
+	)
+	return i, err
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE thorfinn_users
+SET email = $2, password_hash = $3, verified = $4, two_factor_enabled = $5, roles = $6,
+    display_name = $7, given_name = $8, family_name = $9, avatar_url = $10, locale = $11, timezone = $12,
+    user_metadata = $13, app_metadata = $14
+WHERE id = $1 AND version = $15
+RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
 `
 
 type UpdateUserParams struct {
@@ -138,6 +445,16 @@ type UpdateUserParams struct {
 	PasswordHash     string
 	Verified         bool
 	TwoFactorEnabled bool
+	Roles            []string
+	DisplayName      pgtype.Text
+	GivenName        pgtype.Text
+	FamilyName       pgtype.Text
+	AvatarUrl        pgtype.Text
+	Locale           pgtype.Text
+	Timezone         pgtype.Text
+	UserMetadata     []byte
+	AppMetadata      []byte
+	Version          int64
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (ThorfinnUser, error) {
@@ -147,7 +464,116 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (Thorfin
 		arg.PasswordHash,
 		arg.Verified,
 		arg.TwoFactorEnabled,
+		arg.Roles,
+		arg.DisplayName,
+		arg.GivenName,
+		arg.FamilyName,
+		arg.AvatarUrl,
+		arg.Locale,
+		arg.Timezone,
+		arg.UserMetadata,
+		arg.AppMetadata,
+		arg.Version,
+	)
+	var i ThorfinnUser
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Verified,
+		&i.TwoFactorEnabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE thorfinn_users
+SET email = $2
+WHERE id = $1
+RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
+`
+
+type UpdateUserEmailParams struct {
+	ID    string
+	Email string
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (ThorfinnUser, error) {
+	row := q.db.QueryRow(ctx, updateUserEmail, arg.ID, arg.Email)
+	var i ThorfinnUser
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Verified,
+		&i.TwoFactorEnabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
 	)
+	return i, err
+}
+
+const updateUserLastLogin = `-- name: UpdateUserLastLogin :exec
+UPDATE thorfinn_users
+SET last_login_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) UpdateUserLastLogin(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, updateUserLastLogin, id)
+	return err
+}
+
+const updateUserNotificationOptOuts = `-- name: UpdateUserNotificationOptOuts :one
+UPDATE thorfinn_users
+SET notification_opt_outs = $2
+WHERE id = $1
+RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
+`
+
+type UpdateUserNotificationOptOutsParams struct {
+	ID                  string
+	NotificationOptOuts []string
+}
+
+func (q *Queries) UpdateUserNotificationOptOuts(ctx context.Context, arg UpdateUserNotificationOptOutsParams) (ThorfinnUser, error) {
+	row := q.db.QueryRow(ctx, updateUserNotificationOptOuts, arg.ID, arg.NotificationOptOuts)
 	var i ThorfinnUser
 	err := row.Scan(
 		&i.ID,
@@ -157,6 +583,23 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (Thorfin
 		&i.TwoFactorEnabled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
 	)
 	return i, err
 }
@@ -165,7 +608,7 @@ const updateUserPassword = `-- name: UpdateUserPassword :one
 UPDATE thorfinn_users
 SET password_hash = $2
 WHERE id = $1
-RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at
+RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
 `
 
 type UpdateUserPasswordParams struct {
@@ -184,6 +627,141 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.TwoFactorEnabled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE thorfinn_users
+SET two_factor_enabled = $2, notification_opt_outs = $3,
+    display_name = $4, given_name = $5, family_name = $6, avatar_url = $7, locale = $8, timezone = $9,
+    user_metadata = $10
+WHERE id = $1
+RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
+`
+
+type UpdateUserProfileParams struct {
+	ID                  string
+	TwoFactorEnabled    bool
+	NotificationOptOuts []string
+	DisplayName         pgtype.Text
+	GivenName           pgtype.Text
+	FamilyName          pgtype.Text
+	AvatarUrl           pgtype.Text
+	Locale              pgtype.Text
+	Timezone            pgtype.Text
+	UserMetadata        []byte
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (ThorfinnUser, error) {
+	row := q.db.QueryRow(ctx, updateUserProfile,
+		arg.ID,
+		arg.TwoFactorEnabled,
+		arg.NotificationOptOuts,
+		arg.DisplayName,
+		arg.GivenName,
+		arg.FamilyName,
+		arg.AvatarUrl,
+		arg.Locale,
+		arg.Timezone,
+		arg.UserMetadata,
+	)
+	var i ThorfinnUser
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Verified,
+		&i.TwoFactorEnabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
+	)
+	return i, err
+}
+
+const updateUserStatus = `-- name: UpdateUserStatus :one
+UPDATE thorfinn_users
+SET status = $2, status_reason = $3, purge_after = $4, deletion_requested_by_user = $5
+WHERE id = $1
+RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
+`
+
+type UpdateUserStatusParams struct {
+	ID                      string
+	Status                  string
+	StatusReason            pgtype.Text
+	PurgeAfter              pgtype.Timestamptz
+	DeletionRequestedByUser bool
+}
+
+func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (ThorfinnUser, error) {
+	row := q.db.QueryRow(ctx, updateUserStatus,
+		arg.ID,
+		arg.Status,
+		arg.StatusReason,
+		arg.PurgeAfter,
+		arg.DeletionRequestedByUser,
+	)
+	var i ThorfinnUser
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Verified,
+		&i.TwoFactorEnabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
 	)
 	return i, err
 }
@@ -192,7 +770,7 @@ const updateUserVerified = `-- name: UpdateUserVerified :one
 UPDATE thorfinn_users
 SET verified = $2
 WHERE id = $1
-RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at
+RETURNING id, email, password_hash, verified, two_factor_enabled, created_at, updated_at, notification_opt_outs, last_login_at, roles, display_name, given_name, family_name, avatar_url, locale, timezone, user_metadata, app_metadata, version, status, status_reason, purge_after, deleted_at, deletion_requested_by_user
 `
 
 type UpdateUserVerifiedParams struct {
@@ -211,6 +789,23 @@ func (q *Queries) UpdateUserVerified(ctx context.Context, arg UpdateUserVerified
 		&i.TwoFactorEnabled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NotificationOptOuts,
+		&i.LastLoginAt,
+		&i.Roles,
+		&i.DisplayName,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.AvatarUrl,
+		&i.Locale,
+		&i.Timezone,
+		&i.UserMetadata,
+		&i.AppMetadata,
+		&i.Version,
+		&i.Status,
+		&i.StatusReason,
+		&i.PurgeAfter,
+		&i.DeletedAt,
+		&i.DeletionRequestedByUser,
 	)
 	return i, err
 }
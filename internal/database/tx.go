@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// beginner is implemented by pools and connections, and by transactions,
+// which begin a savepoint.
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// InTx runs fn with queries bound to a new transaction, which is committed if
+// fn returns nil, and rolled back otherwise.
+func (q *Queries) InTx(ctx context.Context, fn func(queries *Queries) error) error {
+	db, ok := q.db.(beginner)
+	if !ok {
+		return errors.New("database connection can't begin a transaction")
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(q.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
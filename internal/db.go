@@ -5,20 +5,27 @@ import (
 
 	"github.com/abyanmajid/matcha/logger"
 	"github.com/abyanmajid/thorfinn/internal/database"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// CreateQueryClient connects to the database through a pool, which the
+// handlers, the janitor and the webhook dispatcher share safely.
 func CreateQueryClient(connStr string) (*database.Queries, error) {
-	logger.Debug("Establishing database connection...")
+	logger.Debug("Establishing database connection pool...")
 
-	db, err := pgx.Connect(context.Background(), connStr)
+	pool, err := pgxpool.New(context.Background(), connStr)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Info("Successfully established database connection.")
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	logger.Info("Successfully established database connection pool.")
 
-	queries := database.New(db)
+	queries := database.New(pool)
 
 	return queries, nil
 }
@@ -20,6 +20,58 @@ type EnvConfig struct {
 	JwtSecret        string `name:"JWT_SECRET" required:"true"`
 	EncryptionSecret string `name:"ENCRYPTION_SECRET" required:"true"`
 	EncryptionIv     string `name:"ENCRYPTION_IV" required:"true"`
+
+	PasswordHistorySize          int `name:"PASSWORD_HISTORY_SIZE" default:"5"`
+	PasswordHistoryRetentionDays int `name:"PASSWORD_HISTORY_RETENTION_DAYS" default:"365"`
+
+	Argon2MemoryKib   int `name:"ARGON2_MEMORY_KIB" default:"65536"`
+	Argon2Iterations  int `name:"ARGON2_ITERATIONS" default:"3"`
+	Argon2Parallelism int `name:"ARGON2_PARALLELISM" default:"2"`
+	Argon2SaltLength  int `name:"ARGON2_SALT_LENGTH" default:"16"`
+	Argon2KeyLength   int `name:"ARGON2_KEY_LENGTH" default:"32"`
+
+	AuthResponseFloorMs int `name:"AUTH_RESPONSE_FLOOR_MS" default:"500"`
+
+	TrustedProxies string `name:"TRUSTED_PROXIES"`
+
+	UserDeletionGraceDays  int `name:"USER_DELETION_GRACE_DAYS" default:"30"`
+	JanitorIntervalMinutes int `name:"JANITOR_INTERVAL_MINUTES" default:"60"`
+
+	InvitationExpiryHours int `name:"INVITATION_EXPIRY_HOURS" default:"168"`
+
+	ApiKeyPrefix string `name:"API_KEY_PREFIX" default:"thf_live"`
+
+	AuthzSchemaPath string `name:"AUTHZ_SCHEMA_PATH"`
+
+	ImpersonationSessionMinutes int `name:"IMPERSONATION_SESSION_MINUTES" default:"15"`
+
+	AuditHashChain     bool `name:"AUDIT_HASH_CHAIN" default:"false"`
+	AuditRetentionDays int  `name:"AUDIT_RETENTION_DAYS" default:"0"`
+
+	WebhookIntervalSeconds       int  `name:"WEBHOOK_INTERVAL_SECONDS" default:"10"`
+	WebhookTimeoutMs             int  `name:"WEBHOOK_TIMEOUT_MS" default:"5000"`
+	WebhookMaxAttempts           int  `name:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	WebhookDisableAfterFailures  int  `name:"WEBHOOK_DISABLE_AFTER_FAILURES" default:"20"`
+	WebhookDeliveryRetentionDays int  `name:"WEBHOOK_DELIVERY_RETENTION_DAYS" default:"30"`
+	WebhookAllowPrivateAddresses bool `name:"WEBHOOK_ALLOW_PRIVATE_ADDRESSES" default:"false"`
+
+	RegistrationMode           string `name:"REGISTRATION_MODE" default:"open"`
+	RegistrationAllowedDomains string `name:"REGISTRATION_ALLOWED_DOMAINS"`
+	RegistrationDeniedDomains  string `name:"REGISTRATION_DENIED_DOMAINS"`
+	BlockDisposableEmails      bool   `name:"BLOCK_DISPOSABLE_EMAILS" default:"false"`
+	DisposableEmailDomainsPath string `name:"DISPOSABLE_EMAIL_DOMAINS_PATH"`
+
+	UserMetadataSchemaPath string `name:"USER_METADATA_SCHEMA_PATH"`
+	AppMetadataSchemaPath  string `name:"APP_METADATA_SCHEMA_PATH"`
+	MetadataMaxBytes       int    `name:"METADATA_MAX_BYTES" default:"16384"`
+	UserMetadataClaims     string `name:"USER_METADATA_CLAIMS"`
+	AppMetadataClaims      string `name:"APP_METADATA_CLAIMS"`
+
+	TokenClaims         string `name:"TOKEN_CLAIMS"`
+	ClaimsMaxBytes      int    `name:"CLAIMS_MAX_BYTES" default:"4096"`
+	ClaimsHookUrl       string `name:"CLAIMS_HOOK_URL"`
+	ClaimsHookSecret    string `name:"CLAIMS_HOOK_SECRET"`
+	ClaimsHookTimeoutMs int    `name:"CLAIMS_HOOK_TIMEOUT_MS" default:"2000"`
 }
 
 func ConfigureEnv() (bool, *EnvConfig) {
@@ -38,6 +90,10 @@ func ConfigureEnv() (bool, *EnvConfig) {
 		logger.Fatal("Error loading configuration: %s", err)
 	}
 
+	if _, err := ParseTrustedProxies(config.TrustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	if *dev {
 		logger.Info("Running in development mode")
 	} else {
@@ -1,22 +1,35 @@
 package internal
 
 import (
-	"errors"
-	"net/http"
-
 	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
 )
 
 func GenericError[T any]() *ctx.Response[T] {
+	err := apierror.New(apierror.Internal, "an error occurred while processing your request")
+
+	return &ctx.Response[T]{
+		Error:      err,
+		StatusCode: err.Status(),
+	}
+}
+
+func CustomError[T any](code apierror.Code, message string) *ctx.Response[T] {
+	err := apierror.New(code, message)
+
 	return &ctx.Response[T]{
-		Error:      errors.New("an error occurred while processing your request"),
-		StatusCode: http.StatusInternalServerError,
+		Error:      err,
+		StatusCode: err.Status(),
 	}
 }
 
-func CustomError[T any](message string) *ctx.Response[T] {
+// ApiError responds with err if it is an *apierror.Error, and with a generic
+// error otherwise.
+func ApiError[T any](err error) *ctx.Response[T] {
+	apiErr := apierror.From(err)
+
 	return &ctx.Response[T]{
-		Error:      errors.New(message),
-		StatusCode: http.StatusInternalServerError,
+		Error:      apiErr,
+		StatusCode: apiErr.Status(),
 	}
 }
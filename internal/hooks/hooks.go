@@ -0,0 +1,210 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/matcha/security"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+)
+
+// Hooks lets code embedding Thorfinn run its own logic during the lifecycle of
+// a user, without editing the handlers. Before hooks run before an action and
+// can stop it by returning an error made with Reject. After hooks run once an
+// action is done, so errors they return are logged and don't fail it. Embed
+// Base to only implement the hooks you need.
+type Hooks interface {
+	// BeforeRegister runs before an account is created, by registering or
+	// by accepting an invitation.
+	BeforeRegister(ctx context.Context, registration Registration) error
+	AfterRegister(ctx context.Context, user database.ThorfinnUser) error
+
+	// BeforeLogin runs once the user's password and account are checked,
+	// before their session is created, and before a deletion they requested
+	// is cancelled by the login.
+	BeforeLogin(ctx context.Context, user database.ThorfinnUser) error
+	AfterLogin(ctx context.Context, user database.ThorfinnUser) error
+
+	// BeforeTokenIssue runs before an access token is signed, and can add,
+	// change or remove its custom claims. Changes to reserved claims are
+	// ignored.
+	BeforeTokenIssue(ctx context.Context, user database.ThorfinnUser, claims security.JwtClaims) error
+
+	AfterEmailVerified(ctx context.Context, user database.ThorfinnUser) error
+	AfterEmailChange(ctx context.Context, user database.ThorfinnUser, oldEmail string) error
+	AfterPasswordReset(ctx context.Context, user database.ThorfinnUser) error
+	AfterPasswordChange(ctx context.Context, user database.ThorfinnUser) error
+}
+
+// Registration is an account about to be created. InvitationID is set when it
+// is created by accepting an invitation.
+type Registration struct {
+	Email        string
+	InvitationID string
+}
+
+// Base implements every hook as a no-op.
+type Base struct{}
+
+func (Base) BeforeRegister(context.Context, Registration) error { return nil }
+
+func (Base) AfterRegister(context.Context, database.ThorfinnUser) error { return nil }
+
+func (Base) BeforeLogin(context.Context, database.ThorfinnUser) error { return nil }
+
+func (Base) AfterLogin(context.Context, database.ThorfinnUser) error { return nil }
+
+func (Base) BeforeTokenIssue(context.Context, database.ThorfinnUser, security.JwtClaims) error {
+	return nil
+}
+
+func (Base) AfterEmailVerified(context.Context, database.ThorfinnUser) error { return nil }
+
+func (Base) AfterEmailChange(context.Context, database.ThorfinnUser, string) error { return nil }
+
+func (Base) AfterPasswordReset(context.Context, database.ThorfinnUser) error { return nil }
+
+func (Base) AfterPasswordChange(context.Context, database.ThorfinnUser) error { return nil }
+
+// Rejection is returned by a before hook to stop an action. Its message is
+// shown to the client.
+type Rejection struct {
+	Message string
+}
+
+func (r *Rejection) Error() string {
+	return r.Message
+}
+
+// Reject returns an error that stops the action a before hook runs for, and
+// responds with a 403 and the code hook_rejected.
+func Reject(message string) error {
+	return &Rejection{Message: message}
+}
+
+type namedHooks struct {
+	name  string
+	hooks Hooks
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Hooks{}
+)
+
+// Register registers hooks to run for every user. Register them at startup,
+// before serving requests. Hooks run in the order of their names, and
+// registering hooks under a name that is already taken replaces them.
+func Register(name string, hooks Hooks) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[name] = hooks
+}
+
+func registered() []namedHooks {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]namedHooks, 0, len(names))
+	for _, name := range names {
+		result = append(result, namedHooks{name: name, hooks: registry[name]})
+	}
+
+	return result
+}
+
+// runBefore runs a before hook of every registered hooks, stopping at the
+// first error. A rejection is returned as an API error, and any other error
+// as is.
+func runBefore(hook string, run func(hooks Hooks) error) error {
+	for _, registered := range registered() {
+		err := run(registered.hooks)
+
+		var rejection *Rejection
+		if errors.As(err, &rejection) {
+			return apierror.New(apierror.HookRejected, rejection.Message)
+		}
+		if err != nil {
+			return fmt.Errorf("error running %s hook %s: %v", hook, registered.name, err)
+		}
+	}
+
+	return nil
+}
+
+// runAfter runs an after hook of every registered hooks, logging their errors.
+func runAfter(hook string, run func(hooks Hooks) error) {
+	for _, registered := range registered() {
+		if err := run(registered.hooks); err != nil {
+			logger.Error("Error running %s hook %s: %v", hook, registered.name, err)
+		}
+	}
+}
+
+func BeforeRegister(ctx context.Context, registration Registration) error {
+	return runBefore("BeforeRegister", func(hooks Hooks) error {
+		return hooks.BeforeRegister(ctx, registration)
+	})
+}
+
+func AfterRegister(ctx context.Context, user database.ThorfinnUser) {
+	runAfter("AfterRegister", func(hooks Hooks) error {
+		return hooks.AfterRegister(ctx, user)
+	})
+}
+
+func BeforeLogin(ctx context.Context, user database.ThorfinnUser) error {
+	return runBefore("BeforeLogin", func(hooks Hooks) error {
+		return hooks.BeforeLogin(ctx, user)
+	})
+}
+
+func AfterLogin(ctx context.Context, user database.ThorfinnUser) {
+	runAfter("AfterLogin", func(hooks Hooks) error {
+		return hooks.AfterLogin(ctx, user)
+	})
+}
+
+// BeforeTokenIssue lets every registered hooks change the custom claims of an
+// access token in turn. The claims mapper undoes changes to reserved claims.
+func BeforeTokenIssue(ctx context.Context, user database.ThorfinnUser, claims security.JwtClaims) error {
+	return runBefore("BeforeTokenIssue", func(hooks Hooks) error {
+		return hooks.BeforeTokenIssue(ctx, user, claims)
+	})
+}
+
+func AfterEmailVerified(ctx context.Context, user database.ThorfinnUser) {
+	runAfter("AfterEmailVerified", func(hooks Hooks) error {
+		return hooks.AfterEmailVerified(ctx, user)
+	})
+}
+
+func AfterEmailChange(ctx context.Context, user database.ThorfinnUser, oldEmail string) {
+	runAfter("AfterEmailChange", func(hooks Hooks) error {
+		return hooks.AfterEmailChange(ctx, user, oldEmail)
+	})
+}
+
+func AfterPasswordReset(ctx context.Context, user database.ThorfinnUser) {
+	runAfter("AfterPasswordReset", func(hooks Hooks) error {
+		return hooks.AfterPasswordReset(ctx, user)
+	})
+}
+
+func AfterPasswordChange(ctx context.Context, user database.ThorfinnUser) {
+	runAfter("AfterPasswordChange", func(hooks Hooks) error {
+		return hooks.AfterPasswordChange(ctx, user)
+	})
+}
@@ -0,0 +1,151 @@
+package invitations
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/abyanmajid/matcha/email"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrInvalid  = apierror.New(apierror.InvalidToken, "invitation is invalid or has expired")
+	ErrNotFound = apierror.New(apierror.InvitationNotFound, "invitation not found")
+)
+
+// Params describes an invitation to send. Invitations to the instance have
+// no organization, and their role, if any, is a global role. Invitations to an
+// organization carry the role the invitee gets in it.
+type Params struct {
+	Email        string
+	Organization *database.ThorfinnOrganization
+	Role         string
+	InvitedBy    database.ThorfinnUser
+}
+
+// Inviter creates invitations and emails their single-use links. Only the
+// SHA-256 hash of an invitation token is stored.
+type Inviter struct {
+	config  *internal.EnvConfig
+	queries *database.Queries
+	mailer  *email.Client
+}
+
+func NewInviter(config *internal.EnvConfig, queries *database.Queries, mailer *email.Client) *Inviter {
+	return &Inviter{
+		config:  config,
+		queries: queries,
+		mailer:  mailer,
+	}
+}
+
+// Invite replaces any pending invitation of the same email to the same
+// organization, or to the instance, with a new one and emails it.
+func (i *Inviter) Invite(ctx context.Context, params Params) (database.ThorfinnInvitation, error) {
+	organizationId := pgtype.Text{}
+	organizationName := ""
+	if params.Organization != nil {
+		organizationId = pgtype.Text{String: params.Organization.ID, Valid: true}
+		organizationName = params.Organization.Name
+	}
+
+	err := i.queries.DeletePendingInvitations(ctx, database.DeletePendingInvitationsParams{
+		Email:          params.Email,
+		OrganizationID: organizationId,
+	})
+	if err != nil {
+		return database.ThorfinnInvitation{}, fmt.Errorf("error deleting pending invitations: %v", err)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return database.ThorfinnInvitation{}, fmt.Errorf("error generating token: %v", err)
+	}
+
+	invitation, err := i.queries.CreateInvitation(ctx, database.CreateInvitationParams{
+		ID:             uuid.New().String(),
+		Email:          params.Email,
+		OrganizationID: organizationId,
+		Role:           pgtype.Text{String: params.Role, Valid: params.Role != ""},
+		TokenHash:      HashToken(token),
+		InvitedBy:      pgtype.Text{String: params.InvitedBy.ID, Valid: true},
+		ExpiresAt:      pgtype.Timestamptz{Time: time.Now().Add(time.Duration(i.config.InvitationExpiryHours) * time.Hour), Valid: true},
+	})
+	if err != nil {
+		return database.ThorfinnInvitation{}, fmt.Errorf("error creating invitation: %v", err)
+	}
+
+	internal.SendEmailAsync(i.mailer, i.config.EmailFrom, []string{params.Email}, "You Have Been Invited", "invitation", map[string]any{
+		"InvitationLink":   fmt.Sprintf("%s/auth/accept-invitation?token=%s", i.config.FrontendUrl, token),
+		"OrganizationName": organizationName,
+		"InviterEmail":     params.InvitedBy.Email,
+		"ExpiresAt":        invitation.ExpiresAt.Time.UTC().Format(time.RFC1123),
+	})
+
+	return invitation, nil
+}
+
+// Find returns the pending invitation a token was issued for, or ErrInvalid.
+func (i *Inviter) Find(ctx context.Context, token string) (database.ThorfinnInvitation, error) {
+	invitation, err := i.queries.FindInvitationByTokenHash(ctx, HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.ThorfinnInvitation{}, ErrInvalid
+	}
+	if err != nil {
+		return database.ThorfinnInvitation{}, fmt.Errorf("error finding invitation: %v", err)
+	}
+
+	if invitation.AcceptedAt.Valid || invitation.ExpiresAt.Time.Before(time.Now()) {
+		return database.ThorfinnInvitation{}, ErrInvalid
+	}
+
+	return invitation, nil
+}
+
+// WithTx returns a copy of the inviter that runs its queries with the given
+// ones, such as queries bound to a transaction.
+func (i *Inviter) WithTx(queries *database.Queries) *Inviter {
+	inviter := *i
+	inviter.queries = queries
+	return &inviter
+}
+
+// Accept marks an invitation as accepted. It returns ErrInvalid if the
+// invitation was accepted by a concurrent request, or has expired since it
+// was found.
+func (i *Inviter) Accept(ctx context.Context, invitation database.ThorfinnInvitation) error {
+	_, err := i.queries.AcceptInvitation(ctx, invitation.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("error accepting invitation: %v", err)
+	}
+
+	return nil
+}
+
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func generateToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ClientIp returns the IP address of the client of r. X-Forwarded-For is only
+// trusted when the request comes from one of TRUSTED_PROXIES, and is then read
+// from the right, skipping the trusted proxies, so that clients can't choose
+// their address by sending the header themselves.
+func ClientIp(config *EnvConfig, r *http.Request) string {
+	remote := remoteIp(r)
+
+	proxies, _ := ParseTrustedProxies(config.TrustedProxies)
+	if !isTrusted(proxies, remote) {
+		return remote
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+
+		if !isTrusted(proxies, hop) {
+			return hop
+		}
+	}
+
+	return remote
+}
+
+// ParseTrustedProxies parses a comma-separated list of IP addresses and CIDR
+// ranges.
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	proxies := []netip.Prefix{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			proxies = append(proxies, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		proxies = append(proxies, prefix.Masked())
+	}
+
+	return proxies, nil
+}
+
+func remoteIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func isTrusted(proxies []netip.Prefix, address string) bool {
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if proxy.Contains(ip.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,263 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema used to describe metadata documents. It
+// supports type, enum, properties, required, additionalProperties,
+// maxProperties, items, minItems, maxItems, minLength, maxLength, pattern,
+// minimum and maximum. Other keywords are ignored.
+type Schema struct {
+	Type                 types              `json:"type"`
+	Enum                 []any              `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *additional        `json:"additionalProperties"`
+	MaxProperties        *int               `json:"maxProperties"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+
+	pattern *regexp.Regexp
+}
+
+// types is the type keyword, which may be a single type or a list of types.
+type types []string
+
+func (t *types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = types{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+
+	*t = list
+	return nil
+}
+
+// additional is the additionalProperties keyword, which may be a boolean or a
+// schema that every additional property must match.
+type additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *additional) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		a.Allowed = allowed
+		return nil
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("additionalProperties must be a boolean or a schema")
+	}
+
+	a.Allowed = true
+	a.Schema = &schema
+	return nil
+}
+
+// Load reads and compiles the schema in the file at path. It returns a nil
+// schema, which accepts every document, if path is empty.
+func Load(path string) (*Schema, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema: %v", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse compiles a schema from its JSON encoding.
+func Parse(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %v", err)
+	}
+
+	if err := schema.compile(); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+func (s *Schema) compile() error {
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("error compiling pattern %q: %v", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+
+	children := []*Schema{s.Items}
+	for _, property := range s.Properties {
+		children = append(children, property)
+	}
+	if s.AdditionalProperties != nil {
+		children = append(children, s.AdditionalProperties.Schema)
+	}
+
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks a decoded JSON document against the schema and returns the
+// failures keyed by the dotted path of the offending value, with root as the
+// path of the document itself. A nil schema accepts every document.
+func (s *Schema) Validate(root string, document any) map[string][]string {
+	fields := map[string][]string{}
+	if s != nil {
+		s.validate(root, document, fields)
+	}
+
+	return fields
+}
+
+func (s *Schema) validate(path string, value any, fields map[string][]string) {
+	fail := func(format string, args ...any) {
+		fields[path] = append(fields[path], fmt.Sprintf(format, args...))
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(name string) bool { return isType(value, name) }) {
+		fail("Must be of type %s", strings.Join(s.Type, " or "))
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(allowed any) bool { return equal(allowed, value) }) {
+		fail("Must be one of the allowed values")
+	}
+
+	switch value := value.(type) {
+	case string:
+		length := len([]rune(value))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("Must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("Must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(value) {
+			fail("Must match the pattern %s", s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && value < *s.Minimum {
+			fail("Must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && value > *s.Maximum {
+			fail("Must be at most %v", *s.Maximum)
+		}
+	case []any:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			fail("Must contain at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			fail("Must contain at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range value {
+				s.Items.validate(fmt.Sprintf("%s.%d", path, i), item, fields)
+			}
+		}
+	case map[string]any:
+		if s.MaxProperties != nil && len(value) > *s.MaxProperties {
+			fail("Must contain at most %d properties", *s.MaxProperties)
+		}
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				fields[path+"."+name] = append(fields[path+"."+name], "This field is required")
+			}
+		}
+
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			childPath := path + "." + name
+			if property, ok := s.Properties[name]; ok {
+				property.validate(childPath, value[name], fields)
+				continue
+			}
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if !s.AdditionalProperties.Allowed {
+				fields[childPath] = append(fields[childPath], "This field is not allowed")
+				continue
+			}
+			if s.AdditionalProperties.Schema != nil {
+				s.AdditionalProperties.Schema.validate(childPath, value[name], fields)
+			}
+		}
+	}
+}
+
+func isType(value any, name string) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return false
+	}
+}
+
+func equal(a any, b any) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
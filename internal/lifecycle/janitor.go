@@ -0,0 +1,113 @@
+package lifecycle
+
+import (
+	"context"
+	"time"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/audit"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/webhooks"
+)
+
+const purgeBatchSize = 100
+
+// Janitor periodically purges accounts whose deletion grace period is over,
+// and deletes expired OTP codes, invitations, audit events, and webhook
+// deliveries.
+type Janitor struct {
+	config     *internal.EnvConfig
+	queries    *database.Queries
+	auditor    *audit.Auditor
+	dispatcher *webhooks.Dispatcher
+}
+
+func NewJanitor(config *internal.EnvConfig, queries *database.Queries) *Janitor {
+	return &Janitor{
+		config:     config,
+		queries:    queries,
+		auditor:    audit.NewAuditor(config, queries),
+		dispatcher: webhooks.NewDispatcher(config, queries),
+	}
+}
+
+// Start runs the janitor in the background until ctx is done. It does nothing
+// if JANITOR_INTERVAL_MINUTES is 0 or less.
+func (j *Janitor) Start(ctx context.Context) {
+	if j.config.JanitorIntervalMinutes <= 0 {
+		logger.Info("Janitor is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(j.config.JanitorIntervalMinutes) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			j.Sweep(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Sweep purges every account that is due, and deletes expired OTP codes,
+// invitations, audit events, and webhook deliveries.
+func (j *Janitor) Sweep(ctx context.Context) {
+	logger.Debug("Janitor: purging accounts due for deletion")
+
+	for {
+		userIds, err := j.queries.ListUsersDueForPurge(ctx, purgeBatchSize)
+		if err != nil {
+			logger.Error("Janitor: error listing accounts due for deletion: %v", err)
+			break
+		}
+
+		for _, userId := range userIds {
+			user, err := j.queries.PurgeUser(ctx, userId)
+			if err != nil {
+				logger.Error("Janitor: error purging account %s: %v", userId, err)
+				return
+			}
+			logger.Info("Janitor: purged account %s", userId)
+			j.auditor.RecordSystem(ctx, audit.Event{
+				Action:       audit.ActionUserPurged,
+				TargetUserID: userId,
+			})
+			j.dispatcher.Publish(ctx, webhooks.EventUserDeleted, user, nil)
+		}
+
+		if len(userIds) < purgeBatchSize {
+			break
+		}
+	}
+
+	logger.Debug("Janitor: deleting expired OTP codes")
+	err := j.queries.DeleteExpiredOtpCodes(ctx)
+	if err != nil {
+		logger.Error("Janitor: error deleting expired OTP codes: %v", err)
+	}
+
+	logger.Debug("Janitor: deleting expired invitations")
+	err = j.queries.DeleteExpiredInvitations(ctx)
+	if err != nil {
+		logger.Error("Janitor: error deleting expired invitations: %v", err)
+	}
+
+	logger.Debug("Janitor: deleting expired audit events")
+	err = j.auditor.PurgeExpired(ctx)
+	if err != nil {
+		logger.Error("Janitor: error deleting expired audit events: %v", err)
+	}
+
+	logger.Debug("Janitor: deleting expired webhook deliveries")
+	err = j.dispatcher.PurgeExpired(ctx)
+	if err != nil {
+		logger.Error("Janitor: error deleting expired webhook deliveries: %v", err)
+	}
+}
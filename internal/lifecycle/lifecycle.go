@@ -0,0 +1,83 @@
+package lifecycle
+
+import (
+	"time"
+
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+)
+
+// An account is active until an admin suspends it or schedules it for
+// deletion. Suspended and pending accounts can be restored. Once the deletion
+// grace period is over, the account is purged: its personal data is erased
+// and it is left as a deleted tombstone, which can't be restored. When
+// registration requires approval, accounts start pending approval and become
+// active once an admin approves them.
+const (
+	StatusActive          = "active"
+	StatusPendingApproval = "pending_approval"
+	StatusSuspended       = "suspended"
+	StatusPendingDeletion = "pending_deletion"
+	StatusDeleted         = "deleted"
+)
+
+// ReasonUserRequested is the status reason of accounts that their own user
+// scheduled for deletion, which also have DeletionRequestedByUser set. Unlike
+// deletions made by an admin, these are cancelled when the user logs in
+// during the grace period.
+const ReasonUserRequested = "deletion requested by the user"
+
+var Statuses = []string{StatusActive, StatusPendingApproval, StatusSuspended, StatusPendingDeletion, StatusDeleted}
+
+var (
+	ErrApprovalPending  = apierror.New(apierror.ApprovalPending, "this account is awaiting approval")
+	ErrAccountSuspended = apierror.New(apierror.AccountSuspended, "this account has been suspended")
+	ErrAccountDeleted   = apierror.New(apierror.AccountDeleted, "this account has been deleted")
+)
+
+// Check returns an error describing why user can't authenticate, or nil if
+// the account is active.
+func Check(user database.ThorfinnUser) error {
+	switch user.Status {
+	case StatusActive:
+		return nil
+	case StatusPendingApproval:
+		return ErrApprovalPending
+	case StatusSuspended:
+		return ErrAccountSuspended
+	default:
+		return ErrAccountDeleted
+	}
+}
+
+// CheckVerification is like Check, but lets accounts awaiting approval verify
+// their email, so that they are ready to log in once approved.
+func CheckVerification(user database.ThorfinnUser) error {
+	if user.Status == StatusPendingApproval {
+		return nil
+	}
+
+	return Check(user)
+}
+
+// CanVerifyEmail reports whether user can be sent an email verification link.
+func CanVerifyEmail(user database.ThorfinnUser) bool {
+	return CheckVerification(user) == nil
+}
+
+// IsActive reports whether user can authenticate.
+func IsActive(user database.ThorfinnUser) bool {
+	return user.Status == StatusActive
+}
+
+// CancelsOnLogin reports whether logging in cancels the deletion of user.
+func CancelsOnLogin(user database.ThorfinnUser) bool {
+	return user.Status == StatusPendingDeletion && user.DeletionRequestedByUser
+}
+
+// PurgeAfter returns when an account scheduled for deletion now should be
+// purged.
+func PurgeAfter(config *internal.EnvConfig) time.Time {
+	return time.Now().Add(time.Duration(config.UserDeletionGraceDays) * 24 * time.Hour)
+}
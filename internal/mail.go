@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/abyanmajid/matcha/email"
+	"github.com/abyanmajid/matcha/logger"
+)
+
+// Emails are sent by a fixed number of workers from a bounded queue, so that
+// a burst of requests can't start an unbounded number of SMTP connections.
+const (
+	emailWorkers   = 4
+	emailQueueSize = 1000
+)
+
+type outgoingEmail struct {
+	mailer       *email.Client
+	from         string
+	to           []string
+	subject      string
+	templateName string
+	data         map[string]any
+}
+
+var (
+	emailQueue     = make(chan outgoingEmail, emailQueueSize)
+	emailQueueOnce sync.Once
+)
+
+// SendEmailAsync queues an email to be sent in the background and only logs
+// failures, so that neither the SMTP round trip nor its outcome shows up in
+// the response. If the queue is full, the email is dropped rather than
+// making the request wait.
+func SendEmailAsync(mailer *email.Client, from string, to []string, subject string, templateName string, data map[string]any) {
+	emailQueueOnce.Do(startEmailWorkers)
+
+	select {
+	case emailQueue <- outgoingEmail{mailer, from, to, subject, templateName, data}:
+	default:
+		logger.Error("Dropping %s email: the email queue is full", templateName)
+	}
+}
+
+func startEmailWorkers() {
+	for range emailWorkers {
+		go func() {
+			for queued := range emailQueue {
+				err := queued.mailer.SendEmail(queued.from, queued.to, queued.subject, queued.templateName, queued.data)
+				if err != nil {
+					logger.Error("Error sending %s email: %v", queued.templateName, err)
+				}
+			}
+		}()
+	}
+}
@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/jsonschema"
+)
+
+const (
+	UserMetadataField = "user_metadata"
+	AppMetadataField  = "app_metadata"
+)
+
+// Validator checks metadata documents against the configured size limit and
+// JSON Schemas before they are stored. user_metadata can be edited by the user
+// it belongs to, while app_metadata can only be edited by admins.
+type Validator struct {
+	maxBytes   int
+	userSchema *jsonschema.Schema
+	appSchema  *jsonschema.Schema
+}
+
+func NewValidator(config *internal.EnvConfig) *Validator {
+	userSchema, err := jsonschema.Load(config.UserMetadataSchemaPath)
+	if err != nil {
+		logger.Fatal("Error loading user metadata schema: %v", err)
+	}
+
+	appSchema, err := jsonschema.Load(config.AppMetadataSchemaPath)
+	if err != nil {
+		logger.Fatal("Error loading app metadata schema: %v", err)
+	}
+
+	return &Validator{
+		maxBytes:   config.MetadataMaxBytes,
+		userSchema: userSchema,
+		appSchema:  appSchema,
+	}
+}
+
+// ValidateUserMetadata validates a user_metadata document and returns its
+// encoding, ready to be stored.
+func (v *Validator) ValidateUserMetadata(document map[string]any) ([]byte, error) {
+	return v.validate(UserMetadataField, v.userSchema, document)
+}
+
+// ValidateAppMetadata validates an app_metadata document and returns its
+// encoding, ready to be stored.
+func (v *Validator) ValidateAppMetadata(document map[string]any) ([]byte, error) {
+	return v.validate(AppMetadataField, v.appSchema, document)
+}
+
+func (v *Validator) validate(field string, schema *jsonschema.Schema, document map[string]any) ([]byte, error) {
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return nil, apierror.Validation(map[string][]string{field: {"Must be a JSON object"}})
+	}
+
+	if v.maxBytes > 0 && len(encoded) > v.maxBytes {
+		return nil, apierror.Validation(map[string][]string{field: {fmt.Sprintf("Must be at most %d bytes", v.maxBytes)}})
+	}
+
+	// Validate the document as it will be stored, so that numbers are checked
+	// the same way they'll be read back.
+	var decoded any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %v", field, err)
+	}
+
+	fields := schema.Validate(field, decoded)
+	if len(fields) > 0 {
+		return nil, apierror.Validation(fields)
+	}
+
+	return encoded, nil
+}
+
+// Decode returns the stored metadata document, or an empty one if it can't be
+// decoded.
+func Decode(document []byte) map[string]any {
+	decoded := map[string]any{}
+	if len(document) == 0 {
+		return decoded
+	}
+
+	if err := json.Unmarshal(document, &decoded); err != nil || decoded == nil {
+		logger.Error("Error decoding metadata: %v", err)
+		return map[string]any{}
+	}
+
+	return decoded
+}
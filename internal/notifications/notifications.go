@@ -0,0 +1,108 @@
+package notifications
+
+import (
+	"slices"
+	"time"
+
+	"github.com/abyanmajid/matcha/email"
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+)
+
+type Kind string
+
+const (
+	KindNewLogin          Kind = "new_login"
+	KindPasswordChanged   Kind = "password_changed"
+	KindEmailChanged      Kind = "email_changed"
+	KindTwoFactorEnabled  Kind = "two_factor_enabled"
+	KindTwoFactorDisabled Kind = "two_factor_disabled"
+
+	KindDeletionScheduled Kind = "account_deletion_scheduled"
+	KindDeletionCancelled Kind = "account_deletion_cancelled"
+	KindAccountApproved   Kind = "account_approved"
+)
+
+var ErrNotOptional = apierror.New(apierror.NotificationNotOptional, "only new_login and two_factor_enabled notifications can be turned off")
+
+type event struct {
+	subject  string
+	optional bool
+}
+
+// Every kind is rendered from the template of the same name. Critical events
+// are always sent; optional ones respect the user's notification opt-outs.
+var events = map[Kind]event{
+	KindNewLogin:          {subject: "New Login to Your Account", optional: true},
+	KindPasswordChanged:   {subject: "Your Password Was Changed"},
+	KindEmailChanged:      {subject: "Your Email Was Changed"},
+	KindTwoFactorEnabled:  {subject: "Two-Factor Authentication Enabled", optional: true},
+	KindTwoFactorDisabled: {subject: "Two-Factor Authentication Disabled"},
+	KindDeletionScheduled: {subject: "Your Account Will Be Deleted"},
+	KindDeletionCancelled: {subject: "Your Account Deletion Was Cancelled"},
+	KindAccountApproved:   {subject: "Your Account Has Been Approved"},
+}
+
+// Details describes where an event originated from. Empty fields are left out
+// of the email.
+type Details struct {
+	IpAddress string
+	UserAgent string
+	NewEmail  string
+	DeleteAt  time.Time
+}
+
+// ValidateOptOuts returns ErrNotOptional if any of the given kinds is unknown
+// or cannot be turned off.
+func ValidateOptOuts(kinds []string) error {
+	for _, kind := range kinds {
+		if !events[Kind(kind)].optional {
+			return ErrNotOptional
+		}
+	}
+
+	return nil
+}
+
+type Notifier struct {
+	config *internal.EnvConfig
+	mailer *email.Client
+}
+
+func NewNotifier(config *internal.EnvConfig, mailer *email.Client) *Notifier {
+	return &Notifier{
+		config: config,
+		mailer: mailer,
+	}
+}
+
+// Notify emails the user about a security event. The email is sent in the
+// background so that it never blocks or fails the request that triggered it.
+func (n *Notifier) Notify(user database.ThorfinnUser, kind Kind, details Details) {
+	event, ok := events[kind]
+	if !ok {
+		logger.Error("Unknown notification kind: %s", kind)
+		return
+	}
+
+	if event.optional && slices.Contains(user.NotificationOptOuts, string(kind)) {
+		logger.Debug("User has opted out of %s notifications", kind)
+		return
+	}
+
+	data := map[string]any{
+		"OccurredAt": time.Now().UTC().Format(time.RFC1123),
+		"IpAddress":  details.IpAddress,
+		"UserAgent":  details.UserAgent,
+		"NewEmail":   details.NewEmail,
+		"DeleteAt":   "",
+	}
+
+	if !details.DeleteAt.IsZero() {
+		data["DeleteAt"] = details.DeleteAt.UTC().Format(time.RFC1123)
+	}
+
+	internal.SendEmailAsync(n.mailer, n.config.EmailFrom, []string{user.Email}, event.subject, string(kind), data)
+}
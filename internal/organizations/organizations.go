@@ -0,0 +1,30 @@
+package organizations
+
+import (
+	"slices"
+
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+)
+
+// Roles a user can have in an organization, from most to least privileged.
+// Owners can do everything admins can, and are the only ones who can grant or
+// revoke the owner role and delete the organization. Admins manage members.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+var Roles = []string{RoleOwner, RoleAdmin, RoleMember}
+
+var (
+	ErrNotFound  = apierror.New(apierror.OrganizationNotFound, "organization not found")
+	ErrForbidden = apierror.New(apierror.Forbidden, "you do not have permission to perform this action")
+	ErrLastOwner = apierror.New(apierror.LastOwner, "an organization must keep at least one owner")
+)
+
+// AtLeast reports whether role is as privileged as min or more.
+func AtLeast(role string, min string) bool {
+	rank := slices.Index(Roles, role)
+	return rank >= 0 && rank <= slices.Index(Roles, min)
+}
@@ -0,0 +1,117 @@
+package password
+
+import (
+	"crypto/rand"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+)
+
+var (
+	ErrMismatchedHashAndPassword = errors.New("hashed password does not match the provided password")
+	ErrUnknownHashFormat         = errors.New("password hash is not in a recognized format")
+)
+
+// Algorithm is a password hashing scheme that can recognize and verify its
+// own encoded hashes.
+type Algorithm interface {
+	Identifies(encoded string) bool
+	Verify(encoded string, password string) error
+}
+
+// Hasher hashes new passwords with argon2id and verifies passwords against any
+// of the supported encoded formats, so that hashes produced by older
+// algorithms or parameters keep working until they can be upgraded.
+type Hasher struct {
+	primary    *Argon2id
+	algorithms []Algorithm
+	dummyOnce  sync.Once
+	dummyHash  string
+}
+
+func NewHasher(config *internal.EnvConfig) *Hasher {
+	checkArgon2Config(config)
+
+	primary := NewArgon2id(Argon2idParams{
+		Memory:      uint32(config.Argon2MemoryKib),
+		Iterations:  uint32(config.Argon2Iterations),
+		Parallelism: uint8(config.Argon2Parallelism),
+		SaltLength:  uint32(config.Argon2SaltLength),
+		KeyLength:   uint32(config.Argon2KeyLength),
+	})
+
+	return &Hasher{
+		primary: primary,
+		algorithms: []Algorithm{
+			primary,
+			&Bcrypt{},
+			&Pbkdf2{},
+		},
+	}
+}
+
+// checkArgon2Config exits if an ARGON2_* variable is out of range, rather than
+// letting it wrap around when converted, or produce weak hashes.
+func checkArgon2Config(config *internal.EnvConfig) {
+	checkRange("ARGON2_PARALLELISM", config.Argon2Parallelism, 1, math.MaxUint8)
+	checkRange("ARGON2_MEMORY_KIB", config.Argon2MemoryKib, 8*config.Argon2Parallelism, 4*1024*1024)
+	checkRange("ARGON2_ITERATIONS", config.Argon2Iterations, 1, 1000)
+	checkRange("ARGON2_SALT_LENGTH", config.Argon2SaltLength, 8, 1024)
+	checkRange("ARGON2_KEY_LENGTH", config.Argon2KeyLength, 16, 1024)
+}
+
+func checkRange(name string, value int, min int, max int) {
+	if value < min || value > max {
+		logger.Fatal("Invalid %s %d: must be between %d and %d", name, value, min, max)
+	}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.primary.Hash(password)
+}
+
+func (h *Hasher) Verify(encoded string, password string) error {
+	algorithm, err := h.identify(encoded)
+	if err != nil {
+		return err
+	}
+
+	return algorithm.Verify(encoded, password)
+}
+
+// VerifyDummy verifies the password against a throwaway hash produced with the
+// current parameters and discards the result. Call it when there is no user to
+// verify against, so that the request takes as long as it would for a real one.
+func (h *Hasher) VerifyDummy(password string) {
+	h.dummyOnce.Do(func() {
+		secret := make([]byte, 32)
+		_, _ = rand.Read(secret)
+
+		h.dummyHash, _ = h.primary.Hash(string(secret))
+	})
+
+	_ = h.Verify(h.dummyHash, password)
+}
+
+// NeedsRehash reports whether the encoded hash was produced by an algorithm
+// or with parameters other than the ones currently configured.
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	if !h.primary.Identifies(encoded) {
+		return true
+	}
+
+	return !h.primary.HasCurrentParams(encoded)
+}
+
+func (h *Hasher) identify(encoded string) (Algorithm, error) {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Identifies(encoded) {
+			return algorithm, nil
+		}
+	}
+
+	return nil, ErrUnknownHashFormat
+}
@@ -0,0 +1,96 @@
+package password
+
+import (
+	"context"
+	"time"
+
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrPasswordReused = apierror.New(apierror.PasswordReused, "this password has been used recently, please choose a different one")
+
+// History keeps track of the last PASSWORD_HISTORY_SIZE password hashes of
+// every user, for at most PASSWORD_HISTORY_RETENTION_DAYS, so that a password
+// change can be rejected when it reuses one of them. A size of 0 disables it.
+type History struct {
+	config  *internal.EnvConfig
+	queries *database.Queries
+	hasher  *Hasher
+}
+
+func NewHistory(config *internal.EnvConfig, queries *database.Queries, hasher *Hasher) *History {
+	return &History{
+		config:  config,
+		queries: queries,
+		hasher:  hasher,
+	}
+}
+
+func (h *History) enabled() bool {
+	return h.config.PasswordHistorySize > 0
+}
+
+func (h *History) cutoff() pgtype.Timestamptz {
+	retention := time.Duration(h.config.PasswordHistoryRetentionDays) * 24 * time.Hour
+	return pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true}
+}
+
+// Check returns ErrPasswordReused if the given plaintext password matches one
+// of the user's recent passwords.
+func (h *History) Check(ctx context.Context, userId string, password string) error {
+	if !h.enabled() {
+		return nil
+	}
+
+	entries, err := h.queries.ListRecentPasswordHistory(ctx, database.ListRecentPasswordHistoryParams{
+		UserID:    userId,
+		CreatedAt: h.cutoff(),
+		Limit:     int32(h.config.PasswordHistorySize),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if h.hasher.Verify(entry.PasswordHash, password) == nil {
+			return ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+// WithTx returns a copy of the history that runs its queries with the given
+// ones, such as queries bound to a transaction.
+func (h *History) WithTx(queries *database.Queries) *History {
+	history := *h
+	history.queries = queries
+	return &history
+}
+
+// Record stores a newly set password hash and prunes the entries that fall
+// outside of the configured size or retention period.
+func (h *History) Record(ctx context.Context, userId string, passwordHash string) error {
+	if !h.enabled() {
+		return nil
+	}
+
+	_, err := h.queries.CreatePasswordHistory(ctx, database.CreatePasswordHistoryParams{
+		ID:           uuid.New().String(),
+		UserID:       userId,
+		PasswordHash: passwordHash,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.queries.PrunePasswordHistory(ctx, database.PrunePasswordHistoryParams{
+		UserID:    userId,
+		CreatedAt: h.cutoff(),
+		Limit:     int32(h.config.PasswordHistorySize),
+	})
+}
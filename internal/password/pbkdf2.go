@@ -0,0 +1,25 @@
+package password
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/abyanmajid/matcha/security"
+)
+
+// Pbkdf2 verifies hashes produced by security.Hash, which Thorfinn used before
+// switching to argon2id. It is never used to produce new hashes.
+type Pbkdf2 struct{}
+
+func (p *Pbkdf2) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, security.HashVersion+"$")
+}
+
+func (p *Pbkdf2) Verify(encoded string, password string) error {
+	err := security.VerifyHash([]byte(encoded), []byte(password))
+	if errors.Is(err, security.ErrMismatchedHashAndPassword) {
+		return ErrMismatchedHashAndPassword
+	}
+
+	return err
+}
@@ -0,0 +1,170 @@
+package registration
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/lifecycle"
+)
+
+// Modes in which registration can run. Open and approval modes let anyone
+// register, but accounts registered in approval mode can only log in once an
+// admin approves them. Invite-only mode only lets invited users create an
+// account, and closed mode lets no one.
+const (
+	ModeOpen       = "open"
+	ModeApproval   = "approval"
+	ModeInviteOnly = "invite_only"
+	ModeClosed     = "closed"
+)
+
+var Modes = []string{ModeOpen, ModeApproval, ModeInviteOnly, ModeClosed}
+
+var (
+	ErrClosed             = apierror.New(apierror.RegistrationClosed, "registration is closed")
+	ErrInvitationRequired = apierror.New(apierror.InvitationRequired, "registration requires an invitation")
+	ErrDomainNotAllowed   = apierror.New(apierror.EmailDomainNotAllowed, "emails from this domain are not allowed")
+	ErrDisposableEmail    = apierror.New(apierror.DisposableEmail, "disposable email addresses are not allowed")
+)
+
+//go:embed disposable_domains.txt
+var bundledDisposableDomains string
+
+// Policy decides who can register, and with which email addresses. Its checks
+// only depend on the request, never on existing accounts, so that they don't
+// reveal which emails are registered.
+type Policy struct {
+	mode              string
+	allowedDomains    []string
+	deniedDomains     []string
+	disposableDomains []string
+}
+
+func NewPolicy(config *internal.EnvConfig) *Policy {
+	if !slices.Contains(Modes, config.RegistrationMode) {
+		logger.Fatal("Invalid REGISTRATION_MODE %q: must be one of %s", config.RegistrationMode, strings.Join(Modes, ", "))
+	}
+
+	disposableDomains := []string{}
+	if config.BlockDisposableEmails {
+		var err error
+		disposableDomains, err = loadDomains(config.DisposableEmailDomainsPath)
+		if err != nil {
+			logger.Fatal("Error loading disposable email domains: %v", err)
+		}
+	}
+
+	return &Policy{
+		mode:              config.RegistrationMode,
+		allowedDomains:    parseDomains(config.RegistrationAllowedDomains),
+		deniedDomains:     parseDomains(config.RegistrationDeniedDomains),
+		disposableDomains: disposableDomains,
+	}
+}
+
+// CheckMode returns an error if the registration mode doesn't let users
+// register by themselves.
+func (p *Policy) CheckMode() error {
+	switch p.mode {
+	case ModeClosed:
+		return ErrClosed
+	case ModeInviteOnly:
+		return ErrInvitationRequired
+	default:
+		return nil
+	}
+}
+
+// CheckEmail returns an error if accounts can't use email, because its domain
+// isn't in the allowlist, is in the denylist, or is disposable.
+func (p *Policy) CheckEmail(email string) error {
+	at := strings.LastIndex(email, "@")
+	domain := strings.ToLower(email[at+1:])
+
+	if len(p.allowedDomains) > 0 && !matchesAny(domain, p.allowedDomains) {
+		return ErrDomainNotAllowed
+	}
+
+	if matchesAny(domain, p.deniedDomains) {
+		return ErrDomainNotAllowed
+	}
+
+	if matchesAny(domain, p.disposableDomains) {
+		return ErrDisposableEmail
+	}
+
+	return nil
+}
+
+// InitialStatus returns the status of newly registered accounts.
+func (p *Policy) InitialStatus() string {
+	if p.mode == ModeApproval {
+		return lifecycle.StatusPendingApproval
+	}
+
+	return lifecycle.StatusActive
+}
+
+// matchesAny reports whether domain is one of domains, or a subdomain of one.
+func matchesAny(domain string, domains []string) bool {
+	for _, candidate := range domains {
+		if domain == candidate || strings.HasSuffix(domain, "."+candidate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseDomains(value string) []string {
+	domains := []string{}
+	for _, domain := range strings.Split(value, ",") {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+
+	return domains
+}
+
+// loadDomains reads a list of domains, one per line, from path, or from the
+// bundled list if path is empty. Blank lines and lines starting with # are
+// ignored.
+func loadDomains(path string) ([]string, error) {
+	var reader io.Reader = strings.NewReader(bundledDisposableDomains)
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		reader = file
+	}
+
+	domains := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		domains = append(domains, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	return domains, nil
+}
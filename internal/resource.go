@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/abyanmajid/matcha/ctx"
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/matcha/openapi"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/validation"
+)
+
+// NewResource builds a resource like openapi.NewResource, but serves it with
+// a handler that responds to errors with the status code of their
+// apierror.Code and the apierror.Envelope body, instead of always with 400.
+//
+// Request bodies are validated against the validate tags of Req before the
+// handler is invoked. The JSON schema of the request body is rebuilt from Req
+// so that pointer, slice and map fields have their real types. The vendored
+// OpenAPI schema can't express anything beyond the type of each property, so
+// the constraints themselves, such as which fields are required, are appended
+// to the description.
+func NewResource[Req any, Res any](name string, doc openapi.ResourceDoc, handler func(c *ctx.Request[Req]) *ctx.Response[Res]) openapi.Resource {
+	var req Req
+	if constraints := validation.Describe(req); constraints != "" {
+		doc.Description = doc.Description + "\n\n" + constraints
+	}
+
+	if media, ok := doc.Schema.RequestBody.Content["application/json"]; ok && media != nil {
+		if schema := requestSchema(reflect.TypeOf(req)); len(schema.Properties) > 0 {
+			doc.Schema.RequestBody.Content = openapi.Json(schema)
+		}
+	}
+
+	resource := openapi.NewResource(name, doc, handler)
+	resource.Handler = newHandler(handler)
+
+	return resource
+}
+
+// requestSchema describes the JSON encoding of a request body type, with the
+// properties of nested structs.
+func requestSchema(t reflect.Type) *openapi.ContentSchema {
+	schema := &openapi.ContentSchema{Type: "object", Properties: map[string]*openapi.ContentSchema{}}
+	if t == nil || t.Kind() != reflect.Struct {
+		return schema
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = propertySchema(field.Type)
+	}
+
+	return schema
+}
+
+func propertySchema(t reflect.Type) *openapi.ContentSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &openapi.ContentSchema{Type: "string"}
+	case reflect.Bool:
+		return &openapi.ContentSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &openapi.ContentSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &openapi.ContentSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openapi.ContentSchema{Type: "string"}
+		}
+		return &openapi.ContentSchema{Type: "array"}
+	case reflect.Struct:
+		// Structs with their own encoding, such as times, are strings.
+		if reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+			return &openapi.ContentSchema{Type: "string"}
+		}
+		return requestSchema(t)
+	default:
+		return &openapi.ContentSchema{Type: "object"}
+	}
+}
+
+func newHandler[Req any, Res any](handler func(c *ctx.Request[Req]) *ctx.Response[Res]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqBody Req
+
+		_, isEmptyStruct := any(reqBody).(struct{})
+
+		if !isEmptyStruct {
+			if r.ContentLength == 0 {
+				writeError(w, apierror.New(apierror.InvalidRequest, "missing request body"))
+				return
+			}
+
+			if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+				writeError(w, apierror.New(apierror.InvalidRequest, "invalid request body"))
+				return
+			}
+
+			if fields := validation.Validate(reqBody); len(fields) > 0 {
+				writeError(w, apierror.Validation(fields))
+				return
+			}
+		}
+
+		res := handler(&ctx.Request[Req]{
+			Request:  r,
+			Response: w,
+			Cookies: ctx.Cookies{
+				Request:  r,
+				Response: w,
+			},
+			Body: reqBody,
+		})
+		if res.Error != nil {
+			writeError(w, apierror.From(res.Error))
+			return
+		}
+
+		writeJSON(w, res.Response, res.StatusCode)
+	}
+}
+
+func writeError(w http.ResponseWriter, err *apierror.Error) {
+	writeJSON(w, err.Envelope(), err.Status())
+}
+
+func writeJSON(w http.ResponseWriter, data any, status int) {
+	out, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("Error encoding response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, err = w.Write(out)
+	if err != nil {
+		logger.Error("Error writing response: %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package session
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/matcha/security"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/apikeys"
+	"github.com/abyanmajid/thorfinn/internal/audit"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/abyanmajid/thorfinn/internal/lifecycle"
+)
+
+var (
+	ErrMissingToken   = apierror.New(apierror.Unauthenticated, "you must be logged in to perform this action")
+	ErrInvalidToken   = apierror.New(apierror.Unauthenticated, "access token is invalid or has expired")
+	ErrSessionRevoked = apierror.New(apierror.SessionRevoked, "session has been revoked or has expired")
+	ErrForbidden      = apierror.New(apierror.Forbidden, "you do not have permission to perform this action")
+	ErrImpersonating  = apierror.New(apierror.ImpersonationNotAllowed, "this action can't be performed while impersonating a user")
+)
+
+// Global roles. Admins manage users, impersonators can sign in as other
+// users to see what they see, and authz readers can check permissions and
+// read relation tuples, for services that ask Thorfinn permission questions.
+const (
+	RoleAdmin        = "admin"
+	RoleImpersonator = "impersonator"
+	RoleAuthzReader  = "authz_reader"
+)
+
+// Principal is the authenticated caller of a request. Callers authenticated
+// with an API key have the key set, and no session or claims. Callers
+// impersonating User have the Impersonator set to the user really making the
+// request.
+type Principal struct {
+	User         database.ThorfinnUser
+	Session      database.ThorfinnSession
+	Claims       security.JwtClaims
+	ApiKey       *database.ThorfinnApiKey
+	Impersonator *database.ThorfinnUser
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.User.Roles, role)
+}
+
+// Actor returns who is performing the request, for the audit log.
+func (p *Principal) Actor() audit.Actor {
+	actor := audit.Actor{UserID: p.User.ID}
+	if p.Impersonator != nil {
+		actor.ImpersonatorID = p.Impersonator.ID
+	}
+	if p.ApiKey != nil {
+		actor.ApiKeyID = p.ApiKey.ID
+	}
+
+	return actor
+}
+
+// ForbidImpersonation returns an error if the caller is impersonating the
+// user, for actions only the user themselves may perform, such as changing
+// their credentials.
+func (p *Principal) ForbidImpersonation() error {
+	if p.Impersonator != nil {
+		return ErrImpersonating
+	}
+
+	return nil
+}
+
+// ForbidApiKey returns an error if the caller is authenticated with an API
+// key, for actions that weaken the security of the account.
+func (p *Principal) ForbidApiKey() error {
+	if p.ApiKey != nil {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+type Authenticator struct {
+	config  *internal.EnvConfig
+	queries *database.Queries
+	auditor *audit.Auditor
+}
+
+func NewAuthenticator(config *internal.EnvConfig, queries *database.Queries) *Authenticator {
+	return &Authenticator{
+		config:  config,
+		queries: queries,
+		auditor: audit.NewAuditor(config, queries),
+	}
+}
+
+// Authenticate resolves the principal from the access token or API key
+// carried in the Authorization header, falling back to the access_token
+// cookie.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := tokenFromRequest(r)
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	if apikeys.IsKey(token, a.config.ApiKeyPrefix) {
+		return a.authenticateApiKey(r, token)
+	}
+
+	claims, err := ParseToken(token, a.config)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims["token_type"] != "access" {
+		return nil, ErrInvalidToken
+	}
+
+	sessionId, ok := claims["session_id"].(string)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	userId, ok := claims["user_id"].(string)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	session, err := a.queries.FindSessionById(r.Context(), sessionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != userId {
+		return nil, ErrInvalidToken
+	}
+
+	if session.RevokedAt.Valid || session.ExpiresAt.Time.Before(time.Now()) {
+		return nil, ErrSessionRevoked
+	}
+
+	user, err := a.queries.FindUserById(r.Context(), userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = lifecycle.Check(user)
+	if err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{
+		User:    user,
+		Session: session,
+		Claims:  claims,
+	}
+
+	if session.ImpersonatorID.Valid {
+		principal.Impersonator, err = a.impersonator(r, session.ImpersonatorID.String)
+		if err != nil {
+			return nil, err
+		}
+
+		a.auditor.Record(r, audit.Event{
+			Action:       audit.ActionImpersonatedRequest,
+			Actor:        principal.Actor(),
+			TargetUserID: user.ID,
+			Metadata:     map[string]any{"method": r.Method, "path": r.URL.Path},
+		})
+	}
+
+	return principal, nil
+}
+
+// impersonator finds the user who started an impersonation session. The
+// session stops working as soon as they can no longer authenticate or lose the
+// impersonator role.
+func (a *Authenticator) impersonator(r *http.Request, impersonatorId string) (*database.ThorfinnUser, error) {
+	impersonator, err := a.queries.FindUserById(r.Context(), impersonatorId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !lifecycle.IsActive(impersonator) || !slices.Contains(impersonator.Roles, RoleImpersonator) {
+		return nil, ErrSessionRevoked
+	}
+
+	return &impersonator, nil
+}
+
+// authenticateApiKey resolves the principal from an API key. The key must be
+// live, have the scope the endpoint requires, and, if it belongs to an
+// organization, be used for that organization.
+func (a *Authenticator) authenticateApiKey(r *http.Request, token string) (*Principal, error) {
+	scope := apikeys.RequiredScope(r)
+	if scope == "" {
+		return nil, apikeys.ErrNotAllowed
+	}
+
+	key, err := a.queries.FindApiKeyByHash(r.Context(), apikeys.Hash(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apikeys.ErrInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if key.RevokedAt.Valid || (key.ExpiresAt.Valid && key.ExpiresAt.Time.Before(time.Now())) {
+		return nil, apikeys.ErrInvalid
+	}
+
+	if key.OrganizationID.Valid && apikeys.OrganizationID(r) != key.OrganizationID.String {
+		return nil, apikeys.ErrWrongOrganization
+	}
+
+	if !slices.Contains(key.Scopes, scope) {
+		return nil, apikeys.ErrMissingScope
+	}
+
+	user, err := a.queries.FindUserById(r.Context(), key.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lifecycle.Check(user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.queries.TouchApiKey(r.Context(), key.ID)
+	if err != nil {
+		logger.Error("Error recording API key usage: %v", err)
+	}
+
+	return &Principal{
+		User:   user,
+		ApiKey: &key,
+	}, nil
+}
+
+// Authorize authenticates the request like Authenticate, and additionally
+// requires the caller to have one of the given roles.
+func (a *Authenticator) Authorize(r *http.Request, roles ...string) (*Principal, error) {
+	principal, err := a.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(roles, principal.HasRole) {
+		return nil, ErrForbidden
+	}
+
+	return principal, nil
+}
+
+// ParseToken decodes, decrypts and verifies a token issued by Thorfinn.
+func ParseToken(token string, config *internal.EnvConfig) (security.JwtClaims, error) {
+	encryptedToken, err := security.DecodeBase64(token)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenByte, err := security.Decrypt(encryptedToken, []byte(config.EncryptionSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	verifiedToken, err := security.VerifyJWT(string(tokenByte), []byte(config.JwtSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	return verifiedToken.JwtClaims, nil
+}
+
+func tokenFromRequest(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if token, found := strings.CutPrefix(authorization, "Bearer "); found {
+		return strings.TrimSpace(token)
+	}
+
+	cookie, err := r.Cookie("access_token")
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
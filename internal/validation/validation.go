@@ -0,0 +1,254 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/abyanmajid/v"
+)
+
+var (
+	localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	slugPattern   = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// Validate checks every field of body against the rules in its validate tag
+// and returns the failures of all fields at once, keyed by JSON field name.
+// Pointer fields are only validated when they are set, and then even when
+// they point to an empty value, unless they carry the omitempty rule.
+//
+// Supported rules are required, email, uuid, min=N, max=N and len=N on
+// strings (lengths) and slices (number of items), oneof=a|b on strings,
+// eqfield=Field to require a string to equal another field of the struct, and
+// url, locale (a BCP 47 language tag), timezone (an IANA time zone name) and
+// slug (lowercase letters and digits separated by single hyphens) on strings.
+// The omitempty rule lets a set pointer field be empty, such as to clear it.
+func Validate(body any) map[string][]string {
+	fields := map[string][]string{}
+
+	value := reflect.ValueOf(body)
+	if value.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		set := false
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+			set = true
+		}
+
+		errors := validateField(value, fieldValue, strings.Split(tag, ","), set)
+		if len(errors) > 0 {
+			fields[jsonName(field)] = errors
+		}
+	}
+
+	return fields
+}
+
+func validateField(parent reflect.Value, value reflect.Value, rules []string, set bool) []string {
+	if value.IsZero() {
+		if slices.Contains(rules, "required") {
+			return []string{"This field is required"}
+		}
+		if !set || slices.Contains(rules, "omitempty") {
+			return nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return validateString(parent, value.String(), rules)
+	case reflect.Slice:
+		return validateSlice(value.Len(), rules)
+	default:
+		return nil
+	}
+}
+
+func validateString(parent reflect.Value, value string, rules []string) []string {
+	schema := v.String("")
+	errors := []string{}
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "email":
+			schema.Email()
+		case "uuid":
+			schema.UUID()
+		case "min":
+			schema.Min(atoi(param))
+		case "max":
+			schema.Max(atoi(param))
+		case "len":
+			schema.Length(atoi(param))
+		case "oneof":
+			allowed := strings.Split(param, "|")
+			result := v.Enum("", allowed).Parse(value)
+			if !result.Ok {
+				errors = append(errors, fmt.Sprintf("Must be one of %s", strings.Join(allowed, ", ")))
+			}
+		case "eqfield":
+			other := parent.FieldByName(param)
+			if other.Kind() == reflect.String && other.String() != value {
+				errors = append(errors, fmt.Sprintf("Must match %s", jsonNameOf(parent, param)))
+			}
+		case "url":
+			parsed, err := url.Parse(value)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				errors = append(errors, "Must be a valid http or https URL")
+			}
+		case "locale":
+			if !localePattern.MatchString(value) {
+				errors = append(errors, "Must be a valid language tag, such as en or en-US")
+			}
+		case "slug":
+			if !slugPattern.MatchString(value) {
+				errors = append(errors, "Must contain only lowercase letters, digits and single hyphens between them")
+			}
+		case "timezone":
+			if _, err := time.LoadLocation(value); err != nil || value == "Local" {
+				errors = append(errors, "Must be a valid IANA time zone, such as Europe/Berlin")
+			}
+		}
+	}
+
+	result := schema.ParseTyped(value)
+	return append(result.Errors, errors...)
+}
+
+func validateSlice(length int, rules []string) []string {
+	errors := []string{}
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "min":
+			if length < atoi(param) {
+				errors = append(errors, fmt.Sprintf("Must contain at least %s items", param))
+			}
+		case "max":
+			if length > atoi(param) {
+				errors = append(errors, fmt.Sprintf("Must contain at most %s items", param))
+			}
+		case "len":
+			if length != atoi(param) {
+				errors = append(errors, fmt.Sprintf("Must contain exactly %s items", param))
+			}
+		}
+	}
+
+	return errors
+}
+
+// Describe renders the validate tags of body as a list of constraints, to be
+// appended to the description of the endpoint that accepts it. It returns an
+// empty string if body has no validated fields.
+func Describe(body any) string {
+	bodyType := reflect.TypeOf(body)
+	if bodyType == nil || bodyType.Kind() != reflect.Struct {
+		return ""
+	}
+
+	lines := []string{}
+	for i := 0; i < bodyType.NumField(); i++ {
+		field := bodyType.Field(i)
+
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+
+		unit := "characters"
+		if field.Type.Kind() == reflect.Slice {
+			unit = "items"
+		}
+
+		constraints := []string{}
+		for _, rule := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(rule, "=")
+
+			switch name {
+			case "required":
+				constraints = append(constraints, "required")
+			case "omitempty":
+				constraints = append(constraints, "may be empty")
+			case "email":
+				constraints = append(constraints, "valid email address")
+			case "uuid":
+				constraints = append(constraints, "valid UUID")
+			case "min":
+				constraints = append(constraints, fmt.Sprintf("at least %s %s", param, unit))
+			case "max":
+				constraints = append(constraints, fmt.Sprintf("at most %s %s", param, unit))
+			case "len":
+				constraints = append(constraints, fmt.Sprintf("exactly %s %s", param, unit))
+			case "oneof":
+				constraints = append(constraints, fmt.Sprintf("one of %s", strings.ReplaceAll(param, "|", ", ")))
+			case "eqfield":
+				constraints = append(constraints, fmt.Sprintf("must match `%s`", jsonNameOf(reflect.Zero(bodyType), param)))
+			case "url":
+				constraints = append(constraints, "http or https URL")
+			case "locale":
+				constraints = append(constraints, "BCP 47 language tag")
+			case "slug":
+				constraints = append(constraints, "lowercase letters, digits and hyphens")
+			case "timezone":
+				constraints = append(constraints, "IANA time zone")
+			}
+		}
+
+		lines = append(lines, fmt.Sprintf("- `%s`: %s", jsonName(field), strings.Join(constraints, ", ")))
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return "Request body constraints:\n\n" + strings.Join(lines, "\n")
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+func jsonNameOf(parent reflect.Value, fieldName string) string {
+	field, ok := parent.Type().FieldByName(fieldName)
+	if !ok {
+		return fieldName
+	}
+
+	return jsonName(field)
+}
+
+func atoi(param string) int {
+	n, _ := strconv.Atoi(param)
+	return n
+}
@@ -0,0 +1,437 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/abyanmajid/matcha/logger"
+	"github.com/abyanmajid/thorfinn/internal"
+	"github.com/abyanmajid/thorfinn/internal/apierror"
+	"github.com/abyanmajid/thorfinn/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Events an endpoint can subscribe to, all about the lifecycle of a user.
+const (
+	EventUserRegistered        = "user.registered"
+	EventUserEmailVerified     = "user.email_verified"
+	EventUserEmailChanged      = "user.email_changed"
+	EventUserDeletionScheduled = "user.deletion_scheduled"
+	EventUserDeletionCancelled = "user.deletion_cancelled"
+	EventUserDeleted           = "user.deleted"
+	EventUserTwoFactorEnabled  = "user.two_factor_enabled"
+	EventUserTwoFactorDisabled = "user.two_factor_disabled"
+)
+
+var Events = []string{
+	EventUserRegistered,
+	EventUserEmailVerified,
+	EventUserEmailChanged,
+	EventUserDeletionScheduled,
+	EventUserDeletionCancelled,
+	EventUserDeleted,
+	EventUserTwoFactorEnabled,
+	EventUserTwoFactorDisabled,
+}
+
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+var Statuses = []string{StatusPending, StatusSucceeded, StatusFailed}
+
+const (
+	EventHeader     = "X-Thorfinn-Event"
+	DeliveryHeader  = "X-Thorfinn-Delivery"
+	TimestampHeader = "X-Thorfinn-Timestamp"
+	SignatureHeader = "X-Thorfinn-Signature"
+)
+
+var (
+	ErrNotFound         = apierror.New(apierror.WebhookNotFound, "webhook not found")
+	ErrDeliveryNotFound = apierror.New(apierror.WebhookDeliveryNotFound, "webhook delivery not found")
+)
+
+const (
+	secretPrefix        = "whsec_"
+	secretLength        = 32
+	deliveryBatchSize   = 50
+	maxResponseBodySize = 1024
+	initialBackoff      = time.Minute
+	maxBackoff          = time.Hour
+)
+
+// sharedAddressSpace is the range carrier-grade NATs use, which netip doesn't
+// count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+type payload struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+	Data      map[string]any `json:"data"`
+}
+
+type payloadUser struct {
+	ID               string    `json:"id"`
+	Email            string    `json:"email"`
+	Verified         bool      `json:"verified"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	Status           string    `json:"status"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Dispatcher queues events for the endpoints subscribed to them and delivers
+// them in the background. Deliveries are stored in the database before being
+// sent, so that they survive restarts and can be retried and redelivered.
+type Dispatcher struct {
+	config  *internal.EnvConfig
+	queries *database.Queries
+	client  *http.Client
+}
+
+func NewDispatcher(config *internal.EnvConfig, queries *database.Queries) *Dispatcher {
+	return &Dispatcher{
+		config:  config,
+		queries: queries,
+		client:  newClient(config),
+	}
+}
+
+// newClient returns the client deliveries are sent with. It doesn't follow
+// redirects and, unless WEBHOOK_ALLOW_PRIVATE_ADDRESSES is set, refuses to
+// connect to loopback, private and link-local addresses, so that endpoints
+// can't be used to reach internal services.
+func newClient(config *internal.EnvConfig) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if !config.WebhookAllowPrivateAddresses {
+		dialer.Control = rejectPrivateAddresses
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   time.Duration(config.WebhookTimeoutMs) * time.Millisecond,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// rejectPrivateAddresses is called with the resolved address of every
+// connection, so that a host name can't be pointed at an internal address.
+func rejectPrivateAddresses(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("webhook endpoint resolves to a non-public address %s", ip)
+	}
+
+	return nil
+}
+
+// Publish queues event about user, with any extra data, for every enabled
+// endpoint subscribed to it. Failing to queue an event is logged rather than
+// returned, so that it never fails the action itself.
+func (d *Dispatcher) Publish(ctx context.Context, event string, user database.ThorfinnUser, data map[string]any) {
+	endpoints, err := d.queries.ListSubscribedWebhookEndpoints(ctx, event)
+	if err != nil {
+		logger.Error("Error listing webhook endpoints subscribed to %s: %v", event, err)
+		return
+	}
+
+	if len(endpoints) == 0 {
+		return
+	}
+
+	eventData := map[string]any{}
+	for key, value := range data {
+		eventData[key] = value
+	}
+	eventData["user"] = payloadUser{
+		ID:               user.ID,
+		Email:            user.Email,
+		Verified:         user.Verified,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		Status:           user.Status,
+		CreatedAt:        user.CreatedAt.Time,
+	}
+
+	eventId := uuid.New().String()
+	encoded, err := json.Marshal(payload{
+		ID:        eventId,
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		Data:      eventData,
+	})
+	if err != nil {
+		logger.Error("Error encoding webhook event %s: %v", event, err)
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		_, err := d.queries.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			ID:         uuid.New().String(),
+			EndpointID: endpoint.ID,
+			EventID:    eventId,
+			EventType:  event,
+			Payload:    encoded,
+		})
+		if err != nil {
+			logger.Error("Error queueing webhook event %s for endpoint %s: %v", event, endpoint.ID, err)
+		}
+	}
+}
+
+// Redeliver queues delivery again, as a new delivery with the same event id
+// and payload, so that receivers can tell it apart from the original.
+func (d *Dispatcher) Redeliver(ctx context.Context, delivery database.ThorfinnWebhookDelivery) (database.ThorfinnWebhookDelivery, error) {
+	return d.queries.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		ID:         uuid.New().String(),
+		EndpointID: delivery.EndpointID,
+		EventID:    delivery.EventID,
+		EventType:  delivery.EventType,
+		Payload:    delivery.Payload,
+	})
+}
+
+// Start delivers due events in the background until ctx is done. It does
+// nothing if WEBHOOK_INTERVAL_SECONDS is 0 or less.
+func (d *Dispatcher) Start(ctx context.Context) {
+	if d.config.WebhookIntervalSeconds <= 0 {
+		logger.Info("Webhook delivery is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(d.config.WebhookIntervalSeconds) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			d.Deliver(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Deliver sends every delivery that is due. A batch is sent one delivery
+// after the other, so it is claimed for longer than sending all of it can
+// take, so that other instances don't send them too.
+func (d *Dispatcher) Deliver(ctx context.Context) {
+	leaseSeconds := int32(deliveryBatchSize*d.config.WebhookTimeoutMs/1000) + 60
+
+	for {
+		deliveries, err := d.queries.ClaimDueWebhookDeliveries(ctx, database.ClaimDueWebhookDeliveriesParams{
+			LeaseSeconds: leaseSeconds,
+			Limit:        deliveryBatchSize,
+		})
+		if err != nil {
+			logger.Error("Error claiming due webhook deliveries: %v", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			d.attempt(ctx, delivery)
+		}
+
+		if len(deliveries) < deliveryBatchSize {
+			return
+		}
+	}
+}
+
+// PurgeExpired deletes the deliveries, other than pending ones, older than
+// WEBHOOK_DELIVERY_RETENTION_DAYS. It does nothing if the retention period is
+// 0 or less.
+func (d *Dispatcher) PurgeExpired(ctx context.Context) error {
+	if d.config.WebhookDeliveryRetentionDays <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-time.Duration(d.config.WebhookDeliveryRetentionDays) * 24 * time.Hour)
+
+	return d.queries.DeleteWebhookDeliveriesBefore(ctx, pgtype.Timestamptz{Time: cutoff, Valid: true})
+}
+
+// attempt sends delivery once and records the outcome. A delivery that fails
+// is retried with exponential backoff until WEBHOOK_MAX_ATTEMPTS, and an
+// endpoint is disabled once WEBHOOK_DISABLE_AFTER_FAILURES attempts in a row
+// have failed.
+func (d *Dispatcher) attempt(ctx context.Context, delivery database.ThorfinnWebhookDelivery) {
+	endpoint, err := d.queries.FindWebhookEndpointById(ctx, delivery.EndpointID)
+	if err != nil {
+		logger.Error("Error finding webhook endpoint %s: %v", delivery.EndpointID, err)
+		return
+	}
+
+	responseStatus, responseBody, err := d.send(ctx, endpoint, delivery)
+
+	params := database.RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         StatusSucceeded,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: pgtype.Int4{Int32: int32(responseStatus), Valid: responseStatus != 0},
+		ResponseBody:   responseBody,
+	}
+
+	succeeded := err == nil && responseStatus >= 200 && responseStatus < 300
+	if !succeeded {
+		if err != nil {
+			params.Error = err.Error()
+		} else {
+			params.Error = fmt.Sprintf("endpoint responded with status %d", responseStatus)
+		}
+
+		attempts := int(delivery.Attempts) + 1
+		if attempts >= d.config.WebhookMaxAttempts {
+			params.Status = StatusFailed
+		} else {
+			params.Status = StatusPending
+			params.NextAttemptAt = pgtype.Timestamptz{Time: time.Now().Add(backoff(attempts)), Valid: true}
+		}
+	}
+
+	if err := d.queries.RecordWebhookDeliveryAttempt(ctx, params); err != nil {
+		logger.Error("Error recording attempt of webhook delivery %s: %v", delivery.ID, err)
+	}
+
+	if succeeded {
+		if err := d.queries.RecordWebhookEndpointSuccess(ctx, endpoint.ID); err != nil {
+			logger.Error("Error resetting failures of webhook endpoint %s: %v", endpoint.ID, err)
+		}
+		return
+	}
+
+	logger.Debug("Webhook delivery %s to endpoint %s failed: %s", delivery.ID, endpoint.ID, params.Error)
+
+	if d.config.WebhookDisableAfterFailures <= 0 {
+		return
+	}
+
+	updated, err := d.queries.RecordWebhookEndpointFailure(ctx, database.RecordWebhookEndpointFailureParams{
+		MaxFailures: int32(d.config.WebhookDisableAfterFailures),
+		Reason:      fmt.Sprintf("%d deliveries in a row failed, the last with: %s", d.config.WebhookDisableAfterFailures, params.Error),
+		ID:          endpoint.ID,
+	})
+	if err != nil {
+		logger.Error("Error recording failure of webhook endpoint %s: %v", endpoint.ID, err)
+		return
+	}
+
+	if endpoint.Enabled && !updated.Enabled {
+		logger.Info("Disabled webhook endpoint %s after %d failed deliveries in a row", endpoint.ID, updated.ConsecutiveFailures)
+	}
+}
+
+// send posts delivery to endpoint, and returns the status and the start of
+// the body of the response.
+func (d *Dispatcher) send(ctx context.Context, endpoint database.ThorfinnWebhookEndpoint, delivery database.ThorfinnWebhookDelivery) (int, string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("error creating request: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, delivery.EventType)
+	request.Header.Set(DeliveryHeader, delivery.ID)
+	request.Header.Set(TimestampHeader, timestamp)
+	request.Header.Set(SignatureHeader, "sha256="+Sign(endpoint.Secret, timestamp, delivery.Payload))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxResponseBodySize))
+	if err != nil {
+		return response.StatusCode, "", fmt.Errorf("error reading response: %v", err)
+	}
+
+	return response.StatusCode, string(body), nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256, keyed with secret, of the
+// timestamp and body joined by a dot. Signing the timestamp lets receivers
+// reject replayed deliveries.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret returns a new secret to sign the deliveries to an endpoint
+// with.
+func GenerateSecret() (string, error) {
+	bytes := make([]byte, secretLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// ValidateEvents returns a validation error if any of events is unknown.
+// Subscribing to no events subscribes to all of them.
+func ValidateEvents(events []string) error {
+	for _, event := range events {
+		if !slices.Contains(Events, event) {
+			return apierror.Validation(map[string][]string{
+				"events": {fmt.Sprintf("Must only contain %s", strings.Join(Events, ", "))},
+			})
+		}
+	}
+
+	return nil
+}
+
+// backoff returns how long to wait before retrying a delivery that has failed
+// attempts times: a minute, doubling with each attempt, up to an hour.
+func backoff(attempts int) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS thorfinn_password_history (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES thorfinn_users(id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_password_history_user_id ON thorfinn_password_history(user_id, created_at DESC);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_thorfinn_password_history_user_id;
+
+DROP TABLE IF EXISTS thorfinn_password_history;
@@ -0,0 +1,13 @@
+-- +goose Up
+
+ALTER TABLE thorfinn_users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_users_created_at ON thorfinn_users(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_thorfinn_users_last_login_at ON thorfinn_users(last_login_at, id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_thorfinn_users_last_login_at;
+DROP INDEX IF EXISTS idx_thorfinn_users_created_at;
+
+ALTER TABLE thorfinn_users DROP COLUMN IF EXISTS last_login_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+
+ALTER TABLE thorfinn_users ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+
+ALTER TABLE thorfinn_users DROP COLUMN IF EXISTS roles;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+ALTER TABLE thorfinn_users
+    ADD COLUMN IF NOT EXISTS display_name TEXT,
+    ADD COLUMN IF NOT EXISTS given_name TEXT,
+    ADD COLUMN IF NOT EXISTS family_name TEXT,
+    ADD COLUMN IF NOT EXISTS avatar_url TEXT,
+    ADD COLUMN IF NOT EXISTS locale TEXT,
+    ADD COLUMN IF NOT EXISTS timezone TEXT,
+    ADD COLUMN IF NOT EXISTS user_metadata JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS app_metadata JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+
+ALTER TABLE thorfinn_users
+    DROP COLUMN IF EXISTS display_name,
+    DROP COLUMN IF EXISTS given_name,
+    DROP COLUMN IF EXISTS family_name,
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS user_metadata,
+    DROP COLUMN IF EXISTS app_metadata;
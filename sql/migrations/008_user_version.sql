@@ -0,0 +1,29 @@
+-- +goose Up
+
+ALTER TABLE thorfinn_users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- Bump the version and updated_at of a user whenever it is edited. Recording a
+-- login is not an edit, so that it doesn't invalidate the user's ETag.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION thorfinn_users_touch() RETURNS TRIGGER AS $$
+BEGIN
+    IF to_jsonb(NEW) - 'last_login_at' - 'updated_at' - 'version'
+        IS DISTINCT FROM to_jsonb(OLD) - 'last_login_at' - 'updated_at' - 'version' THEN
+        NEW.updated_at = CURRENT_TIMESTAMP;
+        NEW.version = OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER thorfinn_users_touch
+    BEFORE UPDATE ON thorfinn_users
+    FOR EACH ROW EXECUTE FUNCTION thorfinn_users_touch();
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS thorfinn_users_touch ON thorfinn_users;
+DROP FUNCTION IF EXISTS thorfinn_users_touch();
+
+ALTER TABLE thorfinn_users DROP COLUMN IF EXISTS version;
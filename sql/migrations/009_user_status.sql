@@ -0,0 +1,31 @@
+-- +goose Up
+
+ALTER TABLE thorfinn_users
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS status_reason TEXT,
+    ADD COLUMN IF NOT EXISTS purge_after TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deletion_requested_by_user BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT thorfinn_users_status_check CHECK (status IN ('active', 'suspended', 'pending_deletion', 'deleted'));
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_users_purge_after ON thorfinn_users(purge_after) WHERE status = 'pending_deletion';
+
+ALTER TABLE thorfinn_otp_codes ADD COLUMN IF NOT EXISTS user_id TEXT REFERENCES thorfinn_users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_otp_codes_user_id ON thorfinn_otp_codes(user_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_thorfinn_otp_codes_user_id;
+
+ALTER TABLE thorfinn_otp_codes DROP COLUMN IF EXISTS user_id;
+
+DROP INDEX IF EXISTS idx_thorfinn_users_purge_after;
+
+ALTER TABLE thorfinn_users
+    DROP CONSTRAINT IF EXISTS thorfinn_users_status_check,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS status_reason,
+    DROP COLUMN IF EXISTS purge_after,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS deletion_requested_by_user;
@@ -0,0 +1,32 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS thorfinn_organizations (
+    id TEXT NOT NULL PRIMARY KEY,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS thorfinn_memberships (
+    organization_id TEXT NOT NULL REFERENCES thorfinn_organizations(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES thorfinn_users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_memberships_user_id ON thorfinn_memberships(user_id);
+
+ALTER TABLE thorfinn_sessions ADD COLUMN IF NOT EXISTS active_organization_id TEXT REFERENCES thorfinn_organizations(id) ON DELETE SET NULL;
+
+-- +goose Down
+
+ALTER TABLE thorfinn_sessions DROP COLUMN IF EXISTS active_organization_id;
+
+DROP INDEX IF EXISTS idx_thorfinn_memberships_user_id;
+
+DROP TABLE IF EXISTS thorfinn_memberships;
+
+DROP TABLE IF EXISTS thorfinn_organizations;
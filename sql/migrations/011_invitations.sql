@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS thorfinn_invitations (
+    id TEXT NOT NULL PRIMARY KEY,
+    email TEXT NOT NULL,
+    organization_id TEXT REFERENCES thorfinn_organizations(id) ON DELETE CASCADE,
+    role TEXT,
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by TEXT REFERENCES thorfinn_users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_invitations_organization_id ON thorfinn_invitations(organization_id);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_invitations_email ON thorfinn_invitations(email);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_thorfinn_invitations_email;
+
+DROP INDEX IF EXISTS idx_thorfinn_invitations_organization_id;
+
+DROP TABLE IF EXISTS thorfinn_invitations;
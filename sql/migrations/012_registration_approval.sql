@@ -0,0 +1,13 @@
+-- +goose Up
+
+ALTER TABLE thorfinn_users
+    DROP CONSTRAINT IF EXISTS thorfinn_users_status_check,
+    ADD CONSTRAINT thorfinn_users_status_check CHECK (status IN ('active', 'pending_approval', 'suspended', 'pending_deletion', 'deleted'));
+
+-- +goose Down
+
+UPDATE thorfinn_users SET status = 'active' WHERE status = 'pending_approval';
+
+ALTER TABLE thorfinn_users
+    DROP CONSTRAINT IF EXISTS thorfinn_users_status_check,
+    ADD CONSTRAINT thorfinn_users_status_check CHECK (status IN ('active', 'suspended', 'pending_deletion', 'deleted'));
@@ -0,0 +1,28 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS thorfinn_api_keys (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES thorfinn_users(id) ON DELETE CASCADE,
+    organization_id TEXT REFERENCES thorfinn_organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_api_keys_user_id ON thorfinn_api_keys(user_id);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_api_keys_organization_id ON thorfinn_api_keys(organization_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_thorfinn_api_keys_organization_id;
+
+DROP INDEX IF EXISTS idx_thorfinn_api_keys_user_id;
+
+DROP TABLE IF EXISTS thorfinn_api_keys;
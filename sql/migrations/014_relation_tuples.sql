@@ -0,0 +1,20 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS thorfinn_relation_tuples (
+    object_type TEXT NOT NULL,
+    object_id TEXT NOT NULL,
+    relation TEXT NOT NULL,
+    subject_type TEXT NOT NULL,
+    subject_id TEXT NOT NULL,
+    subject_relation TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (object_type, object_id, relation, subject_type, subject_id, subject_relation)
+);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_relation_tuples_subject ON thorfinn_relation_tuples(subject_type, subject_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_thorfinn_relation_tuples_subject;
+
+DROP TABLE IF EXISTS thorfinn_relation_tuples;
@@ -0,0 +1,7 @@
+-- +goose Up
+
+ALTER TABLE thorfinn_sessions ADD COLUMN IF NOT EXISTS impersonator_id TEXT REFERENCES thorfinn_users(id);
+
+-- +goose Down
+
+ALTER TABLE thorfinn_sessions DROP COLUMN IF EXISTS impersonator_id;
@@ -0,0 +1,67 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS thorfinn_audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id TEXT,
+    impersonator_id TEXT,
+    target_user_id TEXT,
+    action TEXT NOT NULL,
+    outcome TEXT NOT NULL CHECK (outcome IN ('success', 'failure')),
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    previous_hash TEXT UNIQUE,
+    hash TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_audit_events_actor_id ON thorfinn_audit_events(actor_id);
+CREATE INDEX IF NOT EXISTS idx_thorfinn_audit_events_target_user_id ON thorfinn_audit_events(target_user_id);
+CREATE INDEX IF NOT EXISTS idx_thorfinn_audit_events_created_at ON thorfinn_audit_events(created_at);
+
+-- Audit events are append-only.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION thorfinn_audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER thorfinn_audit_events_append_only
+    BEFORE UPDATE ON thorfinn_audit_events
+    FOR EACH ROW EXECUTE FUNCTION thorfinn_audit_events_append_only();
+
+-- Audit events can only be deleted by a transaction that first sets
+-- thorfinn.audit_retention_cutoff, and only when they are older than it.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION thorfinn_audit_events_retention() RETURNS TRIGGER AS $$
+DECLARE
+    cutoff TEXT := current_setting('thorfinn.audit_retention_cutoff', true);
+BEGIN
+    IF cutoff IS NULL OR cutoff = '' OR OLD.created_at >= cutoff::TIMESTAMPTZ THEN
+        RAISE EXCEPTION 'audit events can only be deleted once they are older than the retention period';
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER thorfinn_audit_events_retention
+    BEFORE DELETE ON thorfinn_audit_events
+    FOR EACH ROW EXECUTE FUNCTION thorfinn_audit_events_retention();
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS thorfinn_audit_events_retention ON thorfinn_audit_events;
+DROP FUNCTION IF EXISTS thorfinn_audit_events_retention();
+
+DROP TRIGGER IF EXISTS thorfinn_audit_events_append_only ON thorfinn_audit_events;
+DROP FUNCTION IF EXISTS thorfinn_audit_events_append_only();
+
+DROP INDEX IF EXISTS idx_thorfinn_audit_events_created_at;
+DROP INDEX IF EXISTS idx_thorfinn_audit_events_target_user_id;
+DROP INDEX IF EXISTS idx_thorfinn_audit_events_actor_id;
+
+DROP TABLE IF EXISTS thorfinn_audit_events;
@@ -0,0 +1,45 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS thorfinn_webhook_endpoints (
+    id TEXT NOT NULL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    disabled_reason TEXT,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    created_by TEXT REFERENCES thorfinn_users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS thorfinn_webhook_deliveries (
+    id TEXT NOT NULL PRIMARY KEY,
+    endpoint_id TEXT NOT NULL REFERENCES thorfinn_webhook_endpoints(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_attempt_at TIMESTAMPTZ,
+    response_status INTEGER,
+    response_body TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_webhook_deliveries_endpoint_id ON thorfinn_webhook_deliveries(endpoint_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_thorfinn_webhook_deliveries_due ON thorfinn_webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_thorfinn_webhook_deliveries_due;
+
+DROP INDEX IF EXISTS idx_thorfinn_webhook_deliveries_endpoint_id;
+
+DROP TABLE IF EXISTS thorfinn_webhook_deliveries;
+
+DROP TABLE IF EXISTS thorfinn_webhook_endpoints;
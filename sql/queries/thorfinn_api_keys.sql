@@ -0,0 +1,35 @@
+-- name: CreateApiKey :one
+INSERT INTO thorfinn_api_keys (id, user_id, organization_id, name, prefix, key_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: FindApiKeyById :one
+SELECT * FROM thorfinn_api_keys WHERE id = $1;
+
+-- name: FindApiKeyByHash :one
+SELECT * FROM thorfinn_api_keys WHERE key_hash = $1;
+
+-- name: ListUserApiKeys :many
+SELECT * FROM thorfinn_api_keys
+WHERE user_id = $1 AND organization_id IS NULL AND revoked_at IS NULL
+ORDER BY created_at DESC, id;
+
+-- name: ListOrganizationApiKeys :many
+SELECT * FROM thorfinn_api_keys
+WHERE organization_id = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC, id;
+
+-- name: TouchApiKey :exec
+UPDATE thorfinn_api_keys
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute');
+
+-- name: RevokeApiKey :exec
+UPDATE thorfinn_api_keys
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeMemberApiKeys :exec
+UPDATE thorfinn_api_keys
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE organization_id = $1 AND user_id = $2 AND revoked_at IS NULL;
@@ -0,0 +1,44 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO thorfinn_audit_events (
+    actor_id, impersonator_id, target_user_id, action, outcome, ip_address, user_agent, request_id, metadata, previous_hash, hash, created_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: FindLatestAuditHash :one
+SELECT hash FROM thorfinn_audit_events WHERE hash IS NOT NULL ORDER BY id DESC LIMIT 1;
+
+-- name: LockAuditChain :exec
+-- Holds the lock on the end of the hash chain until the current transaction
+-- ends, so that events are appended to the chain one at a time.
+SELECT pg_advisory_xact_lock(hashtext('thorfinn_audit_events'));
+
+-- name: ListAuditEvents :many
+SELECT * FROM thorfinn_audit_events
+WHERE (sqlc.narg('actor_id')::text IS NULL OR actor_id = sqlc.narg('actor_id'))
+    AND (sqlc.narg('target_user_id')::text IS NULL OR target_user_id = sqlc.narg('target_user_id'))
+    AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action'))
+    AND (sqlc.narg('outcome')::text IS NULL OR outcome = sqlc.narg('outcome'))
+    AND (sqlc.narg('ip_address')::text IS NULL OR ip_address = sqlc.narg('ip_address'))
+    AND (sqlc.narg('request_id')::text IS NULL OR request_id = sqlc.narg('request_id'))
+    AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+    AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+    AND (sqlc.narg('before_id')::bigint IS NULL OR id < sqlc.narg('before_id'))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListChainedAuditEvents :many
+SELECT * FROM thorfinn_audit_events
+WHERE hash IS NOT NULL AND id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: ListUserAuditEvents :many
+SELECT * FROM thorfinn_audit_events
+WHERE actor_id = $1 OR target_user_id = $1
+ORDER BY id;
+
+-- name: SetAuditRetentionCutoff :exec
+-- Lets the current transaction delete audit events older than the cutoff.
+SELECT set_config('thorfinn.audit_retention_cutoff', sqlc.arg('cutoff')::timestamptz::text, true);
+
+-- name: DeleteAuditEventsBefore :exec
+DELETE FROM thorfinn_audit_events WHERE created_at < $1;
@@ -0,0 +1,35 @@
+-- name: CreateInvitation :one
+INSERT INTO thorfinn_invitations (id, email, organization_id, role, token_hash, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: FindInvitationById :one
+SELECT * FROM thorfinn_invitations WHERE id = $1;
+
+-- name: FindInvitationByTokenHash :one
+SELECT * FROM thorfinn_invitations WHERE token_hash = $1;
+
+-- name: ListPendingInvitations :many
+SELECT * FROM thorfinn_invitations
+WHERE organization_id IS NOT DISTINCT FROM sqlc.narg('organization_id')
+  AND accepted_at IS NULL
+  AND expires_at > CURRENT_TIMESTAMP
+ORDER BY created_at DESC, id;
+
+-- name: AcceptInvitation :one
+UPDATE thorfinn_invitations
+SET accepted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND accepted_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteInvitation :exec
+DELETE FROM thorfinn_invitations WHERE id = $1;
+
+-- name: DeletePendingInvitations :exec
+DELETE FROM thorfinn_invitations
+WHERE email = sqlc.arg('email')
+  AND organization_id IS NOT DISTINCT FROM sqlc.narg('organization_id')
+  AND accepted_at IS NULL;
+
+-- name: DeleteExpiredInvitations :exec
+DELETE FROM thorfinn_invitations WHERE accepted_at IS NULL AND expires_at < CURRENT_TIMESTAMP;
@@ -0,0 +1,24 @@
+-- name: CreateMembership :one
+INSERT INTO thorfinn_memberships (organization_id, user_id, role) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: FindMembership :one
+SELECT * FROM thorfinn_memberships WHERE organization_id = $1 AND user_id = $2;
+
+-- name: ListOrganizationMembers :many
+SELECT m.organization_id, m.user_id, m.role, m.created_at, m.updated_at, u.email, u.display_name
+FROM thorfinn_memberships m
+JOIN thorfinn_users u ON u.id = m.user_id
+WHERE m.organization_id = $1
+ORDER BY m.created_at, m.user_id;
+
+-- name: CountOrganizationOwners :one
+SELECT COUNT(*) FROM thorfinn_memberships WHERE organization_id = $1 AND role = 'owner';
+
+-- name: UpdateMembershipRole :one
+UPDATE thorfinn_memberships
+SET role = $3, updated_at = CURRENT_TIMESTAMP
+WHERE organization_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteMembership :exec
+DELETE FROM thorfinn_memberships WHERE organization_id = $1 AND user_id = $2;
@@ -0,0 +1,31 @@
+-- name: CreateOrganization :one
+WITH organization AS (
+    INSERT INTO thorfinn_organizations (id, name, slug)
+    VALUES (sqlc.arg('id'), sqlc.arg('name'), sqlc.arg('slug'))
+    RETURNING *
+), owner AS (
+    INSERT INTO thorfinn_memberships (organization_id, user_id, role)
+    SELECT id, sqlc.arg('owner_id'), 'owner' FROM organization
+)
+SELECT * FROM organization;
+
+-- name: FindOrganizationById :one
+SELECT * FROM thorfinn_organizations WHERE id = $1;
+
+-- name: FindOrganizationBySlug :one
+SELECT * FROM thorfinn_organizations WHERE slug = $1;
+
+-- name: ListUserOrganizations :many
+SELECT o.*, m.role FROM thorfinn_organizations o
+JOIN thorfinn_memberships m ON m.organization_id = o.id
+WHERE m.user_id = $1
+ORDER BY o.name, o.id;
+
+-- name: UpdateOrganization :one
+UPDATE thorfinn_organizations
+SET name = $2, slug = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteOrganization :exec
+DELETE FROM thorfinn_organizations WHERE id = $1;
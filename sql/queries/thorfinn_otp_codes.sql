@@ -2,7 +2,10 @@
 SELECT * FROM thorfinn_otp_codes WHERE id = $1;
 
 -- name: CreateOtpCode :one
-INSERT INTO thorfinn_otp_codes (id, code, expires_at) VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO thorfinn_otp_codes (id, code, expires_at, user_id) VALUES ($1, $2, $3, $4) RETURNING *;
 
 -- name: DeleteOtpCode :exec
 DELETE FROM thorfinn_otp_codes WHERE id = $1;
+
+-- name: DeleteExpiredOtpCodes :exec
+DELETE FROM thorfinn_otp_codes WHERE expires_at < CURRENT_TIMESTAMP;
@@ -0,0 +1,26 @@
+-- name: CreatePasswordHistory :one
+INSERT INTO thorfinn_password_history (id, user_id, password_hash) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: ListRecentPasswordHistory :many
+SELECT * FROM thorfinn_password_history
+WHERE user_id = $1 AND created_at >= $2
+ORDER BY created_at DESC
+LIMIT $3;
+
+-- name: ListPasswordChangeDates :many
+SELECT created_at FROM thorfinn_password_history
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: PrunePasswordHistory :exec
+DELETE FROM thorfinn_password_history
+WHERE user_id = $1
+  AND (
+    created_at < $2
+    OR id NOT IN (
+      SELECT h.id FROM thorfinn_password_history h
+      WHERE h.user_id = $1
+      ORDER BY h.created_at DESC
+      LIMIT $3
+    )
+  );
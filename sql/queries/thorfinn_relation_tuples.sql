@@ -0,0 +1,21 @@
+-- name: CreateRelationTuple :one
+INSERT INTO thorfinn_relation_tuples (object_type, object_id, relation, subject_type, subject_id, subject_relation)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (object_type, object_id, relation, subject_type, subject_id, subject_relation)
+DO UPDATE SET created_at = thorfinn_relation_tuples.created_at
+RETURNING *;
+
+-- name: ListRelationTuples :many
+SELECT * FROM thorfinn_relation_tuples
+WHERE object_type = $1 AND object_id = $2 AND relation = $3
+ORDER BY subject_type, subject_id, subject_relation;
+
+-- name: ListObjectRelationTuples :many
+SELECT * FROM thorfinn_relation_tuples
+WHERE object_type = $1 AND object_id = $2
+ORDER BY relation, subject_type, subject_id, subject_relation;
+
+-- name: DeleteRelationTuple :execrows
+DELETE FROM thorfinn_relation_tuples
+WHERE object_type = $1 AND object_id = $2 AND relation = $3
+  AND subject_type = $4 AND subject_id = $5 AND subject_relation = $6;
@@ -0,0 +1,48 @@
+-- name: CreateSession :one
+INSERT INTO thorfinn_sessions (id, user_id, ip_address, user_agent, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: CreateImpersonationSession :one
+INSERT INTO thorfinn_sessions (id, user_id, ip_address, user_agent, expires_at, impersonator_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: FindSessionById :one
+SELECT * FROM thorfinn_sessions WHERE id = $1;
+
+-- name: RevokeSession :exec
+UPDATE thorfinn_sessions
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeOtherUserSessions :exec
+UPDATE thorfinn_sessions
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE thorfinn_sessions
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: ListUserSessions :many
+SELECT * FROM thorfinn_sessions WHERE user_id = $1 ORDER BY created_at DESC;
+
+-- name: CountUserSessions :one
+SELECT COUNT(*) FROM thorfinn_sessions WHERE user_id = $1;
+
+-- name: HasUserSessionFrom :one
+SELECT EXISTS (
+    SELECT 1 FROM thorfinn_sessions
+    WHERE user_id = $1 AND ip_address = $2 AND user_agent = $3
+);
+
+-- name: SetSessionActiveOrganization :one
+UPDATE thorfinn_sessions
+SET active_organization_id = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: RevokeOrganizationSessions :exec
+-- Revokes the sessions of a user acting in an organization, whose tokens carry
+-- a role in it that may no longer be theirs.
+UPDATE thorfinn_sessions
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND active_organization_id = $2 AND revoked_at IS NULL;
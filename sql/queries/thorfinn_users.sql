@@ -6,12 +6,56 @@ SELECT * FROM thorfinn_users WHERE email = $1;
 
 
 -- name: ListUsers :many
-SELECT id, email, verified, two_factor_enabled, created_at, updated_at
+SELECT id, email, verified, two_factor_enabled, roles, status, created_at, updated_at, last_login_at
 FROM thorfinn_users
-ORDER BY created_at DESC;
+WHERE (sqlc.narg('verified')::boolean IS NULL OR verified = sqlc.narg('verified'))
+    AND (sqlc.narg('two_factor_enabled')::boolean IS NULL OR two_factor_enabled = sqlc.narg('two_factor_enabled'))
+    AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+    AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+    AND (sqlc.narg('email_prefix')::text IS NULL OR email LIKE sqlc.narg('email_prefix') || '%')
+    AND (sqlc.narg('email_contains')::text IS NULL OR email ILIKE '%' || sqlc.narg('email_contains') || '%')
+    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+    AND (sqlc.narg('after_id')::text IS NULL OR CASE sqlc.arg('sort_by')::text
+        WHEN 'email' THEN CASE WHEN sqlc.arg('descending')::boolean
+            THEN (email, id) < (sqlc.arg('after_email')::text, sqlc.narg('after_id'))
+            ELSE (email, id) > (sqlc.arg('after_email')::text, sqlc.narg('after_id')) END
+        WHEN 'last_login_at' THEN CASE WHEN sqlc.arg('descending')::boolean
+            THEN (COALESCE(last_login_at, '-infinity'), id) < (sqlc.arg('after_time')::timestamptz, sqlc.narg('after_id'))
+            ELSE (COALESCE(last_login_at, '-infinity'), id) > (sqlc.arg('after_time')::timestamptz, sqlc.narg('after_id')) END
+        ELSE CASE WHEN sqlc.arg('descending')::boolean
+            THEN (created_at, id) < (sqlc.arg('after_time')::timestamptz, sqlc.narg('after_id'))
+            ELSE (created_at, id) > (sqlc.arg('after_time')::timestamptz, sqlc.narg('after_id')) END
+    END)
+ORDER BY
+    CASE WHEN sqlc.arg('sort_by')::text = 'email' AND NOT sqlc.arg('descending')::boolean THEN email END ASC,
+    CASE WHEN sqlc.arg('sort_by')::text = 'email' AND sqlc.arg('descending')::boolean THEN email END DESC,
+    CASE WHEN sqlc.arg('sort_by')::text = 'last_login_at' AND NOT sqlc.arg('descending')::boolean THEN COALESCE(last_login_at, '-infinity') END ASC,
+    CASE WHEN sqlc.arg('sort_by')::text = 'last_login_at' AND sqlc.arg('descending')::boolean THEN COALESCE(last_login_at, '-infinity') END DESC,
+    CASE WHEN sqlc.arg('sort_by')::text = 'created_at' AND NOT sqlc.arg('descending')::boolean THEN created_at END ASC,
+    CASE WHEN sqlc.arg('sort_by')::text = 'created_at' AND sqlc.arg('descending')::boolean THEN created_at END DESC,
+    CASE WHEN NOT sqlc.arg('descending')::boolean THEN id END ASC,
+    CASE WHEN sqlc.arg('descending')::boolean THEN id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListUserIdsWithRole :many
+SELECT id FROM thorfinn_users WHERE sqlc.arg('role')::text = ANY(roles) ORDER BY id;
+
+-- name: CountUsers :one
+SELECT COUNT(*)
+FROM thorfinn_users
+WHERE (sqlc.narg('verified')::boolean IS NULL OR verified = sqlc.narg('verified'))
+    AND (sqlc.narg('two_factor_enabled')::boolean IS NULL OR two_factor_enabled = sqlc.narg('two_factor_enabled'))
+    AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+    AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+    AND (sqlc.narg('email_prefix')::text IS NULL OR email LIKE sqlc.narg('email_prefix') || '%')
+    AND (sqlc.narg('email_contains')::text IS NULL OR email ILIKE '%' || sqlc.narg('email_contains') || '%')
+    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'));
 
 -- name: CreateUser :one
-INSERT INTO thorfinn_users (id, email, password_hash) VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO thorfinn_users (id, email, password_hash, status) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: CreateInvitedUser :one
+INSERT INTO thorfinn_users (id, email, password_hash, verified, roles) VALUES ($1, $2, $3, TRUE, $4) RETURNING *;
 
 -- name: UpdateUserVerified :one
 UPDATE thorfinn_users
@@ -27,9 +71,70 @@ RETURNING *;
 
 -- name: UpdateUser :one
 UPDATE thorfinn_users
-SET email = $2, password_hash = $3, verified = $4, two_factor_enabled = $5
+SET email = $2, password_hash = $3, verified = $4, two_factor_enabled = $5, roles = $6,
+    display_name = $7, given_name = $8, family_name = $9, avatar_url = $10, locale = $11, timezone = $12,
+    user_metadata = $13, app_metadata = $14
+WHERE id = $1 AND version = $15
+RETURNING *;
+
+-- name: UpdateUserEmail :one
+UPDATE thorfinn_users
+SET email = $2
 WHERE id = $1
 RETURNING *;
 
--- name: DeleteUser :exec
-DELETE FROM thorfinn_users WHERE id = $1;
+-- name: UpdateUserNotificationOptOuts :one
+UPDATE thorfinn_users
+SET notification_opt_outs = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserLastLogin :exec
+UPDATE thorfinn_users
+SET last_login_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: UpdateUserProfile :one
+UPDATE thorfinn_users
+SET two_factor_enabled = $2, notification_opt_outs = $3,
+    display_name = $4, given_name = $5, family_name = $6, avatar_url = $7, locale = $8, timezone = $9,
+    user_metadata = $10
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserStatus :one
+UPDATE thorfinn_users
+SET status = $2, status_reason = $3, purge_after = $4, deletion_requested_by_user = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ListUsersDueForPurge :many
+SELECT id FROM thorfinn_users
+WHERE status = 'pending_deletion' AND purge_after <= CURRENT_TIMESTAMP
+ORDER BY purge_after
+LIMIT $1;
+
+-- name: PurgeUser :one
+WITH deleted_sessions AS (
+    DELETE FROM thorfinn_sessions WHERE user_id = $1
+), deleted_password_history AS (
+    DELETE FROM thorfinn_password_history WHERE user_id = $1
+), deleted_otp_codes AS (
+    DELETE FROM thorfinn_otp_codes WHERE user_id = $1
+), deleted_memberships AS (
+    DELETE FROM thorfinn_memberships WHERE user_id = $1
+), cleared_invitations AS (
+    UPDATE thorfinn_invitations SET invited_by = NULL WHERE invited_by = $1
+), deleted_api_keys AS (
+    DELETE FROM thorfinn_api_keys WHERE user_id = $1
+), deleted_relation_tuples AS (
+    DELETE FROM thorfinn_relation_tuples WHERE subject_type = 'user' AND subject_id = $1
+)
+UPDATE thorfinn_users
+SET email = id || '@deleted.invalid', password_hash = '', verified = FALSE, two_factor_enabled = FALSE,
+    notification_opt_outs = '{}', roles = '{}', last_login_at = NULL,
+    display_name = NULL, given_name = NULL, family_name = NULL, avatar_url = NULL, locale = NULL, timezone = NULL,
+    user_metadata = '{}', app_metadata = '{}',
+    status = 'deleted', status_reason = NULL, purge_after = NULL, deleted_at = CURRENT_TIMESTAMP, deletion_requested_by_user = FALSE
+WHERE id = $1
+RETURNING *;
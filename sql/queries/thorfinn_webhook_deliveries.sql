@@ -0,0 +1,37 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO thorfinn_webhook_deliveries (id, endpoint_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: FindWebhookDeliveryById :one
+SELECT * FROM thorfinn_webhook_deliveries WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM thorfinn_webhook_deliveries
+WHERE endpoint_id = sqlc.arg('endpoint_id') AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg('limit');
+
+-- name: ClaimDueWebhookDeliveries :many
+-- Due deliveries are claimed by pushing their next attempt back by the lease,
+-- so that other instances don't send them at the same time.
+UPDATE thorfinn_webhook_deliveries
+SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg('lease_seconds')::int)
+WHERE id IN (
+    SELECT d.id FROM thorfinn_webhook_deliveries d
+    JOIN thorfinn_webhook_endpoints e ON e.id = d.endpoint_id
+    WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP AND e.enabled
+    ORDER BY d.next_attempt_at
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE thorfinn_webhook_deliveries
+SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_attempt_at = CURRENT_TIMESTAMP,
+    response_status = $4, response_body = $5, error = $6
+WHERE id = $1;
+
+-- name: DeleteWebhookDeliveriesBefore :exec
+DELETE FROM thorfinn_webhook_deliveries WHERE status <> 'pending' AND created_at < $1;
@@ -0,0 +1,44 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO thorfinn_webhook_endpoints (id, url, secret, events, description, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: FindWebhookEndpointById :one
+SELECT * FROM thorfinn_webhook_endpoints WHERE id = $1;
+
+-- name: ListWebhookEndpoints :many
+SELECT * FROM thorfinn_webhook_endpoints
+ORDER BY created_at DESC, id;
+
+-- name: ListSubscribedWebhookEndpoints :many
+SELECT * FROM thorfinn_webhook_endpoints
+WHERE enabled AND (cardinality(events) = 0 OR sqlc.arg('event')::text = ANY(events));
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE thorfinn_webhook_endpoints
+SET url = $2, events = $3, description = $4, enabled = $5,
+    disabled_reason = CASE WHEN $5 THEN NULL ELSE disabled_reason END,
+    consecutive_failures = CASE WHEN $5 AND NOT enabled THEN 0 ELSE consecutive_failures END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordWebhookEndpointSuccess :exec
+UPDATE thorfinn_webhook_endpoints
+SET consecutive_failures = 0
+WHERE id = $1 AND consecutive_failures > 0;
+
+-- name: RecordWebhookEndpointFailure :one
+UPDATE thorfinn_webhook_endpoints
+SET consecutive_failures = consecutive_failures + 1,
+    enabled = enabled AND consecutive_failures + 1 < sqlc.arg('max_failures')::int,
+    disabled_reason = CASE
+        WHEN enabled AND consecutive_failures + 1 >= sqlc.arg('max_failures')::int THEN sqlc.arg('reason')::text
+        ELSE disabled_reason
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM thorfinn_webhook_endpoints WHERE id = $1;